@@ -6,13 +6,18 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 )
 
 var (
 	ConnectionsFile = getConfigPath("connections.json")
 	UsersFile       = getConfigPath("users.json")
 	AppConfigFile   = getConfigPath("app.json")
+	ProfilesFile    = getConfigPath("profiles.json")
+	ApprovalsFile   = getConfigPath("approvals.json")
+	ChartsFile      = getConfigPath("charts.json")
 )
 
 func getConfigPath(filename string) string {
@@ -24,16 +29,213 @@ func getConfigPath(filename string) string {
 	return filepath.Join("config", filename)
 }
 
+// DefaultMaxBodyBytes - ограничение размера тела JSON-запроса по умолчанию
+const DefaultMaxBodyBytes int64 = 1 << 20
+
 type AppConfig struct {
-	Host string `json:"host"`
-	Port string `json:"port"`
+	Host         string `json:"host"`
+	Port         string `json:"port"`
+	MaxBodyBytes int64  `json:"maxBodyBytes,omitempty"`
+	IdleTimeout  string `json:"idleTimeout,omitempty"`
+	// SkipDeleteConfirmation отключает проверку поля confirm при удалении
+	// баз и таблиц - для автоматизации
+	SkipDeleteConfirmation bool `json:"skipDeleteConfirmation,omitempty"`
+	// MaxConcurrentQueries - предел одновременных запросов на подключение,
+	// если у подключения не задан свой; 0 - без предела
+	MaxConcurrentQueries int `json:"maxConcurrentQueries,omitempty"`
+	// QueryQueueTimeout - сколько запрос ждет свободного слота, прежде чем
+	// получить 429; по умолчанию 10s, "0s" - отказ сразу
+	QueryQueueTimeout string `json:"queryQueueTimeout,omitempty"`
+	// MaxRows - предел строк результата /api/query и терминала, если у
+	// подключения не задан свой; 0 - без предела. Лишние строки отбрасываются,
+	// ответ помечается truncated
+	MaxRows int `json:"maxRows,omitempty"`
+	// SlowQueryThreshold - запросы дольше этого времени пишутся в лог и в
+	// журнал медленных запросов; пусто - журнал выключен
+	SlowQueryThreshold string `json:"slowQueryThreshold,omitempty"`
+	// HTTPRetryAttempts - попыток ping и чтения списков у HTTP-драйверов
+	// (Elasticsearch, InfluxDB, Neo4j и др.); 0 - по умолчанию 3, 1 - без повторов
+	HTTPRetryAttempts int `json:"httpRetryAttempts,omitempty"`
+	// HTTPTimeout - таймаут запроса HTTP-драйверов, если у подключения не
+	// задан свой httpTimeout; по умолчанию 30s
+	HTTPTimeout string `json:"httpTimeout,omitempty"`
+	// ConnectionEnvPrefix - в поля подключений через ${NAME} подставляются
+	// только переменные окружения с этим префиксом (например, "DBM_"), чтобы
+	// через подключение нельзя было прочитать остальные. Пусто - подстановка
+	// выключена
+	ConnectionEnvPrefix string `json:"connectionEnvPrefix,omitempty"`
+	// SQLiteDataDir - каталог файлов баз SQLite: пути подключений
+	// отсчитываются от него и не могут вести наружу; по умолчанию data/sqlite
+	SQLiteDataDir string `json:"sqliteDataDir,omitempty"`
+	// AllowedOrigins - Origin, которым разрешены запросы из браузера; "*" -
+	// любой Origin без credentials. Пусто - режим разработки: разрешен любой
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+	// CSRFProtection требует заголовок X-CSRF-Token (GET /api/auth/csrf)
+	// у изменяющих запросов с токеном сессии
+	CSRFProtection bool `json:"csrfProtection,omitempty"`
+	// TrustProxyHeaders включает разбор X-Forwarded-* от обратного прокси;
+	// TrustedProxies - адреса или сети прокси, пусто - любой отправитель
+	TrustProxyHeaders bool     `json:"trustProxyHeaders,omitempty"`
+	TrustedProxies    []string `json:"trustedProxies,omitempty"`
+	// AuthDisabled отключает проверку токена: запросы выполняются от имени
+	// root. Только для локальной разработки, в production-режиме запрещено
+	AuthDisabled bool `json:"authDisabled,omitempty"`
+	// LDAP включает вход через LDAP/Active Directory вместо users.json
+	LDAP *LDAPConfig `json:"ldap,omitempty"`
+	// OIDC добавляет вход через внешнего провайдера (Keycloak, Google и т.п.);
+	// локальный вход при этом остается доступным
+	OIDC *OIDCConfig `json:"oidc,omitempty"`
+	// Production запрещает запуск со встроенным ключом JWT (также APP_ENV=production)
+	Production bool `json:"production,omitempty"`
+	// JWTSecret подписывает токены (переменная JWT_SECRET имеет приоритет).
+	// JWTPreviousSecrets - прежние ключи: выданные с ними токены принимаются
+	// в течение JWTRotationGrace (по умолчанию срок действия токена, 24h)
+	JWTSecret          string   `json:"jwtSecret,omitempty"`
+	JWTPreviousSecrets []string `json:"jwtPreviousSecrets,omitempty"`
+	JWTRotationGrace   string   `json:"jwtRotationGrace,omitempty"`
+}
+
+// LDAPConfig - сервер LDAP для входа. Пользователь ищется по UserFilter
+// от имени BindDN, затем пароль проверяется bind-ом найденной записи
+type LDAPConfig struct {
+	Enabled            bool   `json:"enabled"`
+	URL                string `json:"url"`                // ldap://host:389 или ldaps://host:636
+	StartTLS           bool   `json:"startTls,omitempty"` // перейти на TLS после подключения по ldap://
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+	BindDN             string `json:"bindDn,omitempty"` // учетная запись для поиска; пусто - анонимный поиск
+	BindPassword       string `json:"bindPassword,omitempty"`
+	BaseDN             string `json:"baseDn"`
+	UserFilter         string `json:"userFilter,omitempty"`  // %s - имя пользователя; по умолчанию (uid=%s), для AD - (sAMAccountName=%s)
+	DefaultRole        string `json:"defaultRole,omitempty"` // роль пользователя, созданного при первом входе
+}
+
+// OIDCConfig - провайдер OpenID Connect. Вход идет по authorization code
+// с PKCE; RedirectURL должен указывать на /api/auth/oidc/callback
+type OIDCConfig struct {
+	Enabled       bool     `json:"enabled"`
+	Issuer        string   `json:"issuer"`
+	ClientID      string   `json:"clientId"`
+	ClientSecret  string   `json:"clientSecret,omitempty"`
+	RedirectURL   string   `json:"redirectUrl"`
+	Scopes        []string `json:"scopes,omitempty"`        // помимо openid; по умолчанию profile и email
+	UsernameClaim string   `json:"usernameClaim,omitempty"` // по умолчанию preferred_username, затем email и sub
+	DefaultRole   string   `json:"defaultRole,omitempty"`   // роль пользователя, созданного при первом входе
+	PostLoginURL  string   `json:"postLoginUrl,omitempty"`  // куда вернуть браузер с токеном, по умолчанию /
+}
+
+func (c *AppConfig) BodyLimit() int64 {
+	if c.MaxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+	return c.MaxBodyBytes
+}
+
+// IdleDisconnectAfter возвращает время простоя, после которого подключение
+// закрывается автоматически; 0 - автоотключение выключено
+func (c *AppConfig) IdleDisconnectAfter() (time.Duration, error) {
+	if c.IdleTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.IdleTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("неверный idleTimeout %q: %w", c.IdleTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("idleTimeout не может быть отрицательным")
+	}
+	return timeout, nil
+}
+
+// DefaultQueryQueueTimeout - ожидание слота запроса, если queryQueueTimeout не задан
+const DefaultQueryQueueTimeout = 10 * time.Second
+
+// QueryQueueWait возвращает, сколько запрос ждет свободного слота подключения
+func (c *AppConfig) QueryQueueWait() (time.Duration, error) {
+	if c.QueryQueueTimeout == "" {
+		return DefaultQueryQueueTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.QueryQueueTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("неверный queryQueueTimeout %q: %w", c.QueryQueueTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("queryQueueTimeout не может быть отрицательным")
+	}
+	return timeout, nil
+}
+
+// HTTPRequestTimeout возвращает общий таймаут HTTP-драйверов; 0 - по умолчанию
+func (c *AppConfig) HTTPRequestTimeout() (time.Duration, error) {
+	if c.HTTPTimeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(c.HTTPTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("неверный httpTimeout %q: %w", c.HTTPTimeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("httpTimeout не может быть отрицательным")
+	}
+	return timeout, nil
+}
+
+// SlowQueryDuration возвращает порог медленного запроса; 0 - журнал выключен
+func (c *AppConfig) SlowQueryDuration() (time.Duration, error) {
+	if c.SlowQueryThreshold == "" {
+		return 0, nil
+	}
+	threshold, err := time.ParseDuration(c.SlowQueryThreshold)
+	if err != nil {
+		return 0, fmt.Errorf("неверный slowQueryThreshold %q: %w", c.SlowQueryThreshold, err)
+	}
+	if threshold < 0 {
+		return 0, fmt.Errorf("slowQueryThreshold не может быть отрицательным")
+	}
+	return threshold, nil
+}
+
+// IsProduction - production-режим из app.json или APP_ENV=production
+func (c *AppConfig) IsProduction() bool {
+	return c.Production || os.Getenv("APP_ENV") == "production"
+}
+
+// JWTKeys возвращает ключ подписи, предыдущие ключи и период их приема.
+// JWT_SECRET и JWT_PREVIOUS_SECRETS (через запятую) имеют приоритет над app.json
+func (c *AppConfig) JWTKeys() (string, []string, time.Duration, error) {
+	secret, previous := c.JWTSecret, c.JWTPreviousSecrets
+	if env := os.Getenv("JWT_SECRET"); env != "" {
+		secret = env
+		previous = nil
+		if envPrevious := os.Getenv("JWT_PREVIOUS_SECRETS"); envPrevious != "" {
+			for _, key := range strings.Split(envPrevious, ",") {
+				previous = append(previous, strings.TrimSpace(key))
+			}
+		}
+	}
+
+	var grace time.Duration
+	if c.JWTRotationGrace != "" {
+		var err error
+		grace, err = time.ParseDuration(c.JWTRotationGrace)
+		if err != nil || grace < 0 {
+			return "", nil, 0, fmt.Errorf("неверный jwtRotationGrace %q", c.JWTRotationGrace)
+		}
+	}
+	return secret, previous, grace, nil
 }
 
 var (
 	mu          sync.RWMutex
 	connections []models.Connection
 	users       []models.User
+	profiles    []models.Profile
+	approvals   []models.PendingQuery
+	charts      []models.Chart
 	appConfig   *AppConfig
+
+	// connectionStatsDirty - счетчики запросов изменились после последней
+	// записи connections.json
+	connectionStatsDirty bool
 )
 
 func LoadConnections() ([]models.Connection, error) {
@@ -77,6 +279,7 @@ func SaveConnections(conns []models.Connection) error {
 	}
 
 	connections = conns
+	connectionStatsDirty = false
 	return nil
 }
 
@@ -104,6 +307,14 @@ func AddConnection(conn models.Connection) error {
 	return SaveConnections(conns)
 }
 
+func AddConnections(newConns []models.Connection) error {
+	existing := GetConnections()
+	conns := make([]models.Connection, 0, len(existing)+len(newConns))
+	conns = append(conns, existing...)
+	conns = append(conns, newConns...)
+	return SaveConnections(conns)
+}
+
 func UpdateConnection(id string, conn models.Connection) error {
 	mu.Lock()
 	defer mu.Unlock()
@@ -133,6 +344,50 @@ func UpdateConnection(id string, conn models.Connection) error {
 	return fmt.Errorf("подключение с ID %s не найдено", id)
 }
 
+// RecordConnectionQuery увеличивает счетчик запросов подключения
+// и запоминает время последнего запроса. Файл при этом не пишется:
+// счетчики сохраняет FlushConnectionStats
+func RecordConnectionQuery(id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for i := range connections {
+		if connections[i].ID == id {
+			now := time.Now()
+			connections[i].LastQueryAt = &now
+			connections[i].QueryCount++
+			connectionStatsDirty = true
+			return nil
+		}
+	}
+	return fmt.Errorf("подключение с ID %s не найдено", id)
+}
+
+// FlushConnectionStats сохраняет счетчики запросов, если они изменились
+func FlushConnectionStats() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !connectionStatsDirty {
+		return nil
+	}
+	return writeConnectionsLocked()
+}
+
+// writeConnectionsLocked сохраняет подключения в файл; mu должен быть захвачен
+func writeConnectionsLocked() error {
+	data, err := json.MarshalIndent(connections, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации подключений: %w", err)
+	}
+
+	if err := os.WriteFile(ConnectionsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла подключений: %w", err)
+	}
+	connectionStatsDirty = false
+	return nil
+}
+
 func DeleteConnection(id string) error {
 	conns := GetConnections()
 	for i := range conns {
@@ -198,18 +453,397 @@ func GetUserByUsername(username string) (*models.User, error) {
 
 	for i := range users {
 		if users[i].Username == username {
-			return &users[i], nil
+			user := users[i]
+			return &user, nil
 		}
 	}
 	return nil, fmt.Errorf("пользователь %s не найден", username)
 }
 
+// GetUserByOIDCSubject ищет пользователя, связанного с учетной записью
+// OIDC-провайдера issuer с идентификатором subject (claim sub)
+func GetUserByOIDCSubject(issuer, subject string) (*models.User, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for i := range users {
+		if users[i].OIDCIssuer == issuer && users[i].OIDCSubject == subject {
+			user := users[i]
+			return &user, nil
+		}
+	}
+	return nil, fmt.Errorf("пользователь OIDC %s не найден", subject)
+}
+
+func GetUserByID(id string) (*models.User, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for i := range users {
+		if users[i].ID == id {
+			user := users[i]
+			return &user, nil
+		}
+	}
+	return nil, fmt.Errorf("пользователь с ID %s не найден", id)
+}
+
 func AddUser(user models.User) error {
 	usrs := GetUsers()
 	usrs = append(usrs, user)
 	return SaveUsers(usrs)
 }
 
+func UpdateUser(user models.User) error {
+	usrs := GetUsers()
+	updated := make([]models.User, len(usrs))
+	copy(updated, usrs)
+	for i := range updated {
+		if updated[i].ID == user.ID {
+			updated[i] = user
+			return SaveUsers(updated)
+		}
+	}
+	return fmt.Errorf("пользователь %s не найден", user.Username)
+}
+
+func LoadProfiles() ([]models.Profile, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := os.ReadFile(ProfilesFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			profiles = []models.Profile{}
+			return []models.Profile{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла профилей: %w", err)
+	}
+
+	if len(data) == 0 {
+		profiles = []models.Profile{}
+		return []models.Profile{}, nil
+	}
+
+	var profs []models.Profile
+	if err := json.Unmarshal(data, &profs); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга профилей: %w", err)
+	}
+
+	profiles = profs
+	return profs, nil
+}
+
+func SaveProfiles(profs []models.Profile) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := json.MarshalIndent(profs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации профилей: %w", err)
+	}
+
+	if err := os.WriteFile(ProfilesFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла профилей: %w", err)
+	}
+
+	profiles = profs
+	return nil
+}
+
+func GetProfiles() []models.Profile {
+	mu.RLock()
+	defer mu.RUnlock()
+	return profiles
+}
+
+func GetProfileByID(id string) (*models.Profile, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for i := range profiles {
+		if profiles[i].ID == id {
+			profile := profiles[i]
+			return &profile, nil
+		}
+	}
+	return nil, fmt.Errorf("профиль с ID %s не найден", id)
+}
+
+func AddProfile(profile models.Profile) error {
+	profs := GetProfiles()
+	updated := make([]models.Profile, 0, len(profs)+1)
+	updated = append(updated, profs...)
+	updated = append(updated, profile)
+	return SaveProfiles(updated)
+}
+
+func UpdateProfile(id string, profile models.Profile) error {
+	profs := GetProfiles()
+	updated := make([]models.Profile, len(profs))
+	copy(updated, profs)
+	for i := range updated {
+		if updated[i].ID == id {
+			profile.ID = id
+			updated[i] = profile
+			return SaveProfiles(updated)
+		}
+	}
+	return fmt.Errorf("профиль с ID %s не найден", id)
+}
+
+func DeleteProfile(id string) error {
+	profs := GetProfiles()
+	updated := make([]models.Profile, 0, len(profs))
+	found := false
+	for _, profile := range profs {
+		if profile.ID == id {
+			found = true
+			continue
+		}
+		updated = append(updated, profile)
+	}
+	if !found {
+		return fmt.Errorf("профиль с ID %s не найден", id)
+	}
+	return SaveProfiles(updated)
+}
+
+func LoadApprovals() ([]models.PendingQuery, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := os.ReadFile(ApprovalsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			approvals = []models.PendingQuery{}
+			return []models.PendingQuery{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла запросов на подтверждение: %w", err)
+	}
+
+	if len(data) == 0 {
+		approvals = []models.PendingQuery{}
+		return []models.PendingQuery{}, nil
+	}
+
+	var pending []models.PendingQuery
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга запросов на подтверждение: %w", err)
+	}
+
+	approvals = pending
+	return pending, nil
+}
+
+// writeApprovalsLocked сохраняет запросы на подтверждение; вызывается под mu
+func writeApprovalsLocked(pending []models.PendingQuery) error {
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации запросов на подтверждение: %w", err)
+	}
+
+	if err := os.WriteFile(ApprovalsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла запросов на подтверждение: %w", err)
+	}
+
+	approvals = pending
+	return nil
+}
+
+func GetApprovals() []models.PendingQuery {
+	mu.RLock()
+	defer mu.RUnlock()
+	return approvals
+}
+
+func AddApproval(query models.PendingQuery) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updated := make([]models.PendingQuery, 0, len(approvals)+1)
+	updated = append(updated, approvals...)
+	updated = append(updated, query)
+	return writeApprovalsLocked(updated)
+}
+
+// UpdateApproval изменяет запрос функцией update и сохраняет результат.
+// Проверка и изменение выполняются под одной блокировкой, поэтому два
+// администратора не могут одобрить один запрос дважды: update второго
+// увидит уже измененный статус
+func UpdateApproval(id string, update func(*models.PendingQuery) error) (*models.PendingQuery, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updated := make([]models.PendingQuery, len(approvals))
+	copy(updated, approvals)
+	for i := range updated {
+		if updated[i].ID != id {
+			continue
+		}
+		if err := update(&updated[i]); err != nil {
+			return nil, err
+		}
+		if err := writeApprovalsLocked(updated); err != nil {
+			return nil, err
+		}
+		query := updated[i]
+		return &query, nil
+	}
+	return nil, fmt.Errorf("запрос на подтверждение с ID %s не найден", id)
+}
+
+func LoadCharts() ([]models.Chart, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	data, err := os.ReadFile(ChartsFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			charts = []models.Chart{}
+			return []models.Chart{}, nil
+		}
+		return nil, fmt.Errorf("ошибка чтения файла графиков: %w", err)
+	}
+
+	if len(data) == 0 {
+		charts = []models.Chart{}
+		return []models.Chart{}, nil
+	}
+
+	var saved []models.Chart
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга графиков: %w", err)
+	}
+
+	charts = saved
+	return saved, nil
+}
+
+// writeChartsLocked сохраняет графики; вызывается под mu
+func writeChartsLocked(saved []models.Chart) error {
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации графиков: %w", err)
+	}
+
+	if err := os.WriteFile(ChartsFile, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи файла графиков: %w", err)
+	}
+
+	charts = saved
+	return nil
+}
+
+// GetChartsByUser возвращает графики пользователя
+func GetChartsByUser(userID string) []models.Chart {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := []models.Chart{}
+	for _, chart := range charts {
+		if chart.UserID == userID {
+			result = append(result, chart)
+		}
+	}
+	return result
+}
+
+// GetChartByID возвращает график пользователя; чужой график не находится
+func GetChartByID(userID, id string) (*models.Chart, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for i := range charts {
+		if charts[i].ID == id && charts[i].UserID == userID {
+			chart := charts[i]
+			return &chart, nil
+		}
+	}
+	return nil, fmt.Errorf("график с ID %s не найден", id)
+}
+
+func AddChart(chart models.Chart) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updated := make([]models.Chart, 0, len(charts)+1)
+	updated = append(updated, charts...)
+	updated = append(updated, chart)
+	return writeChartsLocked(updated)
+}
+
+func UpdateChart(chart models.Chart) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updated := make([]models.Chart, len(charts))
+	copy(updated, charts)
+	for i := range updated {
+		if updated[i].ID == chart.ID && updated[i].UserID == chart.UserID {
+			updated[i] = chart
+			return writeChartsLocked(updated)
+		}
+	}
+	return fmt.Errorf("график с ID %s не найден", chart.ID)
+}
+
+func DeleteChart(userID, id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	updated := make([]models.Chart, 0, len(charts))
+	found := false
+	for _, chart := range charts {
+		if chart.ID == id && chart.UserID == userID {
+			found = true
+			continue
+		}
+		updated = append(updated, chart)
+	}
+	if !found {
+		return fmt.Errorf("график с ID %s не найден", id)
+	}
+	return writeChartsLocked(updated)
+}
+
+// CheckFiles проверяет, что файлы конфигурации читаются и разбираются,
+// не изменяя загруженное состояние. Отсутствующий или пустой файл не ошибка.
+func CheckFiles() error {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	files := []struct {
+		path   string
+		target interface{}
+	}{
+		{ConnectionsFile, &[]models.Connection{}},
+		{UsersFile, &[]models.User{}},
+		{ProfilesFile, &[]models.Profile{}},
+		{ApprovalsFile, &[]models.PendingQuery{}},
+		{ChartsFile, &[]models.Chart{}},
+		{AppConfigFile, &AppConfig{}},
+	}
+
+	for _, f := range files {
+		data, err := os.ReadFile(f.path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("ошибка чтения файла %s: %w", f.path, err)
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(data, f.target); err != nil {
+			return fmt.Errorf("ошибка парсинга файла %s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
 func LoadAppConfig() (*AppConfig, error) {
 	mu.Lock()
 	defer mu.Unlock()
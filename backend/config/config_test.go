@@ -0,0 +1,71 @@
+package config
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApprovalParamsRoundTrip(t *testing.T) {
+	ApprovalsFile = filepath.Join(t.TempDir(), "approvals.json")
+	approvals = []models.PendingQuery{}
+
+	tests := []models.QueryParams{
+		{Named: map[string]interface{}{"id": json.Number("42"), "name": "alice"}},
+		{Positional: []interface{}{json.Number("1.5"), "bob", nil}},
+		{},
+	}
+
+	for i, params := range tests {
+		if err := AddApproval(models.PendingQuery{ID: string(rune('a' + i)), Params: params}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	loaded, err := LoadApprovals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(tests) {
+		t.Fatalf("LoadApprovals() returned %d approvals, want %d", len(loaded), len(tests))
+	}
+	for i, params := range tests {
+		if !reflect.DeepEqual(loaded[i].Params, params) {
+			t.Errorf("approval %d params = %+v, want %+v", i, loaded[i].Params, params)
+		}
+	}
+}
+
+func TestRecordConnectionQueryFlush(t *testing.T) {
+	ConnectionsFile = filepath.Join(t.TempDir(), "connections.json")
+	if err := SaveConnections([]models.Connection{{ID: "1", Name: "orders"}}); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(ConnectionsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := RecordConnectionQuery("1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := os.ReadFile(ConnectionsFile); string(data) != string(saved) {
+		t.Fatal("RecordConnectionQuery не должен переписывать файл подключений")
+	}
+
+	if err := FlushConnectionStats(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadConnections()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded[0].QueryCount != 3 || loaded[0].LastQueryAt == nil {
+		t.Errorf("после сохранения queryCount = %d, lastQueryAt = %v", loaded[0].QueryCount, loaded[0].LastQueryAt)
+	}
+}
@@ -19,6 +19,11 @@ func NewAerospikeDriver() *AerospikeDriver {
 	return &AerospikeDriver{}
 }
 
+// Capabilities: произвольные запросы и DDL в Aerospike не поддерживаются
+func (d *AerospikeDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{}
+}
+
 func (d *AerospikeDriver) Connect(ctx context.Context, conn models.Connection) error {
 	host := aerospike.NewHost(conn.Host, 3000)
 	if conn.Port != "" {
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// bsonTimeLayout - RFC3339 с миллисекундами: точность BSON DateTime
+const bsonTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// normalizeBSON приводит значения BSON к виду, понятному в JSON: ObjectID -
+// hex-строка, DateTime - RFC3339 в UTC, Decimal128 - строка без потери
+// точности, Binary - base64. Вложенные документы и массивы обходятся
+// рекурсивно; bson.D становится объектом
+func normalizeBSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format(bsonTimeLayout)
+	case primitive.Decimal128:
+		return v.String()
+	case primitive.Binary:
+		return base64.StdEncoding.EncodeToString(v.Data)
+	case primitive.Timestamp:
+		return map[string]interface{}{"t": v.T, "i": v.I}
+	case primitive.Regex:
+		return fmt.Sprintf("/%s/%s", v.Pattern, v.Options)
+	case primitive.JavaScript:
+		return string(v)
+	case primitive.Symbol:
+		return string(v)
+	case primitive.Null, primitive.Undefined:
+		return nil
+	case primitive.MinKey:
+		return "$minKey"
+	case primitive.MaxKey:
+		return "$maxKey"
+	case primitive.M:
+		return normalizeBSONMap(v)
+	case map[string]interface{}:
+		return normalizeBSONMap(v)
+	case primitive.D:
+		doc := make(map[string]interface{}, len(v))
+		for _, elem := range v {
+			doc[elem.Key] = normalizeBSON(elem.Value)
+		}
+		return doc
+	case primitive.A:
+		return normalizeBSONSlice(v)
+	case []interface{}:
+		return normalizeBSONSlice(v)
+	default:
+		return value
+	}
+}
+
+func normalizeBSONMap(m map[string]interface{}) map[string]interface{} {
+	doc := make(map[string]interface{}, len(m))
+	for key, value := range m {
+		doc[key] = normalizeBSON(value)
+	}
+	return doc
+}
+
+func normalizeBSONSlice(values []interface{}) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, value := range values {
+		out[i] = normalizeBSON(value)
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestNormalizeBSONObjectID(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	if got := normalizeBSON(id); got != "65a1b2c3d4e5f60718293a4b" {
+		t.Errorf("ObjectID = %v", got)
+	}
+}
+
+func TestNormalizeBSONDateTime(t *testing.T) {
+	date := primitive.NewDateTimeFromTime(time.Date(2024, 1, 12, 15, 4, 5, 250e6, time.FixedZone("MSK", 3*3600)))
+	if got := normalizeBSON(date); got != "2024-01-12T12:04:05.250Z" {
+		t.Errorf("DateTime = %v", got)
+	}
+}
+
+func TestNormalizeBSONDecimal128(t *testing.T) {
+	dec, _ := primitive.ParseDecimal128("12345678901234567890.123456789")
+	if got := normalizeBSON(dec); got != "12345678901234567890.123456789" {
+		t.Errorf("Decimal128 = %v", got)
+	}
+}
+
+func TestNormalizeBSONBinary(t *testing.T) {
+	bin := primitive.Binary{Subtype: 0, Data: []byte("hello")}
+	if got := normalizeBSON(bin); got != "aGVsbG8=" {
+		t.Errorf("Binary = %v", got)
+	}
+}
+
+func TestNormalizeBSONNested(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("65a1b2c3d4e5f60718293a4b")
+	doc := bson.M{
+		"owner": bson.M{"_id": id},
+		"tags":  bson.A{id, "x"},
+		"meta":  bson.D{{Key: "deleted", Value: primitive.Null{}}},
+		"count": int32(3),
+	}
+
+	want := map[string]interface{}{
+		"owner": map[string]interface{}{"_id": "65a1b2c3d4e5f60718293a4b"},
+		"tags":  []interface{}{"65a1b2c3d4e5f60718293a4b", "x"},
+		"meta":  map[string]interface{}{"deleted": nil},
+		"count": int32(3),
+	}
+	if got := normalizeBSON(doc); !reflect.DeepEqual(got, want) {
+		t.Errorf("nested = %#v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package database
+
+import "database-manager/models"
+
+// DescribeCapabilities возвращает возможности драйвера: базовые операции
+// драйвер описывает сам (Capabilities), дополнительные определяются по
+// интерфейсам, которые он реализует
+func DescribeCapabilities(driver DatabaseDriver) models.DriverCapabilities {
+	caps := driver.Capabilities()
+	caps.SupportsQuery = caps.QueryLanguage != ""
+
+	_, caps.SupportsDataBrowse = driver.(DataBrowser)
+	_, caps.SupportsDataWrite = driver.(DataWriter)
+	_, caps.SupportsParams = driver.(ParameterizedExecutor)
+	_, caps.SupportsTransactions = driver.(TransactionExecutor)
+	_, caps.SupportsSessions = driver.(SessionOpener)
+	_, caps.SupportsSessionVars = driver.(SessionVarsExecutor)
+	_, caps.SupportsFilterBuilder = driver.(FilterCompiler)
+	_, caps.SupportsRoleListing = driver.(RoleLister)
+
+	return caps
+}
+
+// ConnectionCapabilities описывает подключение без установки соединения.
+// Supabase через PostgREST определяется по настройкам: незапущенный
+// драйвер о режиме еще не знает
+func ConnectionCapabilities(conn models.Connection) (models.DriverCapabilities, bool) {
+	driver := NewDriverFactory().CreateDriver(conn.Type)
+	if driver == nil {
+		return models.DriverCapabilities{}, false
+	}
+
+	var caps models.DriverCapabilities
+	if conn.Type == models.Supabase && conn.RestURL != "" {
+		caps = postgrestCapabilities()
+		caps.SupportsQuery = true
+	} else {
+		caps = DescribeCapabilities(driver)
+	}
+	caps.Type = conn.Type
+	return caps, true
+}
+
+// DatabaseTypes описывает все поддерживаемые типы БД для формы подключения:
+// порт, обязательные поля и SSL берутся из models.DatabaseTypeDescriptor,
+// язык запросов, пользователи и таблицы - из возможностей драйвера
+func DatabaseTypes() []models.DatabaseTypeInfo {
+	types := make([]models.DatabaseTypeInfo, 0, len(models.SupportedDatabaseTypes))
+	for _, t := range models.SupportedDatabaseTypes {
+		caps, ok := ConnectionCapabilities(models.Connection{Type: t})
+		if !ok {
+			continue
+		}
+		descriptor := t.Descriptor()
+		types = append(types, models.DatabaseTypeInfo{
+			Type:           t,
+			DefaultPort:    descriptor.DefaultPort,
+			RequiredFields: t.RequiredFields(),
+			QueryLanguage:  caps.QueryLanguage,
+			FileBased:      t.IsFileBased(),
+			UsesDSN:        t.UsesDSN(),
+			SupportsSSL:    descriptor.SupportsSSL,
+			SupportsUsers:  caps.SupportsUsers,
+			SupportsTables: caps.SupportsCreateTable || caps.SupportsDeleteTable,
+		})
+	}
+	return types
+}
@@ -0,0 +1,33 @@
+package database
+
+import (
+	"database-manager/models"
+	"testing"
+)
+
+func TestDatabaseTypes(t *testing.T) {
+	types := DatabaseTypes()
+	if len(types) != len(models.SupportedDatabaseTypes) {
+		t.Fatalf("DatabaseTypes() returned %d types, want %d", len(types), len(models.SupportedDatabaseTypes))
+	}
+
+	for _, info := range types {
+		if info.FileBased != (info.DefaultPort == "") {
+			t.Errorf("%s: defaultPort %q, fileBased %v", info.Type, info.DefaultPort, info.FileBased)
+		}
+		if len(info.RequiredFields) == 0 {
+			t.Errorf("%s: no required fields", info.Type)
+		}
+	}
+
+	byType := make(map[models.DatabaseType]models.DatabaseTypeInfo, len(types))
+	for _, info := range types {
+		byType[info.Type] = info
+	}
+	if pg := byType[models.PostgreSQL]; pg.QueryLanguage != "sql" || !pg.SupportsUsers || !pg.SupportsTables || !pg.SupportsSSL {
+		t.Errorf("PostgreSQL: got %+v", pg)
+	}
+	if sqlite := byType[models.SQLite]; sqlite.SupportsSSL || len(sqlite.RequiredFields) != 1 || sqlite.RequiredFields[0] != "database" {
+		t.Errorf("SQLite: got %+v", sqlite)
+	}
+}
@@ -3,7 +3,10 @@ package database
 import (
 	"context"
 	"database-manager/models"
+	"encoding/base64"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -19,6 +22,18 @@ func NewCassandraDriver() *CassandraDriver {
 	return &CassandraDriver{}
 }
 
+// Capabilities: переименование таблиц (ALTER TABLE ... RENAME TO) и keyspace
+// в Cassandra не поддерживается
+func (d *CassandraDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "cql",
+		SupportsCreateDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
+		SupportsUsers:          true,
+	}
+}
+
 func (d *CassandraDriver) Connect(ctx context.Context, conn models.Connection) error {
 	cluster := gocql.NewCluster(conn.Host)
 	cluster.Port = 9042
@@ -67,18 +82,53 @@ func (d *CassandraDriver) Ping(ctx context.Context) error {
 	return d.session.Query("SELECT now() FROM system.local").Exec()
 }
 
+// defaultCassandraPageSize - размер страницы результата, если у подключения
+// не задан pageSize (совпадает со значением gocql по умолчанию)
+const defaultCassandraPageSize = 5000
+
+// ExecuteQuery возвращает только первую страницу результата; следующие
+// запрашиваются через ExecuteQueryPage с токеном NextPageState
 func (d *CassandraDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	return d.ExecuteQueryPage(ctx, query, 0, "")
+}
+
+// ExecuteQueryPage читает одну страницу результата, не загружая в память
+// всю партицию. pageState - токен из NextPageState предыдущего ответа
+// (base64), pageSize <= 0 - размер страницы подключения
+func (d *CassandraDriver) ExecuteQueryPage(ctx context.Context, query string, pageSize int, pageState string) (*models.QueryResponse, error) {
 	if d.session == nil {
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
 	startTime := time.Now()
-	iter := d.session.Query(query).Iter()
+
+	if isWriteStatement(query) {
+		return d.executeWrite(ctx, query, startTime), nil
+	}
+
+	if pageSize <= 0 {
+		pageSize = d.conn.PageSize
+	}
+	if pageSize <= 0 {
+		pageSize = defaultCassandraPageSize
+	}
+
+	var state []byte
+	if pageState != "" {
+		var err error
+		if state, err = base64.URLEncoding.DecodeString(pageState); err != nil {
+			return &models.QueryResponse{Error: "неверный pageState"}, nil
+		}
+	}
+
+	// PageState отключает автоматическую подгрузку страниц в итераторе
+	iter := d.session.Query(query).WithContext(ctx).PageSize(pageSize).PageState(state).Iter()
+	nextPageState := iter.PageState()
 
 	columns := iter.Columns()
 	rowsData := make([]map[string]interface{}, 0)
 
-	var row map[string]interface{}
+	row := make(map[string]interface{})
 	for iter.MapScan(row) {
 		rowsData = append(rowsData, row)
 		row = make(map[string]interface{})
@@ -97,12 +147,73 @@ func (d *CassandraDriver) ExecuteQuery(ctx context.Context, query string) (*mode
 
 	executionTime := time.Since(startTime).Milliseconds()
 
-	return &models.QueryResponse{
+	response := &models.QueryResponse{
 		Columns:       columnNames,
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
-	}, nil
+	}
+	if len(nextPageState) > 0 {
+		response.NextPageState = base64.URLEncoding.EncodeToString(nextPageState)
+	}
+	return response, nil
+}
+
+// executeWrite выполняет INSERT/UPDATE/DELETE. CQL не сообщает число
+// измененных строк, оно известно только для легковесных транзакций (IF ...):
+// 1, если условие выполнено, иначе 0.
+func (d *CassandraDriver) executeWrite(ctx context.Context, query string, startTime time.Time) *models.QueryResponse {
+	response := &models.QueryResponse{
+		Columns: []string{},
+		Rows:    []map[string]interface{}{},
+	}
+
+	q := d.session.Query(query).WithContext(ctx)
+	if hasConditionalClause(query) {
+		applied, err := q.MapScanCAS(make(map[string]interface{}))
+		if err != nil {
+			return &models.QueryResponse{Error: err.Error()}
+		}
+		if applied {
+			response.RowsAffected = 1
+		}
+	} else if err := q.Exec(); err != nil {
+		return &models.QueryResponse{Error: err.Error()}
+	}
+
+	response.ExecutionTime = time.Since(startTime).Milliseconds()
+	return response
+}
+
+func hasConditionalClause(query string) bool {
+	for _, word := range strings.Fields(strings.ToUpper(query)) {
+		if word == "IF" {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *CassandraDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.session == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	// CQL не поддерживает OFFSET, поэтому читаем limit+offset строк и отбрасываем лишние
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d", quoteCassandraTable(table), limit+offset)
+	result, err := d.ExecuteQueryPage(ctx, query, limit+offset, "")
+	if err != nil || result.Error != "" {
+		return result, err
+	}
+	result.NextPageState = ""
+
+	if offset >= len(result.Rows) {
+		result.Rows = make([]map[string]interface{}, 0)
+	} else {
+		result.Rows = result.Rows[offset:]
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
 }
 
 func (d *CassandraDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
@@ -129,6 +240,14 @@ func (d *CassandraDriver) ListDatabases(ctx context.Context) ([]models.DatabaseI
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	tableCounts := make(map[string]int64)
+	countIter := d.session.Query("SELECT keyspace_name FROM system_schema.tables").WithContext(ctx).Iter()
+	var tableKeyspace string
+	for countIter.Scan(&tableKeyspace) {
+		tableCounts[tableKeyspace]++
+	}
+	countErr := countIter.Close()
+
 	query := "SELECT keyspace_name, durable_writes FROM system_schema.keyspaces WHERE keyspace_name NOT IN ('system', 'system_schema', 'system_auth', 'system_distributed', 'system_traces')"
 	iter := d.session.Query(query).Iter()
 
@@ -137,9 +256,14 @@ func (d *CassandraDriver) ListDatabases(ctx context.Context) ([]models.DatabaseI
 	var durableWrites bool
 
 	for iter.Scan(&keyspaceName, &durableWrites) {
-		databases = append(databases, models.DatabaseInfo{
+		db := models.DatabaseInfo{
 			Name: keyspaceName,
-		})
+		}
+		if countErr == nil {
+			count := tableCounts[keyspaceName]
+			db.ObjectCount = &count
+		}
+		databases = append(databases, db)
 	}
 
 	if err := iter.Close(); err != nil {
@@ -185,6 +309,12 @@ func (d *CassandraDriver) DeleteDatabase(ctx context.Context, name string) error
 }
 
 func (d *CassandraDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return d.CreateTableWithConstraints(ctx, name, columns, models.TableConstraints{})
+}
+
+// CreateTableWithConstraints: первый столбец первичного ключа становится
+// ключом партиции, остальные - кластерными. CHECK в CQL не поддерживается
+func (d *CassandraDriver) CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error {
 	if d.session == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
@@ -193,15 +323,20 @@ func (d *CassandraDriver) CreateTable(ctx context.Context, name string, columns
 		return fmt.Errorf("необходимо указать хотя бы одну колонку")
 	}
 
-	primaryKeys := make([]string, 0)
+	if len(constraints.Constraints) > 0 {
+		return fmt.Errorf("Cassandra не поддерживает ограничения CHECK")
+	}
+
+	primaryKeys, err := tablePrimaryKey(columns, constraints)
+	if err != nil {
+		return err
+	}
+
 	cols := make([]string, 0, len(columns))
 
 	for _, col := range columns {
 		colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
 		cols = append(cols, colDef)
-		if col.PrimaryKey {
-			primaryKeys = append(primaryKeys, col.Name)
-		}
 	}
 
 	if len(primaryKeys) == 0 {
@@ -252,9 +387,115 @@ func (d *CassandraDriver) ListTables(ctx context.Context) ([]models.TableInfo, e
 		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
 	}
 
+	columns, err := d.loadColumns(ctx, keyspace, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		tables[i].Columns = columns[tables[i].Name]
+	}
+
 	return tables, nil
 }
 
+// DescribeTable возвращает столбцы таблицы вместе с устройством первичного
+// ключа: сначала ключ партиции, затем clustering-столбцы в порядке ключа.
+// Таблица задается как name или keyspace.name
+func (d *CassandraDriver) DescribeTable(ctx context.Context, table string) ([]models.TableColumn, error) {
+	if d.session == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	keyspace := d.conn.Database
+	if ks, name, found := strings.Cut(table, "."); found {
+		keyspace, table = ks, name
+	}
+
+	columns, err := d.loadColumns(ctx, keyspace, table)
+	if err != nil {
+		return nil, err
+	}
+	if len(columns[table]) == 0 {
+		return nil, fmt.Errorf("таблица %s.%s не найдена", keyspace, table)
+	}
+
+	return columns[table], nil
+}
+
+// cassandraColumn - столбец из system_schema.columns; position - номер
+// в ключе партиции или clustering-ключе (-1 для обычных столбцов)
+type cassandraColumn struct {
+	models.TableColumn
+	position int
+}
+
+// loadColumns читает столбцы из system_schema.columns и группирует их
+// по таблицам. Пустой table - все таблицы keyspace
+func (d *CassandraDriver) loadColumns(ctx context.Context, keyspace, table string) (map[string][]models.TableColumn, error) {
+	query := "SELECT table_name, column_name, type, kind, position FROM system_schema.columns WHERE keyspace_name = ?"
+	args := []interface{}{keyspace}
+	if table != "" {
+		query += " AND table_name = ?"
+		args = append(args, table)
+	}
+
+	iter := d.session.Query(query, args...).WithContext(ctx).Iter()
+
+	byTable := make(map[string][]cassandraColumn)
+	var tableName, name, columnType, kind string
+	var position int
+	for iter.Scan(&tableName, &name, &columnType, &kind, &position) {
+		byTable[tableName] = append(byTable[tableName], cassandraColumn{
+			TableColumn: models.TableColumn{
+				Name:       name,
+				Type:       columnType,
+				Kind:       kind,
+				PrimaryKey: kind == "partition_key" || kind == "clustering",
+				Nullable:   kind == "regular" || kind == "static",
+			},
+			position: position,
+		})
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка получения столбцов таблиц: %w", err)
+	}
+
+	result := make(map[string][]models.TableColumn, len(byTable))
+	for name, columns := range byTable {
+		result[name] = sortCassandraColumns(columns)
+	}
+	return result, nil
+}
+
+var cassandraKindOrder = map[string]int{
+	"partition_key": 0,
+	"clustering":    1,
+	"static":        2,
+	"regular":       3,
+}
+
+// sortCassandraColumns упорядочивает столбцы как в CREATE TABLE: ключ
+// партиции и clustering-ключ по позиции, затем остальные по имени
+func sortCassandraColumns(columns []cassandraColumn) []models.TableColumn {
+	sort.SliceStable(columns, func(i, j int) bool {
+		a, b := columns[i], columns[j]
+		if cassandraKindOrder[a.Kind] != cassandraKindOrder[b.Kind] {
+			return cassandraKindOrder[a.Kind] < cassandraKindOrder[b.Kind]
+		}
+		if a.position != b.position {
+			return a.position < b.position
+		}
+		return a.Name < b.Name
+	})
+
+	result := make([]models.TableColumn, len(columns))
+	for i, column := range columns {
+		result[i] = column.TableColumn
+	}
+	return result
+}
+
 func (d *CassandraDriver) DeleteTable(ctx context.Context, name string) error {
 	if d.session == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -264,6 +505,17 @@ func (d *CassandraDriver) DeleteTable(ctx context.Context, name string) error {
 	return d.session.Query(query).Exec()
 }
 
+func (d *CassandraDriver) TruncateTable(ctx context.Context, name string) error {
+	if d.session == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if err := d.session.Query("TRUNCATE " + quoteCassandraTable(name)).WithContext(ctx).Exec(); err != nil {
+		return fmt.Errorf("ошибка очистки таблицы: %w", err)
+	}
+	return nil
+}
+
 func (d *CassandraDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
 	if d.session == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -300,8 +552,9 @@ func (d *CassandraDriver) CreateUser(ctx context.Context, username, password, da
 		return fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
-	if len(permissions) > 0 {
-		for _, perm := range permissions {
+	privileges, roles := splitGrants(permissions, cassandraPermissions)
+	if len(privileges) > 0 {
+		for _, perm := range privileges {
 			grantQuery := fmt.Sprintf("GRANT %s ON KEYSPACE %s TO %s", perm, database, username)
 			if database == "" {
 				grantQuery = fmt.Sprintf("GRANT %s ON ALL KEYSPACES TO %s", perm, username)
@@ -311,6 +564,11 @@ func (d *CassandraDriver) CreateUser(ctx context.Context, username, password, da
 			}
 		}
 	}
+	for _, role := range roles {
+		if err := d.session.Query(fmt.Sprintf("GRANT %s TO %s", quoteCassandraName(role), username)).Exec(); err != nil {
+			return fmt.Errorf("ошибка выдачи роли %s: %w", role, err)
+		}
+	}
 
 	return nil
 }
@@ -369,11 +627,16 @@ func (d *CassandraDriver) UpdateUser(ctx context.Context, username, password str
 	}
 
 	if permissions != nil {
+		privileges, roles := splitGrants(permissions, cassandraPermissions)
 		revokeQuery := fmt.Sprintf("REVOKE ALL PERMISSIONS ON ALL KEYSPACES FROM %s", username)
 		d.session.Query(revokeQuery).Exec()
 
-		if len(permissions) > 0 {
-			for _, perm := range permissions {
+		if err := d.replaceUserRoles(username, roles); err != nil {
+			return err
+		}
+
+		if len(privileges) > 0 {
+			for _, perm := range privileges {
 				grantQuery := fmt.Sprintf("GRANT %s ON KEYSPACE %s TO %s", perm, d.conn.Database, username)
 				if err := d.session.Query(grantQuery).Exec(); err != nil {
 					return fmt.Errorf("ошибка обновления прав: %w", err)
@@ -385,6 +648,85 @@ func (d *CassandraDriver) UpdateUser(ctx context.Context, username, password str
 	return nil
 }
 
+// cassandraPermissions - права, которые принимает permissions (GRANT ...
+// ON KEYSPACE); остальные значения permissions - роли
+var cassandraPermissions = []string{"ALL", "CREATE", "ALTER", "DROP", "SELECT", "MODIFY", "AUTHORIZE", "DESCRIBE", "EXECUTE"}
+
+// quoteCassandraName экранирует имя двойными кавычками, сохраняя регистр
+func quoteCassandraName(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// quoteCassandraTable экранирует каждую часть имени keyspace.table
+func quoteCassandraTable(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quoteCassandraName(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// replaceUserRoles отзывает у пользователя роли не из roles и выдает
+// недостающие
+func (d *CassandraDriver) replaceUserRoles(username string, roles []string) error {
+	current := make(map[string]bool)
+	iter := d.session.Query("SELECT role FROM system_auth.role_members WHERE member = ?", username).Iter()
+	var role string
+	for iter.Scan(&role) {
+		current[role] = true
+	}
+	if err := iter.Close(); err != nil {
+		return fmt.Errorf("ошибка получения ролей пользователя: %w", err)
+	}
+
+	wanted := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		wanted[role] = true
+		if current[role] {
+			continue
+		}
+		if err := d.session.Query(fmt.Sprintf("GRANT %s TO %s", quoteCassandraName(role), username)).Exec(); err != nil {
+			return fmt.Errorf("ошибка выдачи роли %s: %w", role, err)
+		}
+	}
+	for role := range current {
+		if wanted[role] {
+			continue
+		}
+		if err := d.session.Query(fmt.Sprintf("REVOKE %s FROM %s", quoteCassandraName(role), username)).Exec(); err != nil {
+			return fmt.Errorf("ошибка отзыва роли %s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// ListGrantableRoles возвращает права cassandraPermissions и роли без
+// права входа из system_auth.roles
+func (d *CassandraDriver) ListGrantableRoles(ctx context.Context) ([]models.GrantableRole, error) {
+	if d.session == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	result := privilegeGrantables(cassandraPermissions)
+	iter := d.session.Query("SELECT role, can_login FROM system_auth.roles").WithContext(ctx).Iter()
+	var name string
+	var canLogin bool
+	var roles []string
+	for iter.Scan(&name, &canLogin) {
+		if !canLogin {
+			roles = append(roles, name)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка ролей: %w", err)
+	}
+	sort.Strings(roles)
+	for _, role := range roles {
+		result = append(result, models.GrantableRole{Name: role, Kind: models.GrantKindRole})
+	}
+	return result, nil
+}
+
 func (d *CassandraDriver) DeleteUser(ctx context.Context, username string) error {
 	if d.session == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestSortCassandraColumns(t *testing.T) {
+	columns := []cassandraColumn{
+		{TableColumn: models.TableColumn{Name: "payload", Kind: "regular"}, position: -1},
+		{TableColumn: models.TableColumn{Name: "ts", Kind: "clustering"}, position: 1},
+		{TableColumn: models.TableColumn{Name: "bucket", Kind: "partition_key"}, position: 1},
+		{TableColumn: models.TableColumn{Name: "author", Kind: "regular"}, position: -1},
+		{TableColumn: models.TableColumn{Name: "user_id", Kind: "partition_key"}, position: 0},
+		{TableColumn: models.TableColumn{Name: "event_id", Kind: "clustering"}, position: 0},
+		{TableColumn: models.TableColumn{Name: "owner", Kind: "static"}, position: -1},
+	}
+
+	want := []string{"user_id", "bucket", "event_id", "ts", "owner", "author", "payload"}
+	var got []string
+	for _, column := range sortCassandraColumns(columns) {
+		got = append(got, column.Name)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("sortCassandraColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestQuoteCassandraTable(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"users", `"users"`},
+		{"shop.Orders", `"shop"."Orders"`},
+		{`users" WHERE token(id) > 0 ALLOW FILTERING --`, `"users"" WHERE token(id) > 0 ALLOW FILTERING --"`},
+	}
+
+	for _, tt := range tests {
+		if got := quoteCassandraTable(tt.name); got != tt.want {
+			t.Errorf("quoteCassandraTable(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package database
 
 import (
 	"context"
-	"crypto/tls"
 	"database-manager/models"
 	"database/sql"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
@@ -22,23 +25,50 @@ func NewClickHouseDriver() *ClickHouseDriver {
 	return &ClickHouseDriver{}
 }
 
+func (d *ClickHouseDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "sql",
+		SupportsCreateDatabase: true,
+		SupportsRenameDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
+		SupportsRename:         true,
+		SupportsUsers:          true,
+	}
+}
+
 func (d *ClickHouseDriver) Connect(ctx context.Context, conn models.Connection) error {
-	dsn := fmt.Sprintf("clickhouse://%s:%s@%s:%s/%s",
-		conn.Username, conn.Password, conn.Host, conn.Port, conn.Database)
-	
-	if conn.SSL {
-		dsn += "?secure=true"
+	options, err := clickHouseOptions(conn)
+	if err != nil {
+		return err
 	}
 
-	options, err := clickhouse.ParseDSN(dsn)
+	chConn, err := clickhouse.Open(options)
 	if err != nil {
-		return fmt.Errorf("ошибка парсинга DSN: %w", err)
+		return fmt.Errorf("ошибка подключения к ClickHouse: %w", err)
 	}
 
-	if conn.SSL {
-		options.TLS = &tls.Config{
-			InsecureSkipVerify: false,
-		}
+	if err := chConn.Ping(ctx); err != nil {
+		return fmt.Errorf("ошибка ping ClickHouse: %w", err)
+	}
+
+	d.conn = chConn
+	d.dbConn = conn
+	return nil
+}
+
+// UseDatabase меняет базу по умолчанию. USE действует только на одно
+// соединение пула, поэтому пул открывается заново с новой базой
+func (d *ClickHouseDriver) UseDatabase(ctx context.Context, name string) error {
+	if d.conn == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	conn := d.dbConn
+	conn.Database = name
+	options, err := clickHouseOptions(conn)
+	if err != nil {
+		return err
 	}
 
 	chConn, err := clickhouse.Open(options)
@@ -47,14 +77,43 @@ func (d *ClickHouseDriver) Connect(ctx context.Context, conn models.Connection)
 	}
 
 	if err := chConn.Ping(ctx); err != nil {
-		return fmt.Errorf("ошибка ping ClickHouse: %w", err)
+		chConn.Close()
+		return fmt.Errorf("ошибка переключения на базу %s: %w", name, err)
 	}
 
+	d.conn.Close()
 	d.conn = chConn
 	d.dbConn = conn
 	return nil
 }
 
+// clickHouseOptions собирает DSN через net/url, чтобы символы @, :, / и ?
+// в логине или пароле не ломали разбор
+func clickHouseOptions(conn models.Connection) (*clickhouse.Options, error) {
+	u := url.URL{
+		Scheme: "clickhouse",
+		User:   url.UserPassword(conn.Username, conn.Password),
+		Host:   net.JoinHostPort(conn.Host, conn.Port),
+		Path:   "/" + conn.Database,
+	}
+	if conn.SSL {
+		u.RawQuery = "secure=true"
+	}
+
+	options, err := clickhouse.ParseDSN(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка парсинга DSN: %w", err)
+	}
+
+	if conn.SSL {
+		if options.TLS, err = connTLSConfig(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
 func (d *ClickHouseDriver) Disconnect(ctx context.Context) error {
 	if d.conn != nil {
 		return d.conn.Close()
@@ -81,8 +140,40 @@ func (d *ClickHouseDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	return d.query(ctx, query)
+}
+
+func (d *ClickHouseDriver) query(ctx context.Context, query string, args ...interface{}) (*models.QueryResponse, error) {
 	startTime := time.Now()
-	rows, err := d.conn.Query(ctx, query)
+
+	if isWriteStatement(query) {
+		// Exec не возвращает число строк, поэтому считаем записанные строки по прогрессу.
+		// Для мутаций (ALTER TABLE ... UPDATE/DELETE) сервер их не сообщает.
+		var wroteRows uint64
+		var progressMu sync.Mutex
+		ctx = clickhouse.Context(ctx, clickhouse.WithProgress(func(p *clickhouse.Progress) {
+			progressMu.Lock()
+			wroteRows += p.WroteRows
+			progressMu.Unlock()
+		}))
+
+		if err := d.conn.Exec(ctx, query, args...); err != nil {
+			return &models.QueryResponse{
+				Error: err.Error(),
+			}, nil
+		}
+
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		return &models.QueryResponse{
+			Columns:       []string{},
+			Rows:          []map[string]interface{}{},
+			RowsAffected:  int64(wroteRows),
+			ExecutionTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	rows, err := d.conn.Query(ctx, query, args...)
 	if err != nil {
 		return &models.QueryResponse{
 			Error: err.Error(),
@@ -93,8 +184,15 @@ func (d *ClickHouseDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 	columns := rows.Columns()
 	columnTypes := rows.ColumnTypes()
 
+	maxRows := maxRowsFromContext(ctx)
+	truncated := false
 	rowsData := make([]map[string]interface{}, 0)
 	for rows.Next() {
+		// Следующая строка есть, а предел достигнут - дальше не читаем
+		if maxRows > 0 && len(rowsData) >= maxRows {
+			truncated = true
+			break
+		}
 		values := make([]interface{}, len(columns))
 		valuePtrs := make([]interface{}, len(columns))
 		for i := range values {
@@ -125,16 +223,120 @@ func (d *ClickHouseDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
+		Truncated:     truncated,
 	}, nil
 }
 
+func (d *ClickHouseDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", quoteClickHouseIdentifier(table), limit, offset)
+	return d.ExecuteQuery(ctx, query)
+}
+
+func (d *ClickHouseDriver) DescribeTable(ctx context.Context, table string) ([]models.TableColumn, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	rows, err := d.conn.Query(ctx, "DESCRIBE TABLE "+quoteClickHouseIdentifier(table))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения столбцов таблицы: %w", err)
+	}
+	defer rows.Close()
+
+	columnNames := rows.Columns()
+	var columns []models.TableColumn
+	for rows.Next() {
+		// DESCRIBE возвращает разное число служебных столбцов в зависимости от версии,
+		// поэтому читаем все как строки и используем только name и type
+		values := make([]string, len(columnNames))
+		valuePtrs := make([]interface{}, len(columnNames))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, fmt.Errorf("ошибка чтения столбца: %w", err)
+		}
+		columns = append(columns, models.TableColumn{
+			Name:     values[0],
+			Type:     values[1],
+			Nullable: strings.HasPrefix(values[1], "Nullable("),
+		})
+	}
+
+	return columns, rows.Err()
+}
+
+// SearchTable ищет строки, в которых хотя бы один из столбцов содержит text
+// (без учета регистра). Если столбцы не заданы, используются все строковые.
+func (d *ClickHouseDriver) SearchTable(ctx context.Context, table, text string, columns []string, limit, offset int) (*models.QueryResponse, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	if len(columns) == 0 {
+		described, err := d.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range described {
+			if strings.Contains(col.Type, "String") {
+				columns = append(columns, col.Name)
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("в таблице %s нет строковых столбцов", table)
+		}
+	}
+
+	pattern := "%" + escapeLikePattern(text) + "%"
+	conditions := make([]string, len(columns))
+	args := make([]interface{}, len(columns))
+	for i, col := range columns {
+		conditions[i] = fmt.Sprintf("toString(%s) ILIKE ?", quoteClickHouseIdentifier(col))
+		args[i] = pattern
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT %d OFFSET %d",
+		quoteClickHouseIdentifier(table), strings.Join(conditions, " OR "), limit, offset)
+	return d.query(ctx, query, args...)
+}
+
+// quoteClickHouseIdentifier экранирует имя обратными кавычками,
+// сохраняя разделение database.table
+func quoteClickHouseIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quoteClickHouseName(part)
+	}
+	return strings.Join(parts, ".")
+}
+
+// quoteClickHouseName экранирует одно имя (пользователя, роли) целиком:
+// точка в нем не разделяет части. Внутри обратных кавычек ClickHouse
+// понимает escape-последовательности, поэтому сначала удваивается \
+func quoteClickHouseName(name string) string {
+	name = strings.ReplaceAll(name, `\`, `\\`)
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
 func (d *ClickHouseDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
 	if d.conn == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	query := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", name)
-	return d.conn.Exec(ctx, query)
+	return d.conn.Exec(ctx, clickhouseCreateDatabase(name))
+}
+
+func clickhouseCreateDatabase(name string) string {
+	return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", name)
+}
+
+func (d *ClickHouseDriver) PlanCreateDatabase(name string, options map[string]interface{}) ([]string, error) {
+	return []string{clickhouseCreateDatabase(name)}, nil
 }
 
 func (d *ClickHouseDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
@@ -142,6 +344,19 @@ func (d *ClickHouseDriver) ListDatabases(ctx context.Context) ([]models.Database
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	tableCounts := make(map[string]int64)
+	countRows, err := d.conn.Query(ctx, "SELECT database, count() FROM system.tables GROUP BY database")
+	if err == nil {
+		for countRows.Next() {
+			var dbName string
+			var count uint64
+			if countRows.Scan(&dbName, &count) == nil {
+				tableCounts[dbName] = int64(count)
+			}
+		}
+		countRows.Close()
+	}
+
 	query := "SELECT name, engine, data_path FROM system.databases WHERE name NOT IN ('system', 'information_schema', 'INFORMATION_SCHEMA') ORDER BY name"
 	rows, err := d.conn.Query(ctx, query)
 	if err != nil {
@@ -167,6 +382,12 @@ func (d *ClickHouseDriver) ListDatabases(ctx context.Context) ([]models.Database
 			sizeRows.Close()
 		}
 
+		if count, ok := tableCounts[db.Name]; ok {
+			db.ObjectCount = &count
+		} else {
+			db.ObjectCount = new(int64)
+		}
+
 		databases = append(databases, db)
 	}
 
@@ -179,8 +400,7 @@ func (d *ClickHouseDriver) UpdateDatabase(ctx context.Context, oldName, newName
 	}
 
 	if newName != "" && newName != oldName {
-		query := fmt.Sprintf("RENAME DATABASE %s TO %s", oldName, newName)
-		if err := d.conn.Exec(ctx, query); err != nil {
+		if err := d.conn.Exec(ctx, clickhouseRenameDatabase(oldName, newName)); err != nil {
 			return fmt.Errorf("ошибка переименования базы данных: %w", err)
 		}
 	}
@@ -188,40 +408,99 @@ func (d *ClickHouseDriver) UpdateDatabase(ctx context.Context, oldName, newName
 	return nil
 }
 
+func clickhouseRenameDatabase(oldName, newName string) string {
+	return fmt.Sprintf("RENAME DATABASE %s TO %s", oldName, newName)
+}
+
+// PlanUpdateDatabase: из параметров базы ClickHouse меняет только имя
+func (d *ClickHouseDriver) PlanUpdateDatabase(oldName, newName string, options map[string]interface{}) ([]string, error) {
+	if newName == "" || newName == oldName {
+		return []string{}, nil
+	}
+	return []string{clickhouseRenameDatabase(oldName, newName)}, nil
+}
+
 func (d *ClickHouseDriver) DeleteDatabase(ctx context.Context, name string) error {
 	if d.conn == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	query := fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)
-	if err := d.conn.Exec(ctx, query); err != nil {
+	if err := d.conn.Exec(ctx, clickhouseDropDatabase(name)); err != nil {
 		return fmt.Errorf("ошибка удаления базы данных: %w", err)
 	}
 
 	return nil
 }
 
+func clickhouseDropDatabase(name string) string {
+	return fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)
+}
+
+func (d *ClickHouseDriver) PlanDeleteDatabase(name string) ([]string, error) {
+	return []string{clickhouseDropDatabase(name)}, nil
+}
+
 func (d *ClickHouseDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return d.CreateTableWithConstraints(ctx, name, columns, models.TableConstraints{})
+}
+
+// CreateTableWithConstraints создает MergeTree-таблицу: первичный ключ
+// становится ключом сортировки ORDER BY, ограничения - CONSTRAINT ... CHECK
+func (d *ClickHouseDriver) CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error {
 	if d.conn == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
+	query, err := clickhouseCreateTable(name, columns, constraints)
+	if err != nil {
+		return err
+	}
+
+	return d.conn.Exec(ctx, query)
+}
+
+func (d *ClickHouseDriver) PlanCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) ([]string, error) {
+	query, err := clickhouseCreateTable(name, columns, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return []string{query}, nil
+}
+
+func clickhouseCreateTable(name string, columns []models.TableColumn, constraints models.TableConstraints) (string, error) {
 	if len(columns) == 0 {
-		return fmt.Errorf("необходимо указать хотя бы одну колонку")
+		return "", fmt.Errorf("необходимо указать хотя бы одну колонку")
 	}
 
-	cols := make([]string, 0, len(columns))
+	primaryKey, err := tablePrimaryKey(columns, constraints)
+	if err != nil {
+		return "", err
+	}
+
+	cols := make([]string, 0, len(columns)+len(constraints.Constraints))
 	for _, col := range columns {
+		if col.References != "" {
+			return "", fmt.Errorf("ClickHouse не поддерживает внешние ключи (колонка %s)", col.Name)
+		}
 		colDef := fmt.Sprintf("  %s %s", col.Name, col.Type)
 		if !col.Nullable {
 			colDef += " NOT NULL"
 		}
+		if col.Default != "" {
+			colDef += " DEFAULT " + col.Default
+		}
 		cols = append(cols, colDef)
 	}
+	for i, check := range constraints.Constraints {
+		cols = append(cols, fmt.Sprintf("  CONSTRAINT check_%d CHECK %s", i+1, check))
+	}
 
-	query := fmt.Sprintf("CREATE TABLE %s (\n%s\n) ENGINE = MergeTree() ORDER BY tuple()", name, strings.Join(cols, ",\n"))
+	orderBy := "tuple()"
+	if len(primaryKey) > 0 {
+		orderBy = "(" + strings.Join(primaryKey, ", ") + ")"
+	}
 
-	return d.conn.Exec(ctx, query)
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n) ENGINE = MergeTree() ORDER BY %s", name, strings.Join(cols, ",\n"), orderBy), nil
 }
 
 func (d *ClickHouseDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
@@ -272,8 +551,26 @@ func (d *ClickHouseDriver) DeleteTable(ctx context.Context, name string) error {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", name)
-	return d.conn.Exec(ctx, query)
+	return d.conn.Exec(ctx, clickhouseDropTable(name))
+}
+
+func clickhouseDropTable(name string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", name)
+}
+
+func (d *ClickHouseDriver) PlanDeleteTable(name string) ([]string, error) {
+	return []string{clickhouseDropTable(name)}, nil
+}
+
+func (d *ClickHouseDriver) TruncateTable(ctx context.Context, name string) error {
+	if d.conn == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if err := d.conn.Exec(ctx, "TRUNCATE TABLE "+quoteClickHouseIdentifier(name)); err != nil {
+		return fmt.Errorf("ошибка очистки таблицы: %w", err)
+	}
+	return nil
 }
 
 func (d *ClickHouseDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
@@ -281,30 +578,43 @@ func (d *ClickHouseDriver) UpdateTable(ctx context.Context, oldName, newName str
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	if newName != "" && newName != oldName {
-		query := fmt.Sprintf("RENAME TABLE %s TO %s", oldName, newName)
-		if err := d.conn.Exec(ctx, query); err != nil {
+	changes, err := planTableChanges(oldName, newName, columns, "RENAME TABLE %s TO %s", clickhouseAddColumn)
+	if err != nil {
+		return err
+	}
+
+	if changes.rename != "" {
+		if err := d.conn.Exec(ctx, changes.rename); err != nil {
 			return fmt.Errorf("ошибка переименования таблицы: %w", err)
 		}
-		oldName = newName
 	}
 
-	if len(columns) > 0 {
-		for _, col := range columns {
-			colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
-			if !col.Nullable {
-				colDef += " NOT NULL"
-			}
-			query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", oldName, colDef)
-			if err := d.conn.Exec(ctx, query); err != nil {
-				return fmt.Errorf("ошибка добавления колонки %s: %w", col.Name, err)
-			}
+	for i, col := range columns {
+		if err := d.conn.Exec(ctx, changes.columns[i]); err != nil {
+			return fmt.Errorf("ошибка добавления колонки %s: %w", col.Name, err)
 		}
 	}
 
 	return nil
 }
 
+func (d *ClickHouseDriver) PlanUpdateTable(oldName, newName string, columns []models.TableColumn) ([]string, error) {
+	changes, err := planTableChanges(oldName, newName, columns, "RENAME TABLE %s TO %s", clickhouseAddColumn)
+	if err != nil {
+		return nil, err
+	}
+	return changes.statements(), nil
+}
+
+// clickhouseAddColumn: UpdateTable в ClickHouse только добавляет столбцы
+func clickhouseAddColumn(table string, col models.TableColumn) (string, error) {
+	colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
+	if !col.Nullable {
+		colDef += " NOT NULL"
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", table, colDef), nil
+}
+
 func (d *ClickHouseDriver) CreateUser(ctx context.Context, username, password, database string, permissions []string) error {
 	if d.conn == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -315,12 +625,18 @@ func (d *ClickHouseDriver) CreateUser(ctx context.Context, username, password, d
 		return fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
-	if len(permissions) > 0 {
-		grantQuery := fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.Join(permissions, ", "), database, username)
+	privileges, roles := splitGrants(permissions, clickhousePrivileges)
+	if len(privileges) > 0 {
+		grantQuery := fmt.Sprintf("GRANT %s ON %s.* TO %s", strings.Join(privileges, ", "), database, username)
 		if err := d.conn.Exec(ctx, grantQuery); err != nil {
 			return fmt.Errorf("ошибка выдачи прав: %w", err)
 		}
 	}
+	for _, role := range roles {
+		if err := d.conn.Exec(ctx, fmt.Sprintf("GRANT %s TO %s", quoteClickHouseIdentifier(role), username)); err != nil {
+			return fmt.Errorf("ошибка выдачи роли %s: %w", role, err)
+		}
+	}
 
 	return nil
 }
@@ -343,36 +659,77 @@ func (d *ClickHouseDriver) ListUsers(ctx context.Context) ([]models.UserInfo, er
 		if err := rows.Scan(&username); err != nil {
 			continue
 		}
+		users = append(users, d.userInfo(ctx, username))
+	}
 
-		grantsQuery := fmt.Sprintf("SHOW GRANTS FOR %s", username)
-		grantsRows, err := d.conn.Query(ctx, grantsQuery)
-		permissions := make([]string, 0)
-		if err == nil {
-			for grantsRows.Next() {
-				var grant string
-				if err := grantsRows.Scan(&grant); err == nil {
-					permissions = append(permissions, grant)
-				}
-			}
-			grantsRows.Close()
+	return users, nil
+}
+
+// SearchUsers - ListUsers с поиском по имени (ILIKE) и пагинацией в запросе
+// к system.users: SHOW GRANTS выполняется только для пользователей страницы
+func (d *ClickHouseDriver) SearchUsers(ctx context.Context, filter models.UserFilter) (*models.UserList, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	pattern := "%" + escapeLikePattern(filter.Search) + "%"
+	var total uint64
+	if err := d.conn.QueryRow(ctx, "SELECT count() FROM system.users WHERE name ILIKE ?", pattern).Scan(&total); err != nil {
+		return nil, fmt.Errorf("ошибка подсчета пользователей: %w", err)
+	}
+
+	query := "SELECT name FROM system.users WHERE name ILIKE ? ORDER BY name"
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", filter.Offset)
+	}
+	rows, err := d.conn.Query(ctx, query, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	list := &models.UserList{Users: []models.UserInfo{}, Total: int(total)}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			continue
 		}
+		list.Users = append(list.Users, d.userInfo(ctx, username))
+	}
+	return list, nil
+}
 
-		isSuperuser := false
-		for _, perm := range permissions {
-			if strings.Contains(strings.ToUpper(perm), "ALL") || strings.Contains(strings.ToUpper(perm), "ADMIN") {
-				isSuperuser = true
-				break
+// userInfo дополняет имя пользователя его правами из SHOW GRANTS
+func (d *ClickHouseDriver) userInfo(ctx context.Context, username string) models.UserInfo {
+	grantsQuery := fmt.Sprintf("SHOW GRANTS FOR %s", username)
+	grantsRows, err := d.conn.Query(ctx, grantsQuery)
+	permissions := make([]string, 0)
+	if err == nil {
+		for grantsRows.Next() {
+			var grant string
+			if err := grantsRows.Scan(&grant); err == nil {
+				permissions = append(permissions, grant)
 			}
 		}
+		grantsRows.Close()
+	}
 
-		users = append(users, models.UserInfo{
-			Username:    username,
-			Permissions: permissions,
-			IsSuperuser: isSuperuser,
-		})
+	isSuperuser := false
+	for _, perm := range permissions {
+		if strings.Contains(strings.ToUpper(perm), "ALL") || strings.Contains(strings.ToUpper(perm), "ADMIN") {
+			isSuperuser = true
+			break
+		}
 	}
 
-	return users, nil
+	return models.UserInfo{
+		Username:    username,
+		Permissions: permissions,
+		IsSuperuser: isSuperuser,
+	}
 }
 
 func (d *ClickHouseDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
@@ -388,11 +745,16 @@ func (d *ClickHouseDriver) UpdateUser(ctx context.Context, username, password st
 	}
 
 	if permissions != nil {
+		privileges, roles := splitGrants(permissions, clickhousePrivileges)
 		revokeQuery := fmt.Sprintf("REVOKE ALL ON *.* FROM %s", username)
 		d.conn.Exec(ctx, revokeQuery)
 
-		if len(permissions) > 0 {
-			for _, perm := range permissions {
+		if err := d.replaceUserRoles(ctx, username, roles); err != nil {
+			return err
+		}
+
+		if len(privileges) > 0 {
+			for _, perm := range privileges {
 				grantQuery := fmt.Sprintf("GRANT %s ON %s.* TO %s", perm, d.dbConn.Database, username)
 				if d.dbConn.Database == "" {
 					grantQuery = fmt.Sprintf("GRANT %s ON *.* TO %s", perm, username)
@@ -407,6 +769,71 @@ func (d *ClickHouseDriver) UpdateUser(ctx context.Context, username, password st
 	return nil
 }
 
+// clickhousePrivileges - привилегии, которые принимает permissions
+// (GRANT ... ON база.*); остальные значения permissions - роли
+var clickhousePrivileges = []string{"SELECT", "INSERT", "ALTER", "CREATE", "DROP", "TRUNCATE", "OPTIMIZE", "SHOW", "ALL"}
+
+// replaceUserRoles отзывает у пользователя роли не из roles и выдает
+// недостающие
+func (d *ClickHouseDriver) replaceUserRoles(ctx context.Context, username string, roles []string) error {
+	rows, err := d.conn.Query(ctx, "SELECT granted_role_name FROM system.role_grants WHERE user_name = ?", username)
+	if err != nil {
+		return fmt.Errorf("ошибка получения ролей пользователя: %w", err)
+	}
+	current := make(map[string]bool)
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err == nil {
+			current[role] = true
+		}
+	}
+	rows.Close()
+
+	wanted := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		wanted[role] = true
+		if current[role] {
+			continue
+		}
+		if err := d.conn.Exec(ctx, fmt.Sprintf("GRANT %s TO %s", quoteClickHouseIdentifier(role), username)); err != nil {
+			return fmt.Errorf("ошибка выдачи роли %s: %w", role, err)
+		}
+	}
+	for role := range current {
+		if wanted[role] {
+			continue
+		}
+		if err := d.conn.Exec(ctx, fmt.Sprintf("REVOKE %s FROM %s", quoteClickHouseIdentifier(role), username)); err != nil {
+			return fmt.Errorf("ошибка отзыва роли %s: %w", role, err)
+		}
+	}
+	return nil
+}
+
+// ListGrantableRoles возвращает привилегии clickhousePrivileges и роли из
+// system.roles
+func (d *ClickHouseDriver) ListGrantableRoles(ctx context.Context) ([]models.GrantableRole, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	rows, err := d.conn.Query(ctx, "SELECT name FROM system.roles ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка ролей: %w", err)
+	}
+	defer rows.Close()
+
+	result := privilegeGrantables(clickhousePrivileges)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка чтения роли: %w", err)
+		}
+		result = append(result, models.GrantableRole{Name: name, Kind: models.GrantKindRole})
+	}
+	return result, rows.Err()
+}
+
 func (d *ClickHouseDriver) DeleteUser(ctx context.Context, username string) error {
 	if d.conn == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -420,3 +847,174 @@ func (d *ClickHouseDriver) DeleteUser(ctx context.Context, username string) erro
 	return nil
 }
 
+
+func (d *ClickHouseDriver) ListSettings(ctx context.Context) ([]models.ServerSetting, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := "SELECT name, value, default, description, readonly FROM system.settings ORDER BY name"
+	rows, err := d.conn.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make([]models.ServerSetting, 0)
+	for rows.Next() {
+		var setting models.ServerSetting
+		var readonly uint8
+		if err := rows.Scan(&setting.Name, &setting.Value, &setting.Default, &setting.Description, &readonly); err != nil {
+			continue
+		}
+		setting.Changeable = readonly == 0
+		settings = append(settings, setting)
+	}
+
+	return settings, nil
+}
+
+// SetSetting сохраняет параметр в профиле пользователя подключения (scope "user").
+// Настройки ClickHouse действуют в рамках сессии, поэтому иначе изменение
+// не переживет возврат соединения в пул.
+func (d *ClickHouseDriver) SetSetting(ctx context.Context, name, value, scope string) error {
+	if d.conn == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if scope != "" && scope != "user" {
+		return fmt.Errorf("неизвестная область применения: %s", scope)
+	}
+
+	var readonly uint8
+	row := d.conn.QueryRow(ctx, "SELECT readonly FROM system.settings WHERE name = ?", name)
+	if err := row.Scan(&readonly); err != nil {
+		return fmt.Errorf("неизвестный параметр %s", name)
+	}
+	if readonly != 0 {
+		return fmt.Errorf("параметр %s нельзя изменить", name)
+	}
+
+	username := d.dbConn.Username
+	if username == "" {
+		username = "default"
+	}
+
+	quotedValue := "'" + strings.NewReplacer("\\", "\\\\", "'", "\\'").Replace(value) + "'"
+	query := fmt.Sprintf("ALTER USER %s SETTINGS %s = %s", quoteClickHouseName(username), name, quotedValue)
+	if err := d.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка изменения параметра: %w", err)
+	}
+
+	return nil
+}
+
+// clickHouseSkipIndexType - тип skip-индекса с необязательными параметрами:
+// minmax, set(100), bloom_filter(0.01), tokenbf_v1(256, 2, 0)...
+var clickHouseSkipIndexType = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\([0-9.,\s]*\))?$`)
+
+// splitTable разделяет database.table; без базы используется база подключения
+func (d *ClickHouseDriver) splitTable(table string) (string, string) {
+	if idx := strings.Index(table, "."); idx != -1 {
+		return table[:idx], table[idx+1:]
+	}
+	database := d.dbConn.Database
+	if database == "" {
+		database = "default"
+	}
+	return database, table
+}
+
+// ListIndexes возвращает индексы пропуска данных (skip indexes). Первичный
+// ключ и ключ сортировки MergeTree задаются при создании таблицы и сюда
+// не входят
+func (d *ClickHouseDriver) ListIndexes(ctx context.Context, table string) ([]models.IndexInfo, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	database, name := d.splitTable(table)
+	rows, err := d.conn.Query(ctx, `
+		SELECT name, type_full, expr, granularity
+		FROM system.data_skipping_indices
+		WHERE database = ? AND table = ?
+		ORDER BY name
+	`, database, name)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка индексов: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make([]models.IndexInfo, 0)
+	for rows.Next() {
+		var indexName, indexType, expr string
+		var granularity uint64
+		if err := rows.Scan(&indexName, &indexType, &expr, &granularity); err != nil {
+			return nil, fmt.Errorf("ошибка чтения индекса: %w", err)
+		}
+		indexes = append(indexes, models.IndexInfo{
+			Name:        indexName,
+			Table:       table,
+			Columns:     []string{expr},
+			Type:        indexType,
+			Granularity: int64(granularity),
+			Definition:  fmt.Sprintf("INDEX %s %s TYPE %s GRANULARITY %d", indexName, expr, indexType, granularity),
+		})
+	}
+
+	return indexes, rows.Err()
+}
+
+// CreateIndex добавляет skip-индекс (ALTER TABLE ... ADD INDEX). Индекс
+// строится для новых данных; существующие части индексируются командой
+// ALTER TABLE ... MATERIALIZE INDEX
+func (d *ClickHouseDriver) CreateIndex(ctx context.Context, req models.CreateIndexRequest) error {
+	if d.conn == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if req.Unique {
+		return fmt.Errorf("ClickHouse не поддерживает уникальные индексы: доступны только индексы пропуска данных (minmax, set, bloom_filter...)")
+	}
+
+	indexType := req.Type
+	if indexType == "" {
+		indexType = "minmax"
+	}
+	if !clickHouseSkipIndexType.MatchString(indexType) {
+		return fmt.Errorf("неверный тип индекса %q", req.Type)
+	}
+
+	granularity := req.Granularity
+	if granularity <= 0 {
+		granularity = 1
+	}
+
+	columns := make([]string, len(req.Columns))
+	for i, column := range req.Columns {
+		columns[i] = quoteClickHouseIdentifier(column)
+	}
+	expr := columns[0]
+	if len(columns) > 1 {
+		expr = "(" + strings.Join(columns, ", ") + ")"
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD INDEX %s %s TYPE %s GRANULARITY %d",
+		quoteClickHouseIdentifier(req.Table), quoteClickHouseIdentifier(req.Name), expr, indexType, granularity)
+	if err := d.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка создания индекса (skip-индексы поддерживаются только движками семейства MergeTree): %w", err)
+	}
+	return nil
+}
+
+func (d *ClickHouseDriver) DropIndex(ctx context.Context, table, name string) error {
+	if d.conn == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s DROP INDEX %s", quoteClickHouseIdentifier(table), quoteClickHouseIdentifier(name))
+	if err := d.conn.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка удаления индекса: %w", err)
+	}
+	return nil
+}
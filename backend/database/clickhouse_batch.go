@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// InsertBatch вставляет строки одним пакетом через PrepareBatch: данные
+// уходят на сервер в нативном формате одним блоком, что намного быстрее
+// построчных INSERT. Столбцы - объединение ключей строк; значения JSON
+// приводятся к типам столбцов из DESCRIBE. Отсутствующий в строке столбец
+// получает NULL или нулевое значение типа (не DEFAULT столбца)
+func (d *ClickHouseDriver) InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error) {
+	if d.conn == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+	if len(rows) == 0 {
+		return &models.WriteResult{}, nil
+	}
+
+	described, err := d.DescribeTable(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]string, len(described))
+	for _, col := range described {
+		types[col.Name] = col.Type
+	}
+
+	columnNames := newColumnSet()
+	for _, row := range rows {
+		columnNames.addKeys(row)
+	}
+	columns := columnNames.list()
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		if _, ok := types[column]; !ok {
+			return nil, fmt.Errorf("столбца %s нет в таблице %s", column, table)
+		}
+		quoted[i] = quoteClickHouseIdentifier(column)
+	}
+
+	batch, err := d.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s (%s)",
+		quoteClickHouseIdentifier(table), strings.Join(quoted, ", ")))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подготовки пакета: %w", err)
+	}
+	defer batch.Abort()
+
+	for i, row := range rows {
+		values := make([]interface{}, len(columns))
+		for j, column := range columns {
+			value, err := clickhouseBatchValue(types[column], row[column])
+			if err != nil {
+				return nil, fmt.Errorf("строка %d, столбец %s: %w", i+1, column, err)
+			}
+			values[j] = value
+		}
+		if err := batch.Append(values...); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", i+1, err)
+		}
+	}
+
+	if err := batch.Send(); err != nil {
+		return nil, fmt.Errorf("ошибка отправки пакета: %w", err)
+	}
+	return &models.WriteResult{RowsAffected: int64(len(rows))}, nil
+}
+
+// clickhouseDateLayouts - форматы дат в строках: RFC3339 и формат ClickHouse
+var clickhouseDateLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+// clickhouseBatchValue приводит значение из JSON (float64, string, bool) к
+// типу Go, который принимает столбец clickhouse-go: драйвер не преобразует
+// float64 в Int64 или строку в DateTime сам. Составные типы (Array, Map,
+// Tuple) передаются как есть
+func clickhouseBatchValue(chType string, value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	chType = unwrapClickHouseType(chType)
+
+	intBits, isInt := clickhouseIntBits(chType, "Int")
+	uintBits, isUint := clickhouseIntBits(chType, "UInt")
+
+	switch {
+	case isInt:
+		n, err := batchInt(value, intBits)
+		if err != nil {
+			return nil, err
+		}
+		switch intBits {
+		case 8:
+			return int8(n), nil
+		case 16:
+			return int16(n), nil
+		case 32:
+			return int32(n), nil
+		case 64:
+			return n, nil
+		}
+	case isUint:
+		n, err := batchUint(value, uintBits)
+		if err != nil {
+			return nil, err
+		}
+		switch uintBits {
+		case 8:
+			return uint8(n), nil
+		case 16:
+			return uint16(n), nil
+		case 32:
+			return uint32(n), nil
+		case 64:
+			return n, nil
+		}
+	case chType == "Float32" || chType == "Float64":
+		f, err := batchFloat(value)
+		if err != nil {
+			return nil, err
+		}
+		if chType == "Float32" {
+			return float32(f), nil
+		}
+		return f, nil
+	case chType == "Bool":
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("ожидается true или false, получено %v", value)
+		}
+		return b, nil
+	case strings.HasPrefix(chType, "Decimal"):
+		switch v := value.(type) {
+		case float64:
+			return decimal.NewFromFloat(v), nil
+		case string:
+			return decimal.NewFromString(v)
+		}
+		return nil, fmt.Errorf("ожидается число, получено %v", value)
+	case strings.HasPrefix(chType, "Date"):
+		return batchTime(value)
+	case chType == "String" || strings.HasPrefix(chType, "FixedString") ||
+		chType == "UUID" || strings.HasPrefix(chType, "Enum"):
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		if f, ok := value.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+		return fmt.Sprint(value), nil
+	}
+	return value, nil
+}
+
+// unwrapClickHouseType снимает Nullable(...) и LowCardinality(...)
+func unwrapClickHouseType(chType string) string {
+	for _, wrapper := range []string{"Nullable(", "LowCardinality("} {
+		for strings.HasPrefix(chType, wrapper) && strings.HasSuffix(chType, ")") {
+			chType = chType[len(wrapper) : len(chType)-1]
+		}
+	}
+	return chType
+}
+
+// clickhouseIntBits возвращает разрядность для Int8...Int256 (UInt...)
+func clickhouseIntBits(chType, prefix string) (int, bool) {
+	if !strings.HasPrefix(chType, prefix) {
+		return 0, false
+	}
+	bits, err := strconv.Atoi(strings.TrimPrefix(chType, prefix))
+	return bits, err == nil
+}
+
+func batchInt(value interface{}, bits int) (int64, error) {
+	if bits != 8 && bits != 16 && bits != 32 && bits != 64 {
+		return 0, fmt.Errorf("тип Int%d не поддерживается при пакетной вставке", bits)
+	}
+	var n int64
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) {
+			return 0, fmt.Errorf("ожидается целое число, получено %v", v)
+		}
+		n = int64(v)
+	case string:
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("ожидается целое число, получено %q", v)
+		}
+		n = parsed
+	default:
+		return 0, fmt.Errorf("ожидается целое число, получено %v", value)
+	}
+	if bits < 64 && (n < -(1<<(bits-1)) || n > 1<<(bits-1)-1) {
+		return 0, fmt.Errorf("значение %d не помещается в Int%d", n, bits)
+	}
+	return n, nil
+}
+
+func batchUint(value interface{}, bits int) (uint64, error) {
+	if bits != 8 && bits != 16 && bits != 32 && bits != 64 {
+		return 0, fmt.Errorf("тип UInt%d не поддерживается при пакетной вставке", bits)
+	}
+	var n uint64
+	switch v := value.(type) {
+	case float64:
+		if v != math.Trunc(v) || v < 0 {
+			return 0, fmt.Errorf("ожидается неотрицательное целое число, получено %v", v)
+		}
+		n = uint64(v)
+	case string:
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("ожидается неотрицательное целое число, получено %q", v)
+		}
+		n = parsed
+	default:
+		return 0, fmt.Errorf("ожидается неотрицательное целое число, получено %v", value)
+	}
+	if bits < 64 && n > 1<<bits-1 {
+		return 0, fmt.Errorf("значение %d не помещается в UInt%d", n, bits)
+	}
+	return n, nil
+}
+
+func batchFloat(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("ожидается число, получено %q", v)
+		}
+		return f, nil
+	}
+	return 0, fmt.Errorf("ожидается число, получено %v", value)
+}
+
+// batchTime разбирает дату: строка RFC3339, "2006-01-02 15:04:05" или
+// "2006-01-02" (в UTC) либо число - Unix-время в секундах
+func batchTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	case string:
+		for _, layout := range clickhouseDateLayouts {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("ожидается дата RFC3339 или 2006-01-02 15:04:05, получено %q", v)
+	}
+	return time.Time{}, fmt.Errorf("ожидается дата, получено %v", value)
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestClickHouseOptionsEscapesCredentials(t *testing.T) {
+	conn := models.Connection{
+		Host:     "ch.example.com",
+		Port:     "9000",
+		Database: "analytics",
+		Username: "reporter",
+		Password: "p@ss:w/rd?x",
+	}
+
+	options, err := clickHouseOptions(conn)
+	if err != nil {
+		t.Fatalf("clickHouseOptions() error: %v", err)
+	}
+
+	if options.Auth.Username != conn.Username || options.Auth.Password != conn.Password {
+		t.Fatalf("auth = %+v, want %s/%s", options.Auth, conn.Username, conn.Password)
+	}
+	if options.Auth.Database != conn.Database {
+		t.Fatalf("database = %q, want %q", options.Auth.Database, conn.Database)
+	}
+	if len(options.Addr) != 1 || options.Addr[0] != "ch.example.com:9000" {
+		t.Fatalf("addr = %v", options.Addr)
+	}
+	if options.TLS != nil {
+		t.Fatalf("TLS не должен быть включен без SSL")
+	}
+}
+
+func TestClickHouseOptionsSSL(t *testing.T) {
+	conn := models.Connection{Host: "localhost", Port: "9440", Database: "default", Username: "default", Password: "x", SSL: true}
+
+	options, err := clickHouseOptions(conn)
+	if err != nil {
+		t.Fatalf("clickHouseOptions() error: %v", err)
+	}
+	if options.TLS == nil {
+		t.Fatalf("TLS должен быть включен при SSL")
+	}
+}
+
+func TestClickHouseBatchValue(t *testing.T) {
+	date := time.Date(2024, 1, 12, 12, 4, 5, 0, time.UTC)
+	tests := []struct {
+		chType string
+		value  interface{}
+		want   interface{}
+	}{
+		{"Int32", float64(42), int32(42)},
+		{"Nullable(Int64)", "9007199254740993", int64(9007199254740993)},
+		{"UInt8", float64(255), uint8(255)},
+		{"Float32", float64(1.5), float32(1.5)},
+		{"Bool", true, true},
+		{"LowCardinality(String)", float64(7), "7"},
+		{"DateTime", "2024-01-12T12:04:05Z", date},
+		{"DateTime64(3, 'UTC')", "2024-01-12 12:04:05", date},
+		{"Date", float64(date.Unix()), date},
+		{"Nullable(String)", nil, nil},
+		{"Array(String)", []interface{}{"a"}, []interface{}{"a"}},
+		{"IntervalDay", float64(1), float64(1)},
+	}
+	for _, tt := range tests {
+		got, err := clickhouseBatchValue(tt.chType, tt.value)
+		if err != nil {
+			t.Errorf("clickhouseBatchValue(%s, %v) error: %v", tt.chType, tt.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("clickhouseBatchValue(%s, %v) = %#v, want %#v", tt.chType, tt.value, got, tt.want)
+		}
+	}
+
+	if got, err := clickhouseBatchValue("Decimal(10, 2)", "12.34"); err != nil || got.(decimal.Decimal).String() != "12.34" {
+		t.Errorf("Decimal = %v, %v", got, err)
+	}
+
+	for _, invalid := range []struct {
+		chType string
+		value  interface{}
+	}{
+		{"Int8", float64(200)},
+		{"Int32", float64(1.5)},
+		{"UInt16", float64(-1)},
+		{"Bool", "yes"},
+		{"DateTime", "12/01/2024"},
+		{"Int128", float64(1)},
+	} {
+		if _, err := clickhouseBatchValue(invalid.chType, invalid.value); err == nil {
+			t.Errorf("clickhouseBatchValue(%s, %v): expected error", invalid.chType, invalid.value)
+		}
+	}
+}
+
+func TestQuoteClickHouseIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"events", "`events`"},
+		{"analytics.events", "`analytics`.`events`"},
+		{"a`b", "`a``b`"},
+		{`a\`, "`a\\\\`"},
+		{"x\\` FROM system.users --", "`x\\\\`` FROM system`.`users --`"},
+	}
+
+	for _, tt := range tests {
+		if got := quoteClickHouseIdentifier(tt.name); got != tt.want {
+			t.Errorf("quoteClickHouseIdentifier(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
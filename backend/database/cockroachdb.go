@@ -3,6 +3,11 @@ package database
 import (
 	"context"
 	"database-manager/models"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type CockroachDBDriver struct {
@@ -19,3 +24,107 @@ func (d *CockroachDBDriver) Connect(ctx context.Context, conn models.Connection)
 	return d.PostgreSQLDriver.Connect(ctx, conn)
 }
 
+// ListTables использует SHOW TABLES: каталоги pg_class/information_schema
+// в CockroachDB эмулируются, и размеры из pg_total_relation_size там не
+// имеют смысла, а оценку числа строк CRDB отдает сама
+func (d *CockroachDBDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var databaseName string
+	if err := d.pool.QueryRow(ctx, "SELECT current_database()").Scan(&databaseName); err != nil {
+		return nil, fmt.Errorf("ошибка получения текущей базы: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		SELECT schema_name, table_name, estimated_row_count
+		FROM [SHOW TABLES]
+		WHERE type = 'table'
+		ORDER BY schema_name, table_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
+	}
+	defer rows.Close()
+
+	schema := d.schema()
+	tables := make([]models.TableInfo, 0)
+	for rows.Next() {
+		var schemaName, tableName string
+		var rowCount sql.NullInt64
+		if err := rows.Scan(&schemaName, &tableName, &rowCount); err != nil {
+			continue
+		}
+
+		table := models.TableInfo{Name: tableName, Database: databaseName}
+		if schema == allSchemas {
+			table.Name = schemaName + "." + tableName
+		} else if schemaName != schema {
+			continue
+		}
+		if rowCount.Valid {
+			table.Rows = rowCount.Int64
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// ClusterInfo возвращает идентификатор, версию кластера и состояние узлов
+// из crdb_internal.gossip_nodes
+func (d *CockroachDBDriver) ClusterInfo(ctx context.Context) (*models.CockroachClusterInfo, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	info := &models.CockroachClusterInfo{}
+	err := d.pool.QueryRow(ctx, `
+		SELECT crdb_internal.cluster_id()::STRING, crdb_internal.cluster_name(), version()
+	`).Scan(&info.ClusterID, &info.ClusterName, &info.Version)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения информации о кластере: %w", err)
+	}
+
+	rows, err := d.pool.Query(ctx, `
+		SELECT node_id, address, locality, build_tag, started_at, is_live, ranges, leases
+		FROM crdb_internal.gossip_nodes
+		ORDER BY node_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка узлов: %w", err)
+	}
+	defer rows.Close()
+
+	info.Nodes = make([]models.CockroachNode, 0)
+	for rows.Next() {
+		var node models.CockroachNode
+		if err := rows.Scan(&node.NodeID, &node.Address, &node.Locality, &node.BuildTag,
+			&node.StartedAt, &node.IsLive, &node.Ranges, &node.Leases); err != nil {
+			return nil, fmt.Errorf("ошибка чтения узла: %w", err)
+		}
+		info.Nodes = append(info.Nodes, node)
+	}
+
+	return info, rows.Err()
+}
+
+// TableRanges выполняет SHOW RANGES FROM TABLE. Набор столбцов зависит от
+// версии CockroachDB, поэтому результат отдается как есть
+func (d *CockroachDBDriver) TableRanges(ctx context.Context, table string) (*models.QueryResponse, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	ident := pgx.Identifier(strings.Split(table, ".")).Sanitize()
+	result, err := d.query(ctx, "SHOW RANGES FROM TABLE "+ident)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("ошибка получения диапазонов таблицы %s: %s", table, result.Error)
+	}
+	return result, nil
+}
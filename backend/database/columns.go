@@ -0,0 +1,50 @@
+package database
+
+import "sort"
+
+// columnSet собирает имена столбцов в порядке первого появления,
+// чтобы порядок столбцов в ответе не менялся от запроса к запросу
+type columnSet struct {
+	names []string
+	seen  map[string]bool
+}
+
+func newColumnSet(initial ...string) *columnSet {
+	s := &columnSet{seen: make(map[string]bool)}
+	for _, name := range initial {
+		s.add(name)
+	}
+	return s
+}
+
+func (s *columnSet) add(name string) {
+	if s.seen[name] {
+		return
+	}
+	s.seen[name] = true
+	s.names = append(s.names, name)
+}
+
+// addKeys добавляет ключи документа. Порядок обхода map случаен,
+// поэтому новые ключи одного документа добавляются по алфавиту.
+func (s *columnSet) addKeys(doc map[string]interface{}) {
+	for _, key := range sortedKeys(doc) {
+		s.add(key)
+	}
+}
+
+func (s *columnSet) list() []string {
+	if s.names == nil {
+		return []string{}
+	}
+	return s.names
+}
+
+func sortedKeys(doc map[string]interface{}) []string {
+	keys := make([]string, 0, len(doc))
+	for key := range doc {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestColumnSetKeepsFirstSeenOrder(t *testing.T) {
+	set := newColumnSet("_id")
+	set.addKeys(map[string]interface{}{"name": 1, "age": 2, "_id": 3})
+	set.addKeys(map[string]interface{}{"city": 1, "name": 2, "zip": 3, "email": 4})
+
+	want := []string{"_id", "age", "name", "city", "email", "zip"}
+	if got := set.list(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("list() = %v, want %v", got, want)
+	}
+}
+
+func TestColumnSetEmpty(t *testing.T) {
+	if got := newColumnSet().list(); got == nil || len(got) != 0 {
+		t.Fatalf("list() = %#v, want empty non-nil slice", got)
+	}
+}
+
+func TestDocumentsToResponseColumnOrderIsDeterministic(t *testing.T) {
+	results := []bson.M{
+		{"_id": 1, "title": "a", "author": "b", "year": 2000, "pages": 10},
+		{"_id": 2, "title": "c", "isbn": "d", "genre": "e"},
+	}
+
+	want := []string{"_id", "author", "pages", "title", "year", "genre", "isbn"}
+	for i := 0; i < 50; i++ {
+		got := documentsToResponse(results, time.Now()).Columns
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("iteration %d: columns = %v, want %v", i, got, want)
+		}
+	}
+}
@@ -18,8 +18,13 @@ type CouchbaseDriver struct {
 }
 
 func NewCouchbaseDriver() *CouchbaseDriver {
-	return &CouchbaseDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &CouchbaseDriver{}
+}
+
+func (d *CouchbaseDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "n1ql",
+		SupportsCreateDatabase: true,
 	}
 }
 
@@ -31,6 +36,12 @@ func (d *CouchbaseDriver) Connect(ctx context.Context, conn models.Connection) e
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Couchbase: %w", err)
 	}
@@ -63,7 +74,7 @@ func (d *CouchbaseDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -121,20 +132,13 @@ func (d *CouchbaseDriver) ExecuteQuery(ctx context.Context, query string) (*mode
 		return &models.QueryResponse{Error: err.Error()}, nil
 	}
 
-	columns := []string{}
+	columns := newColumnSet()
 	rowsData := make([]map[string]interface{}, 0)
 
 	if results, ok := result["results"].([]interface{}); ok {
-		if len(results) > 0 {
-			if firstResult, ok := results[0].(map[string]interface{}); ok {
-				for key := range firstResult {
-					columns = append(columns, key)
-				}
-			}
-		}
-
 		for _, res := range results {
 			if resMap, ok := res.(map[string]interface{}); ok {
+				columns.addKeys(resMap)
 				rowsData = append(rowsData, resMap)
 			}
 		}
@@ -143,7 +147,7 @@ func (d *CouchbaseDriver) ExecuteQuery(ctx context.Context, query string) (*mode
 	executionTime := time.Since(startTime).Milliseconds()
 
 	return &models.QueryResponse{
-		Columns:       columns,
+		Columns:       columns.list(),
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
@@ -213,7 +217,7 @@ func (d *CouchbaseDriver) ListDatabases(ctx context.Context) ([]models.DatabaseI
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -304,7 +308,7 @@ func (d *CouchbaseDriver) ListTables(ctx context.Context) ([]models.TableInfo, e
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
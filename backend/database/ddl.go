@@ -0,0 +1,42 @@
+package database
+
+import (
+	"database-manager/models"
+	"fmt"
+)
+
+// tableChanges - команды UpdateTable в порядке выполнения: переименование
+// таблицы (если оно есть) и по одной команде на операцию со столбцом
+type tableChanges struct {
+	rename  string
+	columns []string
+}
+
+// planTableChanges строит команды UpdateTable до выполнения первой из них,
+// поэтому ошибка в описании столбца не оставляет таблицу переименованной.
+// Столбцы изменяются уже в переименованной таблице
+func planTableChanges(oldName, newName string, columns []models.TableColumn, renameFormat string, alter func(table string, col models.TableColumn) (string, error)) (tableChanges, error) {
+	var changes tableChanges
+	table := oldName
+	if newName != "" && newName != oldName {
+		changes.rename = fmt.Sprintf(renameFormat, oldName, newName)
+		table = newName
+	}
+
+	for _, col := range columns {
+		query, err := alter(table, col)
+		if err != nil {
+			return tableChanges{}, err
+		}
+		changes.columns = append(changes.columns, query)
+	}
+	return changes, nil
+}
+
+func (c tableChanges) statements() []string {
+	statements := make([]string, 0, len(c.columns)+1)
+	if c.rename != "" {
+		statements = append(statements, c.rename)
+	}
+	return append(statements, c.columns...)
+}
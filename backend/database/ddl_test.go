@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestPlanTableChanges(t *testing.T) {
+	columns := []models.TableColumn{
+		{Name: "age", Type: "INTEGER", Nullable: true},
+		{Name: "nick", NewName: "login", Operation: models.ColumnRename},
+	}
+
+	changes, err := planTableChanges("users", "people", columns, "ALTER TABLE %s RENAME TO %s", sqliteAlterColumn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ALTER TABLE users RENAME TO people",
+		"ALTER TABLE people ADD COLUMN age INTEGER",
+		"ALTER TABLE people RENAME COLUMN nick TO login",
+	}
+	if got := changes.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements() = %q, want %q", got, want)
+	}
+
+	changes, err = planTableChanges("users", "users", columns[:1], "ALTER TABLE %s RENAME TO %s", sqliteAlterColumn)
+	if err != nil || changes.rename != "" || len(changes.statements()) != 1 {
+		t.Errorf("same name: got %+v, %v", changes, err)
+	}
+}
+
+func TestPlanTableChangesInvalidColumn(t *testing.T) {
+	columns := []models.TableColumn{
+		{Name: "age", Type: "INTEGER"},
+		{Name: "nick", Operation: models.ColumnRename},
+	}
+	if _, err := planTableChanges("users", "people", columns, "ALTER TABLE %s RENAME TO %s", sqliteAlterColumn); err == nil {
+		t.Error("expected error: rename without newName")
+	}
+}
+
+func TestSQLCreateTable(t *testing.T) {
+	columns := []models.TableColumn{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "email", Type: "TEXT", Unique: true, Nullable: true},
+	}
+	got, err := sqlCreateTable("users", columns, models.TableConstraints{
+		PrimaryKey:  []string{"id"},
+		Constraints: []string{"id > 0"},
+	})
+	want := "CREATE TABLE users (\n  id INTEGER NOT NULL,\n  email TEXT UNIQUE,\n  PRIMARY KEY (id),\n  CHECK (id > 0)\n)"
+	if err != nil || got != want {
+		t.Errorf("sqlCreateTable() = %q, %v; want %q", got, err, want)
+	}
+
+	if _, err := sqlCreateTable("users", nil, models.TableConstraints{}); err == nil {
+		t.Error("expected error for table without columns")
+	}
+}
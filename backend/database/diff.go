@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// DiffResults сравнивает два результата построчно по столбцу key: строки
+// только справа - добавленные, только слева - удаленные, с одинаковым ключом
+// и разными значениями - измененные. Сравниваются только общие столбцы,
+// столбцы одной из сторон возвращаются отдельно. Значения сравниваются по
+// JSON-представлению: 1 из одной БД и 1.0 из другой считаются равными
+func DiffResults(left, right *models.QueryResponse, key string) (*models.QueryDiffResponse, error) {
+	leftColumns := resultColumns(left)
+	rightColumns := resultColumns(right)
+	if !containsString(leftColumns, key) {
+		return nil, fmt.Errorf("ключевого столбца %q нет в левом результате", key)
+	}
+	if !containsString(rightColumns, key) {
+		return nil, fmt.Errorf("ключевого столбца %q нет в правом результате", key)
+	}
+
+	diff := &models.QueryDiffResponse{
+		Key:           key,
+		Columns:       []string{},
+		Added:         []map[string]interface{}{},
+		Removed:       []map[string]interface{}{},
+		Changed:       []models.RowChange{},
+		LeftRowCount:  len(left.Rows),
+		RightRowCount: len(right.Rows),
+		Truncated:     left.Truncated || right.Truncated,
+	}
+	for _, column := range leftColumns {
+		if containsString(rightColumns, column) {
+			diff.Columns = append(diff.Columns, column)
+		} else {
+			diff.LeftOnlyColumns = append(diff.LeftOnlyColumns, column)
+		}
+	}
+	for _, column := range rightColumns {
+		if !containsString(leftColumns, column) {
+			diff.RightOnlyColumns = append(diff.RightOnlyColumns, column)
+		}
+	}
+
+	rightByKey, err := indexRows(right.Rows, key, "правом")
+	if err != nil {
+		return nil, err
+	}
+	leftByKey, err := indexRows(left.Rows, key, "левом")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, leftRow := range left.Rows {
+		rightRow, ok := rightByKey[diffValue(leftRow[key])]
+		if !ok {
+			diff.Removed = append(diff.Removed, leftRow)
+			continue
+		}
+		var changed []string
+		for _, column := range diff.Columns {
+			if diffValue(leftRow[column]) != diffValue(rightRow[column]) {
+				changed = append(changed, column)
+			}
+		}
+		if len(changed) == 0 {
+			diff.UnchangedCount++
+			continue
+		}
+		diff.Changed = append(diff.Changed, models.RowChange{
+			Key:     leftRow[key],
+			Columns: changed,
+			Left:    leftRow,
+			Right:   rightRow,
+		})
+	}
+	for _, rightRow := range right.Rows {
+		if _, ok := leftByKey[diffValue(rightRow[key])]; !ok {
+			diff.Added = append(diff.Added, rightRow)
+		}
+	}
+
+	return diff, nil
+}
+
+// indexRows строит индекс строк по ключу; повторяющийся или пустой ключ -
+// ошибка, иначе строки нельзя сопоставить однозначно
+func indexRows(rows []map[string]interface{}, key, side string) (map[string]map[string]interface{}, error) {
+	index := make(map[string]map[string]interface{}, len(rows))
+	for _, row := range rows {
+		value, ok := row[key]
+		if !ok || value == nil {
+			return nil, fmt.Errorf("в %s результате есть строка без значения ключа %q", side, key)
+		}
+		k := diffValue(value)
+		if _, exists := index[k]; exists {
+			return nil, fmt.Errorf("значение ключа %q = %s повторяется в %s результате", key, k, side)
+		}
+		index[k] = row
+	}
+	return index, nil
+}
+
+// diffValue - JSON-представление значения для сравнения
+func diffValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// resultColumns - столбцы результата; у документных БД без списка столбцов
+// они берутся из строк и сортируются, чтобы порядок не зависел от map
+func resultColumns(resp *models.QueryResponse) []string {
+	if len(resp.Columns) > 0 {
+		return resp.Columns
+	}
+	columns := rowKeys(resp.Rows)
+	sort.Strings(columns)
+	return columns
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	left := &models.QueryResponse{
+		Columns: []string{"id", "name", "price", "legacy"},
+		Rows: []map[string]interface{}{
+			{"id": int64(1), "name": "a", "price": 10.0, "legacy": true},
+			{"id": int64(2), "name": "b", "price": 20.0, "legacy": false},
+			{"id": int64(3), "name": "c", "price": 30.0, "legacy": false},
+		},
+	}
+	// Числа из другой БД приходят другим типом: 1 и 1.0 - один ключ
+	right := &models.QueryResponse{
+		Columns: []string{"id", "name", "price", "discount"},
+		Rows: []map[string]interface{}{
+			{"id": float64(1), "name": "a", "price": int64(10), "discount": 0},
+			{"id": float64(2), "name": "B", "price": 20.0, "discount": 5},
+			{"id": float64(4), "name": "d", "price": 40.0, "discount": 0},
+		},
+	}
+
+	diff, err := DiffResults(left, right, "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(diff.Columns, []string{"id", "name", "price"}) {
+		t.Errorf("Columns = %v", diff.Columns)
+	}
+	if !reflect.DeepEqual(diff.LeftOnlyColumns, []string{"legacy"}) || !reflect.DeepEqual(diff.RightOnlyColumns, []string{"discount"}) {
+		t.Errorf("LeftOnlyColumns = %v, RightOnlyColumns = %v", diff.LeftOnlyColumns, diff.RightOnlyColumns)
+	}
+	if len(diff.Added) != 1 || diff.Added[0]["name"] != "d" {
+		t.Errorf("Added = %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0]["name"] != "c" {
+		t.Errorf("Removed = %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Key != int64(2) || !reflect.DeepEqual(diff.Changed[0].Columns, []string{"name"}) {
+		t.Errorf("Changed = %+v", diff.Changed)
+	}
+	if diff.UnchangedCount != 1 || diff.LeftRowCount != 3 || diff.RightRowCount != 3 {
+		t.Errorf("UnchangedCount = %d, LeftRowCount = %d, RightRowCount = %d", diff.UnchangedCount, diff.LeftRowCount, diff.RightRowCount)
+	}
+}
+
+func TestDiffResultsErrors(t *testing.T) {
+	rows := &models.QueryResponse{Rows: []map[string]interface{}{{"id": 1}, {"id": 2}}}
+	duplicated := &models.QueryResponse{Rows: []map[string]interface{}{{"id": 1}, {"id": 1}}}
+	withoutKey := &models.QueryResponse{Rows: []map[string]interface{}{{"id": 1}, {"name": "x"}}}
+
+	if _, err := DiffResults(rows, rows, "missing"); err == nil {
+		t.Error("expected error for missing key column")
+	}
+	if _, err := DiffResults(rows, duplicated, "id"); err == nil {
+		t.Error("expected error for duplicated key")
+	}
+	if _, err := DiffResults(withoutKey, rows, "id"); err == nil {
+		t.Error("expected error for row without key")
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database-manager/models"
+	"errors"
 )
 
 type DatabaseDriver interface {
@@ -23,6 +24,239 @@ type DatabaseDriver interface {
 	UpdateUser(ctx context.Context, username, password string, permissions []string) error
 	DeleteUser(ctx context.Context, username string) error
 	Ping(ctx context.Context) error
+	// Capabilities описывает, какие из операций выше драйвер действительно
+	// выполняет; остальные возвращают ошибку при вызове
+	Capabilities() models.DriverCapabilities
+}
+
+// DataBrowser реализуется драйверами, которые умеют отдавать строки
+// таблицы (коллекции, индекса) без написания запроса вручную
+type DataBrowser interface {
+	BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error)
+}
+
+// DataWriter реализуется драйверами, которые умеют добавлять, изменять
+// и удалять строки (документы) без написания запроса вручную
+type DataWriter interface {
+	InsertRows(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error)
+	UpdateRows(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error)
+	DeleteRow(ctx context.Context, table, id string) (*models.WriteResult, error)
+}
+
+// BatchInserter реализуется драйверами с пакетной вставкой (ClickHouse):
+// все строки уходят на сервер одним пакетом, а не отдельными INSERT
+type BatchInserter interface {
+	InsertBatch(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error)
+}
+
+// PointWriter реализуется БД временных рядов (InfluxDB): точки из rows или
+// line protocol из lines записываются в базу или bucket запроса
+type PointWriter interface {
+	WritePoints(ctx context.Context, req models.WriteRowsRequest) (*models.WriteResult, error)
+}
+
+// DatabaseSwitcher реализуется драйверами, у которых база выбирается
+// при подключении. UseDatabase меняет драйвер без блокировок, поэтому
+// вызывается только через ConnectionManager.UseDatabase - на новом драйвере,
+// которым еще не пользуются запросы
+type DatabaseSwitcher interface {
+	UseDatabase(ctx context.Context, name string) error
+}
+
+// QuerySession - выделенное соединение, на котором состояние сохраняется
+// между запросами (SET, временные таблицы, SELECT db, MULTI/EXEC)
+type QuerySession interface {
+	ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error)
+	Close() error
+}
+
+// SessionOpener реализуется драйверами, у которых запросы по умолчанию
+// идут через пул соединений и потому не сохраняют состояние
+type SessionOpener interface {
+	OpenSession(ctx context.Context) (QuerySession, error)
+}
+
+// OpenSession открывает сессию, если драйвер их поддерживает; иначе запросы
+// выполняются через обычный ExecuteQuery без сохранения состояния
+func OpenSession(ctx context.Context, driver DatabaseDriver) (QuerySession, error) {
+	if opener, ok := driver.(SessionOpener); ok {
+		return opener.OpenSession(ctx)
+	}
+	return statelessSession{driver}, nil
+}
+
+type statelessSession struct {
+	DatabaseDriver
+}
+
+func (statelessSession) Close() error {
+	return nil
+}
+
+// ParameterizedExecutor реализуется драйверами с привязкой параметров:
+// значения $1, $2... передаются отдельно от текста запроса
+type ParameterizedExecutor interface {
+	ExecuteQueryArgs(ctx context.Context, query string, args []interface{}) (*models.QueryResponse, error)
+}
+
+// PagedExecutor реализуется драйверами, которые отдают результат
+// постранично: NextPageState ответа передается в следующий запрос
+type PagedExecutor interface {
+	ExecuteQueryPage(ctx context.Context, query string, pageSize int, pageState string) (*models.QueryResponse, error)
+}
+
+// GraphExecutor реализуется графовыми драйверами (Neo4j): кроме строк
+// ответ содержит Graph - узлы и связи результата
+type GraphExecutor interface {
+	ExecuteQueryGraph(ctx context.Context, query string) (*models.QueryResponse, error)
+}
+
+// SessionVarsExecutor реализуется драйверами, которые умеют выполнить
+// запрос с переменными сессии (PostgreSQL: SET LOCAL в транзакции вокруг
+// запроса). ValidateSessionVars проверяет имена по списку разрешенных
+type SessionVarsExecutor interface {
+	ValidateSessionVars(vars map[string]string) error
+	ExecuteQueryWithSessionVars(ctx context.Context, query string, args []interface{}, vars map[string]string) (*models.QueryResponse, error)
+}
+
+// TransactionExecutor реализуется драйверами, которые умеют выполнить
+// пакет команд атомарно (Redis MULTI/EXEC)
+type TransactionExecutor interface {
+	ExecuteTransaction(ctx context.Context, commands []string) (*models.QueryResponse, error)
+}
+
+// TableDescriber реализуется драйверами, которые умеют возвращать
+// столбцы (поля) таблицы
+type TableDescriber interface {
+	DescribeTable(ctx context.Context, table string) ([]models.TableColumn, error)
+}
+
+// ExactRowCounter реализуется драйверами, у которых ListTables возвращает
+// оценку числа строк: CountRows считает точное значение (медленно)
+type ExactRowCounter interface {
+	CountRows(ctx context.Context, table string) (int64, error)
+}
+
+// TableSearcher реализуется драйверами, которые умеют искать строки,
+// содержащие текст, в указанных (или во всех текстовых) столбцах
+type TableSearcher interface {
+	SearchTable(ctx context.Context, table, text string, columns []string, limit, offset int) (*models.QueryResponse, error)
+}
+
+// NotificationListener реализуется драйверами с поддержкой LISTEN/NOTIFY:
+// Listen блокируется до отмены ctx и вызывает notify на каждое уведомление
+type NotificationListener interface {
+	Listen(ctx context.Context, channel string, notify func(models.Notification) error) error
+}
+
+// UserSearcher реализуется драйверами, которые ищут пользователей по имени
+// и отдают страницу списка запросом к серверу; для остальных ListUsers
+// фильтруется в памяти (FilterUsers)
+type UserSearcher interface {
+	SearchUsers(ctx context.Context, filter models.UserFilter) (*models.UserList, error)
+}
+
+// RoleLister реализуется драйверами, которые умеют перечислить значения
+// для permissions пользователя: роли сервера и принимаемые привилегии
+type RoleLister interface {
+	ListGrantableRoles(ctx context.Context) ([]models.GrantableRole, error)
+}
+
+// ActivityMonitor реализуется драйверами, которые умеют показывать
+// выполняющиеся запросы и прерывать их (PostgreSQL, MongoDB). Нехватка прав
+// пользователя подключения возвращается как ErrPermissionDenied
+type ActivityMonitor interface {
+	ListActivity(ctx context.Context) ([]models.QueryActivity, error)
+	TerminateBackend(ctx context.Context, pid int) error
+}
+
+// ErrPermissionDenied - у пользователя подключения нет прав на операцию
+var ErrPermissionDenied = errors.New("недостаточно прав")
+
+// ClusterHealthChecker реализуется драйверами кластерных БД,
+// которые умеют отдавать состояние кластера и узлов
+type ClusterHealthChecker interface {
+	ClusterHealth(ctx context.Context) (*models.ClusterHealth, error)
+}
+
+// CockroachInspector реализуется драйвером CockroachDB: сведения о кластере
+// из crdb_internal и распределение таблицы по диапазонам (SHOW RANGES)
+type CockroachInspector interface {
+	ClusterInfo(ctx context.Context) (*models.CockroachClusterInfo, error)
+	TableRanges(ctx context.Context, table string) (*models.QueryResponse, error)
+}
+
+// SettingsManager реализуется драйверами, которые позволяют читать
+// и менять параметры сервера
+type SettingsManager interface {
+	ListSettings(ctx context.Context) ([]models.ServerSetting, error)
+	SetSetting(ctx context.Context, name, value, scope string) error
+}
+
+// IndexSettingsManager реализуется поисковыми движками, у которых
+// настройки индекса меняются асинхронной задачей
+type IndexSettingsManager interface {
+	GetIndexSettings(ctx context.Context, index string) (map[string]interface{}, error)
+	UpdateIndexSettings(ctx context.Context, index string, settings map[string]interface{}) (*models.AsyncTask, error)
+}
+
+// IndexManager реализуется драйверами, у которых индексы создаются
+// и удаляются отдельно от таблицы
+type IndexManager interface {
+	ListIndexes(ctx context.Context, table string) ([]models.IndexInfo, error)
+	CreateIndex(ctx context.Context, req models.CreateIndexRequest) error
+	DropIndex(ctx context.Context, table, name string) error
+}
+
+// TaskTracker реализуется драйверами, у которых длительные операции
+// (переиндексация, запись документов) выполняются фоновыми задачами
+type TaskTracker interface {
+	GetTaskStatus(ctx context.Context, taskID string) (*models.TaskStatus, error)
+}
+
+// FilterCompiler реализуется драйверами документных хранилищ: упрощенный
+// фильтр собирается в текст запроса, который принимает ExecuteQuery
+type FilterCompiler interface {
+	CompileFilter(filter models.QueryFilter) (string, error)
+}
+
+// ConstrainedTableCreator реализуется драйверами, которые умеют создавать
+// таблицы с составным первичным ключом и ограничениями CHECK
+type ConstrainedTableCreator interface {
+	CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error
+}
+
+// TableTruncater реализуется драйверами, которые умеют удалять все строки
+// (документы, ключи), сохраняя структуру таблицы
+type TableTruncater interface {
+	TruncateTable(ctx context.Context, name string) error
+}
+
+// RowDeleter удаляет строки по фильтру и возвращает их число. Формат
+// filter зависит от БД: условие WHERE (SQL), фильтр deleteMany (MongoDB)
+// или шаблон ключей (Redis); пустой filter удаляет все строки
+type RowDeleter interface {
+	DeleteRows(ctx context.Context, table, filter string, args []interface{}) (int64, error)
+}
+
+// DependencyAwareDropper реализуется драйверами с внешними ключами:
+// TableDependencies возвращает для каждой таблицы список таблиц, на которые она ссылается
+type DependencyAwareDropper interface {
+	TableDependencies(ctx context.Context) (map[string][]string, error)
+	DropTable(ctx context.Context, name string, cascade bool) error
+}
+
+// DDLPlanner реализуется SQL-драйверами: возвращает команды, которые
+// выполнили бы операции над базами и таблицами, не выполняя их. Сами
+// операции строят команды теми же функциями, поэтому предпросмотр совпадает
+// с тем, что будет выполнено
+type DDLPlanner interface {
+	PlanCreateDatabase(name string, options map[string]interface{}) ([]string, error)
+	PlanUpdateDatabase(oldName, newName string, options map[string]interface{}) ([]string, error)
+	PlanDeleteDatabase(name string) ([]string, error)
+	PlanCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) ([]string, error)
+	PlanUpdateTable(oldName, newName string, columns []models.TableColumn) ([]string, error)
+	PlanDeleteTable(name string) ([]string, error)
 }
 
 type DriverFactory struct{}
@@ -67,6 +301,12 @@ func (f *DriverFactory) CreateDriver(dbType models.DatabaseType) DatabaseDriver
 		return NewRabbitMQDriver()
 	case models.Zookeeper:
 		return NewZookeeperDriver()
+	case models.Oracle:
+		return NewOracleDriver()
+	case models.SQLite:
+		return NewSQLiteDriver()
+	case models.Prometheus:
+		return NewPrometheusDriver()
 	default:
 		return nil
 	}
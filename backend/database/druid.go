@@ -18,8 +18,13 @@ type DruidDriver struct {
 }
 
 func NewDruidDriver() *DruidDriver {
-	return &DruidDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &DruidDriver{}
+}
+
+// Capabilities: Druid доступен только для чтения через Druid SQL
+func (d *DruidDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage: "sql",
 	}
 }
 
@@ -31,6 +36,12 @@ func (d *DruidDriver) Connect(ctx context.Context, conn models.Connection) error
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Druid: %w", err)
 	}
@@ -63,7 +74,7 @@ func (d *DruidDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -121,23 +132,18 @@ func (d *DruidDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 		return &models.QueryResponse{Error: err.Error()}, nil
 	}
 
-	columns := []string{}
+	columns := newColumnSet()
 	rowsData := make([]map[string]interface{}, 0)
 
-	if len(results) > 0 {
-		for key := range results[0] {
-			columns = append(columns, key)
-		}
-	}
-
 	for _, row := range results {
+		columns.addKeys(row)
 		rowsData = append(rowsData, row)
 	}
 
 	executionTime := time.Since(startTime).Milliseconds()
 
 	return &models.QueryResponse{
-		Columns:       columns,
+		Columns:       columns.list(),
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
@@ -171,7 +177,7 @@ func (d *DruidDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo,
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -230,7 +236,7 @@ func (d *DruidDriver) ListTables(ctx context.Context) ([]models.TableInfo, error
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
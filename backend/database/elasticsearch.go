@@ -8,6 +8,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"time"
 )
 
@@ -18,8 +20,17 @@ type ElasticsearchDriver struct {
 }
 
 func NewElasticsearchDriver() *ElasticsearchDriver {
-	return &ElasticsearchDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &ElasticsearchDriver{}
+}
+
+// Capabilities: базы - это индексы, переименование выполняется через reindex
+func (d *ElasticsearchDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "json",
+		SupportsCreateDatabase: true,
+		SupportsRenameDatabase: true,
+		SupportsDeleteTable:    true,
+		SupportsUsers:          true,
 	}
 }
 
@@ -31,6 +42,12 @@ func (d *ElasticsearchDriver) Connect(ctx context.Context, conn models.Connectio
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Elasticsearch: %w", err)
 	}
@@ -62,7 +79,7 @@ func (d *ElasticsearchDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -94,6 +111,63 @@ func (d *ElasticsearchDriver) ExecuteQuery(ctx context.Context, query string) (*
 		index = "_all"
 	}
 
+	return d.search(ctx, index, searchQuery, startTime)
+}
+
+// CompileFilter собирает тело _search с bool-запросом. eq использует term,
+// поэтому для текстовых полей указывайте поле .keyword
+func (d *ElasticsearchDriver) CompileFilter(filter models.QueryFilter) (string, error) {
+	if err := validateFilter(filter); err != nil {
+		return "", err
+	}
+	return marshalCompiledQuery(map[string]interface{}{"query": compileElasticFilter(filter)})
+}
+
+func (d *ElasticsearchDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	searchQuery := map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"from": offset,
+		"size": limit,
+	}
+
+	return d.search(ctx, table, searchQuery, time.Now())
+}
+
+// SearchTable ищет документы индекса через multi_match. Без указанных полей
+// поиск идет по всем полям, lenient отключает ошибки на нетекстовых полях.
+func (d *ElasticsearchDriver) SearchTable(ctx context.Context, table, text string, columns []string, limit, offset int) (*models.QueryResponse, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	fields := columns
+	if len(fields) == 0 {
+		fields = []string{"*"}
+	}
+
+	searchQuery := map[string]interface{}{
+		"query": map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":   text,
+				"fields":  fields,
+				"type":    "phrase_prefix",
+				"lenient": true,
+			},
+		},
+		"from": offset,
+		"size": limit,
+	}
+
+	return d.search(ctx, table, searchQuery, time.Now())
+}
+
+func (d *ElasticsearchDriver) search(ctx context.Context, index string, searchQuery map[string]interface{}, startTime time.Time) (*models.QueryResponse, error) {
 	url := fmt.Sprintf("%s/%s/_search", d.baseURL, index)
 	body, _ := json.Marshal(searchQuery)
 
@@ -128,7 +202,7 @@ func (d *ElasticsearchDriver) ExecuteQuery(ctx context.Context, query string) (*
 	hits, _ := result["hits"].(map[string]interface{})
 	hitsList, _ := hits["hits"].([]interface{})
 
-	columns := []string{"_id", "_source"}
+	columns := newColumnSet("_id", "_source")
 	rowsData := make([]map[string]interface{}, 0)
 
 	for _, hit := range hitsList {
@@ -137,10 +211,8 @@ func (d *ElasticsearchDriver) ExecuteQuery(ctx context.Context, query string) (*
 		row["_id"] = hitMap["_id"]
 		
 		if source, ok := hitMap["_source"].(map[string]interface{}); ok {
+			columns.addKeys(source)
 			for key, value := range source {
-				if !contains(columns, key) {
-					columns = append(columns, key)
-				}
 				row[key] = value
 			}
 		}
@@ -150,13 +222,169 @@ func (d *ElasticsearchDriver) ExecuteQuery(ctx context.Context, query string) (*
 	executionTime := time.Since(startTime).Milliseconds()
 
 	return &models.QueryResponse{
-		Columns:       columns,
+		Columns:       columns.list(),
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
 	}, nil
 }
 
+// getJSON выполняет GET запрос к API и разбирает JSON ответ в target
+func (d *ElasticsearchDriver) getJSON(ctx context.Context, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	if d.conn.Username != "" {
+		req.SetBasicAuth(d.conn.Username, d.conn.Password)
+	}
+
+	resp, err := doIdempotent(d.client, req)
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("статус %d, ответ: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+	return nil
+}
+
+// ClusterHealth объединяет _cluster/health и дисковую статистику _nodes/stats/fs
+func (d *ElasticsearchDriver) ClusterHealth(ctx context.Context) (*models.ClusterHealth, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var health struct {
+		ClusterName         string `json:"cluster_name"`
+		Status              string `json:"status"`
+		NumberOfNodes       int    `json:"number_of_nodes"`
+		NumberOfDataNodes   int    `json:"number_of_data_nodes"`
+		ActivePrimaryShards int    `json:"active_primary_shards"`
+		ActiveShards        int    `json:"active_shards"`
+		RelocatingShards    int    `json:"relocating_shards"`
+		InitializingShards  int    `json:"initializing_shards"`
+		UnassignedShards    int    `json:"unassigned_shards"`
+	}
+	if err := d.getJSON(ctx, "/_cluster/health", &health); err != nil {
+		return nil, fmt.Errorf("кластер Elasticsearch %s недоступен: %w", d.baseURL, err)
+	}
+
+	var stats struct {
+		Nodes map[string]struct {
+			Name string `json:"name"`
+			Host string `json:"host"`
+			FS   struct {
+				Total struct {
+					TotalInBytes     int64 `json:"total_in_bytes"`
+					AvailableInBytes int64 `json:"available_in_bytes"`
+				} `json:"total"`
+			} `json:"fs"`
+		} `json:"nodes"`
+	}
+	if err := d.getJSON(ctx, "/_nodes/stats/fs", &stats); err != nil {
+		return nil, fmt.Errorf("ошибка получения статистики узлов: %w", err)
+	}
+
+	result := &models.ClusterHealth{
+		ClusterName:         health.ClusterName,
+		Status:              health.Status,
+		NumberOfNodes:       health.NumberOfNodes,
+		NumberOfDataNodes:   health.NumberOfDataNodes,
+		ActivePrimaryShards: health.ActivePrimaryShards,
+		ActiveShards:        health.ActiveShards,
+		RelocatingShards:    health.RelocatingShards,
+		InitializingShards:  health.InitializingShards,
+		UnassignedShards:    health.UnassignedShards,
+		Nodes:               make([]models.NodeDiskUsage, 0, len(stats.Nodes)),
+	}
+
+	for id, node := range stats.Nodes {
+		usage := models.NodeDiskUsage{
+			ID:             id,
+			Name:           node.Name,
+			Host:           node.Host,
+			DiskTotalBytes: node.FS.Total.TotalInBytes,
+			DiskFreeBytes:  node.FS.Total.AvailableInBytes,
+		}
+		if usage.DiskTotalBytes > 0 {
+			used := usage.DiskTotalBytes - usage.DiskFreeBytes
+			usage.DiskUsedPercent = float64(used) * 100 / float64(usage.DiskTotalBytes)
+		}
+		result.Nodes = append(result.Nodes, usage)
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Name < result.Nodes[j].Name
+	})
+
+	return result, nil
+}
+
+// GetTaskStatus возвращает состояние задачи из _tasks (например, _reindex,
+// запущенного с wait_for_completion=false). ID имеет вид node:number
+func (d *ElasticsearchDriver) GetTaskStatus(ctx context.Context, taskID string) (*models.TaskStatus, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var task struct {
+		Completed bool `json:"completed"`
+		Task      struct {
+			Action      string `json:"action"`
+			Description string `json:"description"`
+			Status      struct {
+				Total   int64 `json:"total"`
+				Created int64 `json:"created"`
+				Updated int64 `json:"updated"`
+				Deleted int64 `json:"deleted"`
+			} `json:"status"`
+		} `json:"task"`
+		Error *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+		Response struct {
+			Failures []interface{} `json:"failures"`
+		} `json:"response"`
+	}
+	if err := d.getJSON(ctx, "/_tasks/"+url.PathEscape(taskID), &task); err != nil {
+		return nil, fmt.Errorf("ошибка получения статуса задачи: %w", err)
+	}
+
+	progress := task.Task.Status
+	status := &models.TaskStatus{
+		ID:          taskID,
+		Status:      models.TaskProcessing,
+		Completed:   task.Completed,
+		Type:        task.Task.Action,
+		Description: task.Task.Description,
+		Total:       progress.Total,
+		Processed:   progress.Created + progress.Updated + progress.Deleted,
+	}
+
+	switch {
+	case task.Error != nil:
+		status.Status = models.TaskFailed
+		status.Error = fmt.Sprintf("%s: %s", task.Error.Type, task.Error.Reason)
+	case len(task.Response.Failures) > 0:
+		status.Status = models.TaskFailed
+		failures, _ := json.Marshal(task.Response.Failures)
+		status.Error = string(failures)
+	case task.Completed:
+		status.Status = models.TaskSucceeded
+	}
+
+	return status, nil
+}
+
 func (d *ElasticsearchDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
@@ -219,7 +447,7 @@ func (d *ElasticsearchDriver) ListDatabases(ctx context.Context) ([]models.Datab
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
@@ -263,51 +491,114 @@ func (d *ElasticsearchDriver) UpdateDatabase(ctx context.Context, oldName, newNa
 	}
 
 	if newName != "" && newName != oldName {
-		reindexURL := fmt.Sprintf("%s/_reindex", d.baseURL)
-		reindexBody := map[string]interface{}{
-			"source": map[string]interface{}{
-				"index": oldName,
-			},
-			"dest": map[string]interface{}{
-				"index": newName,
-			},
+		if err := d.reindex(ctx, oldName, newName); err != nil {
+			return err
 		}
 
-		jsonBody, _ := json.Marshal(reindexBody)
-		req, err := http.NewRequestWithContext(ctx, "POST", reindexURL, bytes.NewBuffer(jsonBody))
-		if err != nil {
-			return fmt.Errorf("ошибка создания запроса: %w", err)
+		// Исходный индекс удаляется только после того, как переиндексация
+		// завершилась и число документов в новом индексе совпало со старым
+		if err := d.DeleteDatabase(ctx, oldName); err != nil {
+			return fmt.Errorf("данные скопированы в %s, но старый индекс не удалён: %w", newName, err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+	}
 
-		if d.conn.Username != "" {
-			req.SetBasicAuth(d.conn.Username, d.conn.Password)
-		}
+	return nil
+}
+
+// reindex копирует документы source в dest фоновой задачей _reindex,
+// дожидается её завершения и сверяет число документов в обоих индексах
+func (d *ElasticsearchDriver) reindex(ctx context.Context, source, dest string) error {
+	reindexURL := fmt.Sprintf("%s/_reindex?wait_for_completion=false", d.baseURL)
+	reindexBody := map[string]interface{}{
+		"source": map[string]interface{}{
+			"index": source,
+		},
+		"dest": map[string]interface{}{
+			"index": dest,
+		},
+	}
+
+	jsonBody, _ := json.Marshal(reindexBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", reindexURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if d.conn.Username != "" {
+		req.SetBasicAuth(d.conn.Username, d.conn.Password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ошибка переиндексации: статус %d, ответ: %s", resp.StatusCode, string(body))
+	}
 
-		resp, err := d.client.Do(req)
+	var started struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal(body, &started); err != nil || started.Task == "" {
+		return fmt.Errorf("ошибка переиндексации: не получен ID задачи, ответ: %s", string(body))
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		status, err := d.GetTaskStatus(ctx, started.Task)
 		if err != nil {
-			return fmt.Errorf("ошибка выполнения запроса: %w", err)
+			return fmt.Errorf("переиндексация (задача %s): %w", started.Task, err)
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return fmt.Errorf("ошибка переиндексации: статус %d, ответ: %s", resp.StatusCode, string(body))
+		if status.Status == models.TaskFailed {
+			return fmt.Errorf("переиндексация (задача %s) завершилась ошибкой: %s", started.Task, status.Error)
+		}
+		if status.Completed {
+			break
 		}
 
-		deleteURL := fmt.Sprintf("%s/%s", d.baseURL, oldName)
-		delReq, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
-		if err == nil {
-			if d.conn.Username != "" {
-				delReq.SetBasicAuth(d.conn.Username, d.conn.Password)
-			}
-			d.client.Do(delReq)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("переиндексация (задача %s) не завершилась вовремя, старый индекс сохранён: %w", started.Task, ctx.Err())
+		case <-ticker.C:
 		}
 	}
 
+	var refreshed map[string]interface{}
+	if err := d.getJSON(ctx, "/"+url.PathEscape(dest)+"/_refresh", &refreshed); err != nil {
+		return fmt.Errorf("ошибка обновления индекса %s: %w", dest, err)
+	}
+
+	sourceCount, err := d.countDocuments(ctx, source)
+	if err != nil {
+		return err
+	}
+	destCount, err := d.countDocuments(ctx, dest)
+	if err != nil {
+		return err
+	}
+	if sourceCount != destCount {
+		return fmt.Errorf("переиндексация прервана: в %s %d документов, в %s %d; старый индекс сохранён", source, sourceCount, dest, destCount)
+	}
+
 	return nil
 }
 
+func (d *ElasticsearchDriver) countDocuments(ctx context.Context, index string) (int64, error) {
+	var result struct {
+		Count int64 `json:"count"`
+	}
+	if err := d.getJSON(ctx, "/"+url.PathEscape(index)+"/_count", &result); err != nil {
+		return 0, fmt.Errorf("ошибка подсчёта документов в %s: %w", index, err)
+	}
+	return result.Count, nil
+}
+
 func (d *ElasticsearchDriver) DeleteDatabase(ctx context.Context, name string) error {
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
@@ -356,7 +647,7 @@ func (d *ElasticsearchDriver) ListTables(ctx context.Context) ([]models.TableInf
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
@@ -496,7 +787,7 @@ func (d *ElasticsearchDriver) ListUsers(ctx context.Context) ([]models.UserInfo,
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
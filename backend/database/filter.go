@@ -0,0 +1,226 @@
+package database
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filterOps - операции упрощенного фильтра, общие для всех компиляторов
+var filterOps = map[string]bool{
+	"eq": true, "ne": true, "gt": true, "gte": true, "lt": true, "lte": true,
+	"in": true, "exists": true,
+}
+
+// validateFilter проверяет фильтр до компиляции, чтобы все драйверы
+// отвечали на некорректный фильтр одинаково
+func validateFilter(filter models.QueryFilter) error {
+	switch strings.ToLower(filter.Logic) {
+	case "", "and", "or":
+	default:
+		return fmt.Errorf("неизвестная логика %q: допустимы and и or", filter.Logic)
+	}
+
+	for _, cond := range filter.Conditions {
+		if cond.Field == "" {
+			return fmt.Errorf("у условия не указано поле")
+		}
+		if !filterOps[cond.Op] {
+			return fmt.Errorf("неизвестная операция %q для поля %s", cond.Op, cond.Field)
+		}
+		switch cond.Op {
+		case "in":
+			if _, ok := cond.Value.([]interface{}); !ok {
+				return fmt.Errorf("для операции in поля %s значение должно быть массивом", cond.Field)
+			}
+		case "exists":
+			if _, ok := cond.Value.(bool); !ok && cond.Value != nil {
+				return fmt.Errorf("для операции exists поля %s значение должно быть true или false", cond.Field)
+			}
+		}
+	}
+
+	for _, group := range filter.Groups {
+		if err := validateFilter(group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func filterIsOr(filter models.QueryFilter) bool {
+	return strings.EqualFold(filter.Logic, "or")
+}
+
+func existsValue(cond models.FilterCondition) bool {
+	exists, ok := cond.Value.(bool)
+	return !ok || exists
+}
+
+// compileElasticFilter собирает bool-запрос Elasticsearch. Условия and
+// попадают в filter (без влияния на релевантность), or - в should
+func compileElasticFilter(filter models.QueryFilter) map[string]interface{} {
+	clauses := make([]interface{}, 0, len(filter.Conditions)+len(filter.Groups))
+	for _, cond := range filter.Conditions {
+		clauses = append(clauses, elasticCondition(cond))
+	}
+	for _, group := range filter.Groups {
+		clauses = append(clauses, compileElasticFilter(group))
+	}
+
+	if len(clauses) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	if filterIsOr(filter) {
+		return map[string]interface{}{"bool": map[string]interface{}{
+			"should":               clauses,
+			"minimum_should_match": 1,
+		}}
+	}
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": clauses}}
+}
+
+func elasticCondition(cond models.FilterCondition) map[string]interface{} {
+	field := func(value interface{}) map[string]interface{} {
+		return map[string]interface{}{cond.Field: value}
+	}
+
+	switch cond.Op {
+	case "ne":
+		return map[string]interface{}{"bool": map[string]interface{}{
+			"must_not": []interface{}{map[string]interface{}{"term": field(cond.Value)}},
+		}}
+	case "gt", "gte", "lt", "lte":
+		return map[string]interface{}{"range": field(map[string]interface{}{cond.Op: cond.Value})}
+	case "in":
+		return map[string]interface{}{"terms": field(cond.Value)}
+	case "exists":
+		exists := map[string]interface{}{"exists": map[string]interface{}{"field": cond.Field}}
+		if existsValue(cond) {
+			return exists
+		}
+		return map[string]interface{}{"bool": map[string]interface{}{"must_not": []interface{}{exists}}}
+	}
+	return map[string]interface{}{"term": field(cond.Value)}
+}
+
+// compileMongoFilter собирает фильтр find: условия объединяются
+// через $and/$or, единственное условие возвращается как есть
+func compileMongoFilter(filter models.QueryFilter) map[string]interface{} {
+	clauses := make([]interface{}, 0, len(filter.Conditions)+len(filter.Groups))
+	for _, cond := range filter.Conditions {
+		clauses = append(clauses, mongoCondition(cond))
+	}
+	for _, group := range filter.Groups {
+		clauses = append(clauses, compileMongoFilter(group))
+	}
+
+	switch len(clauses) {
+	case 0:
+		return map[string]interface{}{}
+	case 1:
+		return clauses[0].(map[string]interface{})
+	}
+	if filterIsOr(filter) {
+		return map[string]interface{}{"$or": clauses}
+	}
+	return map[string]interface{}{"$and": clauses}
+}
+
+func mongoCondition(cond models.FilterCondition) map[string]interface{} {
+	var value interface{}
+	if cond.Op == "exists" {
+		value = existsValue(cond)
+	} else {
+		value = cond.Value
+	}
+	return map[string]interface{}{cond.Field: map[string]interface{}{"$" + cond.Op: value}}
+}
+
+var meiliOperators = map[string]string{
+	"eq": "=", "ne": "!=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<=",
+}
+
+// compileMeiliFilter собирает строку filter Meilisearch, например
+// `genre = "drama" AND (year > 2000 OR rating >= 8)`. Поля должны быть
+// в filterableAttributes индекса
+func compileMeiliFilter(filter models.QueryFilter) (string, error) {
+	clauses := make([]string, 0, len(filter.Conditions)+len(filter.Groups))
+	for _, cond := range filter.Conditions {
+		clause, err := meiliCondition(cond)
+		if err != nil {
+			return "", err
+		}
+		clauses = append(clauses, clause)
+	}
+	for _, group := range filter.Groups {
+		clause, err := compileMeiliFilter(group)
+		if err != nil {
+			return "", err
+		}
+		if clause != "" {
+			clauses = append(clauses, "("+clause+")")
+		}
+	}
+
+	separator := " AND "
+	if filterIsOr(filter) {
+		separator = " OR "
+	}
+	return strings.Join(clauses, separator), nil
+}
+
+func meiliCondition(cond models.FilterCondition) (string, error) {
+	if strings.ContainsAny(cond.Field, " \t\n\"'()") {
+		return "", fmt.Errorf("недопустимое имя поля %q для фильтра Meilisearch", cond.Field)
+	}
+
+	switch cond.Op {
+	case "exists":
+		if existsValue(cond) {
+			return cond.Field + " EXISTS", nil
+		}
+		return cond.Field + " NOT EXISTS", nil
+	case "in":
+		values := cond.Value.([]interface{})
+		items := make([]string, 0, len(values))
+		for _, value := range values {
+			item, err := meiliLiteral(value)
+			if err != nil {
+				return "", fmt.Errorf("поле %s: %w", cond.Field, err)
+			}
+			items = append(items, item)
+		}
+		return cond.Field + " IN [" + strings.Join(items, ", ") + "]", nil
+	}
+
+	value, err := meiliLiteral(cond.Value)
+	if err != nil {
+		return "", fmt.Errorf("поле %s: %w", cond.Field, err)
+	}
+	return cond.Field + " " + meiliOperators[cond.Op] + " " + value, nil
+}
+
+func meiliLiteral(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", fmt.Errorf("неподдерживаемый тип значения %T", value)
+}
+
+func marshalCompiledQuery(query interface{}) (string, error) {
+	data, err := json.Marshal(query)
+	if err != nil {
+		return "", fmt.Errorf("ошибка сборки запроса: %w", err)
+	}
+	return string(data), nil
+}
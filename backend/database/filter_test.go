@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"testing"
+)
+
+var testFilter = models.QueryFilter{
+	Conditions: []models.FilterCondition{
+		{Field: "genre", Op: "eq", Value: "drama"},
+	},
+	Groups: []models.QueryFilter{{
+		Logic: "or",
+		Conditions: []models.FilterCondition{
+			{Field: "year", Op: "gt", Value: float64(2000)},
+			{Field: "tags", Op: "in", Value: []interface{}{"a", "b"}},
+		},
+	}},
+}
+
+func TestCompileMeiliFilter(t *testing.T) {
+	got, err := compileMeiliFilter(testFilter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `genre = "drama" AND (year > 2000 OR tags IN ["a", "b"])`
+	if got != want {
+		t.Errorf("compileMeiliFilter() = %s, want %s", got, want)
+	}
+
+	escaped, _ := compileMeiliFilter(models.QueryFilter{Conditions: []models.FilterCondition{
+		{Field: "title", Op: "ne", Value: `say "hi"`},
+		{Field: "poster", Op: "exists", Value: false},
+	}})
+	if want := `title != "say \"hi\"" AND poster NOT EXISTS`; escaped != want {
+		t.Errorf("compileMeiliFilter() = %s, want %s", escaped, want)
+	}
+
+	if _, err := compileMeiliFilter(models.QueryFilter{Conditions: []models.FilterCondition{
+		{Field: "a OR b", Op: "eq", Value: "x"},
+	}}); err == nil {
+		t.Error("expected error for field with spaces")
+	}
+}
+
+func TestCompileMongoFilter(t *testing.T) {
+	data, _ := json.Marshal(compileMongoFilter(testFilter))
+	want := `{"$and":[{"genre":{"$eq":"drama"}},{"$or":[{"year":{"$gt":2000}},{"tags":{"$in":["a","b"]}}]}]}`
+	if string(data) != want {
+		t.Errorf("compileMongoFilter() = %s, want %s", data, want)
+	}
+
+	data, _ = json.Marshal(compileMongoFilter(models.QueryFilter{}))
+	if string(data) != "{}" {
+		t.Errorf("empty filter = %s, want {}", data)
+	}
+}
+
+func TestCompileElasticFilter(t *testing.T) {
+	data, _ := json.Marshal(compileElasticFilter(testFilter))
+	want := `{"bool":{"filter":[{"term":{"genre":"drama"}},{"bool":{"minimum_should_match":1,"should":[{"range":{"year":{"gt":2000}}},{"terms":{"tags":["a","b"]}}]}}]}}`
+	if string(data) != want {
+		t.Errorf("compileElasticFilter() = %s, want %s", data, want)
+	}
+}
+
+func TestValidateFilter(t *testing.T) {
+	invalid := []models.QueryFilter{
+		{Logic: "xor"},
+		{Conditions: []models.FilterCondition{{Op: "eq", Value: 1}}},
+		{Conditions: []models.FilterCondition{{Field: "a", Op: "like", Value: "x"}}},
+		{Conditions: []models.FilterCondition{{Field: "a", Op: "in", Value: "x"}}},
+		{Groups: []models.QueryFilter{{Conditions: []models.FilterCondition{{Field: "a", Op: "exists", Value: "yes"}}}}},
+	}
+	for i, filter := range invalid {
+		if err := validateFilter(filter); err == nil {
+			t.Errorf("case %d: expected error", i)
+		}
+	}
+
+	if err := validateFilter(testFilter); err != nil {
+		t.Errorf("validateFilter(testFilter) = %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"database-manager/models"
+	"fmt"
+)
+
+// graphCollector собирает узлы и связи результата без повторов: один узел
+// встречается во многих строках и путях
+type graphCollector struct {
+	seenNodes         map[string]bool
+	seenRelationships map[string]bool
+	result            models.GraphResult
+}
+
+func newGraphCollector() *graphCollector {
+	return &graphCollector{
+		seenNodes:         make(map[string]bool),
+		seenRelationships: make(map[string]bool),
+		result: models.GraphResult{
+			Nodes:         []models.GraphNode{},
+			Relationships: []models.GraphRelationship{},
+		},
+	}
+}
+
+func (g *graphCollector) addNode(node models.GraphNode) {
+	if g.seenNodes[node.ID] {
+		return
+	}
+	g.seenNodes[node.ID] = true
+	g.result.Nodes = append(g.result.Nodes, node)
+}
+
+func (g *graphCollector) addRelationship(rel models.GraphRelationship) {
+	if g.seenRelationships[rel.ID] {
+		return
+	}
+	g.seenRelationships[rel.ID] = true
+	g.result.Relationships = append(g.result.Relationships, rel)
+}
+
+// graph возвращает собранный граф; nil - графовых значений в результате нет
+func (g *graphCollector) graph() *models.GraphResult {
+	if len(g.result.Nodes) == 0 && len(g.result.Relationships) == 0 {
+		return nil
+	}
+	result := g.result
+	return &result
+}
+
+// addRESTGraph разбирает часть "graph" строки ответа HTTP API Neo4j
+// (resultDataContents: ["row", "graph"]). Идентификатор - elementId, а у
+// серверов до 5.x, где его нет, - числовой id
+func (g *graphCollector) addRESTGraph(value interface{}) {
+	graph, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	nodes, _ := graph["nodes"].([]interface{})
+	for _, item := range nodes {
+		node, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		g.addNode(models.GraphNode{
+			ID:         restGraphID(node, "elementId", "id"),
+			Labels:     restGraphLabels(node["labels"]),
+			Properties: restGraphProperties(node["properties"]),
+		})
+	}
+
+	relationships, _ := graph["relationships"].([]interface{})
+	for _, item := range relationships {
+		rel, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		relType, _ := rel["type"].(string)
+		g.addRelationship(models.GraphRelationship{
+			ID:         restGraphID(rel, "elementId", "id"),
+			Type:       relType,
+			StartNode:  restGraphID(rel, "startNodeElementId", "startNode"),
+			EndNode:    restGraphID(rel, "endNodeElementId", "endNode"),
+			Properties: restGraphProperties(rel["properties"]),
+		})
+	}
+}
+
+func restGraphID(entity map[string]interface{}, elementIDKey, idKey string) string {
+	if elementID, ok := entity[elementIDKey].(string); ok && elementID != "" {
+		return elementID
+	}
+	if id, ok := entity[idKey]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return ""
+}
+
+func restGraphLabels(value interface{}) []string {
+	items, _ := value.([]interface{})
+	labels := make([]string, 0, len(items))
+	for _, item := range items {
+		if label, ok := item.(string); ok {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+func restGraphProperties(value interface{}) map[string]interface{} {
+	if properties, ok := value.(map[string]interface{}); ok {
+		return properties
+	}
+	return map[string]interface{}{}
+}
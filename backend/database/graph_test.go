@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGraphCollectorRESTGraph(t *testing.T) {
+	// Части "graph" двух строк ответа HTTP API: узел 1 встречается в обеих,
+	// у второй строки идентификаторы только числовые (Neo4j 4.x)
+	rows := []string{
+		`{"nodes": [
+			{"id": "1", "elementId": "4:db:1", "labels": ["Person"], "properties": {"name": "Alice"}},
+			{"id": "2", "elementId": "4:db:2", "labels": ["Person"], "properties": {"name": "Bob"}}
+		], "relationships": [
+			{"id": "7", "elementId": "5:db:7", "type": "KNOWS", "startNode": "1", "startNodeElementId": "4:db:1",
+			 "endNode": "2", "endNodeElementId": "4:db:2", "properties": {"since": 2020}}
+		]}`,
+		`{"nodes": [
+			{"id": "1", "elementId": "4:db:1", "labels": ["Person"], "properties": {"name": "Alice"}},
+			{"id": 3, "labels": ["City"]}
+		], "relationships": [
+			{"id": 8, "type": "LIVES_IN", "startNode": 1, "endNode": 3}
+		]}`,
+	}
+
+	graph := newGraphCollector()
+	if graph.graph() != nil {
+		t.Fatal("empty collector must return nil graph")
+	}
+	for _, row := range rows {
+		var value interface{}
+		if err := json.Unmarshal([]byte(row), &value); err != nil {
+			t.Fatal(err)
+		}
+		graph.addRESTGraph(value)
+	}
+	graph.addRESTGraph(nil)
+
+	result := graph.graph()
+	if len(result.Nodes) != 3 {
+		t.Fatalf("nodes = %+v, want 3 without duplicates", result.Nodes)
+	}
+	if result.Nodes[0].ID != "4:db:1" || result.Nodes[0].Labels[0] != "Person" || result.Nodes[0].Properties["name"] != "Alice" {
+		t.Errorf("node 0 = %+v", result.Nodes[0])
+	}
+	if result.Nodes[2].ID != "3" || len(result.Nodes[2].Properties) != 0 || result.Nodes[2].Properties == nil {
+		t.Errorf("node without elementId = %+v", result.Nodes[2])
+	}
+
+	if len(result.Relationships) != 2 {
+		t.Fatalf("relationships = %+v", result.Relationships)
+	}
+	knows := result.Relationships[0]
+	if knows.ID != "5:db:7" || knows.Type != "KNOWS" || knows.StartNode != "4:db:1" || knows.EndNode != "4:db:2" {
+		t.Errorf("KNOWS = %+v", knows)
+	}
+	livesIn := result.Relationships[1]
+	if livesIn.ID != "8" || livesIn.StartNode != "1" || livesIn.EndNode != "3" {
+		t.Errorf("LIVES_IN = %+v", livesIn)
+	}
+}
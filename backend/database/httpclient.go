@@ -0,0 +1,139 @@
+package database
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"database-manager/models"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHTTPTimeout - таймаут запроса HTTP-драйверов, если он не задан ни
+// в подключении, ни в app.json
+const DefaultHTTPTimeout = 30 * time.Second
+
+var httpTimeout atomic.Int64
+
+func init() {
+	httpTimeout.Store(int64(DefaultHTTPTimeout))
+}
+
+// SetHTTPTimeout задает общий таймаут HTTP-драйверов; 0 - значение по умолчанию
+func SetHTTPTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultHTTPTimeout
+	}
+	httpTimeout.Store(int64(timeout))
+}
+
+// sharedTransport - общий пул соединений всех HTTP-драйверов. У
+// http.DefaultTransport MaxIdleConnsPerHost = 2, и при частых запросах к
+// одному кластеру соединения закрываются и открываются заново
+var sharedTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	ForceAttemptHTTP2:     true,
+	MaxIdleConns:          256,
+	MaxIdleConnsPerHost:   32,
+	IdleConnTimeout:       90 * time.Second,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ExpectContinueTimeout: time.Second,
+	TLSClientConfig:       &tls.Config{MinVersion: tls.VersionTLS12},
+}
+
+// insecureTransport - тот же пул для подключений со skipTlsVerify. Пулы
+// раздельные, чтобы соединение без проверки сертификата не досталось
+// подключению с проверкой
+var insecureTransport = func() *http.Transport {
+	t := sharedTransport.Clone()
+	t.TLSClientConfig.InsecureSkipVerify = true
+	return t
+}()
+
+// newHTTPClient возвращает клиент на общем транспорте с таймаутом из
+// httpTimeout подключения или общим
+func newHTTPClient(conn models.Connection) (*http.Client, error) {
+	timeout, err := conn.HTTPTimeoutDuration()
+	if err != nil {
+		return nil, err
+	}
+	if timeout == 0 {
+		timeout = time.Duration(httpTimeout.Load())
+	}
+	transport, err := transportFor(conn)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+var (
+	tlsTransportsMu sync.Mutex
+	// tlsTransports - транспорты подключений с частным CA или клиентским
+	// сертификатом, по одному на набор TLS-параметров: подключения к одному
+	// кластеру с одним сертификатом делят пул. Ключ - хеш значений полей,
+	// поэтому замененный по тому же пути файл подхватится после перезапуска
+	tlsTransports = map[string]*http.Transport{}
+)
+
+func transportFor(conn models.Connection) (*http.Transport, error) {
+	if conn.CACert == "" && conn.ClientCert == "" && conn.ClientKey == "" {
+		if conn.SkipTLSVerify {
+			return insecureTransport, nil
+		}
+		return sharedTransport, nil
+	}
+
+	key := fmt.Sprintf("%t:%x", conn.SkipTLSVerify,
+		sha256.Sum256([]byte(conn.CACert+"\x00"+conn.ClientCert+"\x00"+conn.ClientKey)))
+
+	tlsTransportsMu.Lock()
+	defer tlsTransportsMu.Unlock()
+	if transport, ok := tlsTransports[key]; ok {
+		return transport, nil
+	}
+
+	tlsConfig, err := connTLSConfig(conn)
+	if err != nil {
+		return nil, err
+	}
+	transport := sharedTransport.Clone()
+	transport.TLSClientConfig.RootCAs = tlsConfig.RootCAs
+	transport.TLSClientConfig.Certificates = tlsConfig.Certificates
+	transport.TLSClientConfig.InsecureSkipVerify = conn.SkipTLSVerify
+	tlsTransports[key] = transport
+	return transport, nil
+}
+
+// connTLSConfig - TLS для драйверов с собственным протоколом (PostgreSQL,
+// ClickHouse, Redis, MongoDB). ServerName нужен для проверки сертификата:
+// pgx без него отказывается устанавливать TLS. При DSN хосты берутся из
+// строки подключения, и ServerName подставляет драйвер
+func connTLSConfig(conn models.Connection) (*tls.Config, error) {
+	rootCAs, err := conn.CACertPool()
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: conn.SkipTLSVerify,
+	}
+	cert, err := conn.ClientCertificate()
+	if err != nil {
+		return nil, err
+	}
+	if cert != nil {
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	if conn.DSN == "" {
+		tlsConfig.ServerName = conn.Host
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database-manager/models"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewHTTPClientTimeout(t *testing.T) {
+	SetHTTPTimeout(45 * time.Second)
+	defer SetHTTPTimeout(0)
+
+	client, err := newHTTPClient(models.Connection{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != 45*time.Second || client.Transport != sharedTransport {
+		t.Errorf("timeout = %v, transport shared = %v", client.Timeout, client.Transport == sharedTransport)
+	}
+
+	client, err = newHTTPClient(models.Connection{HTTPTimeout: "2m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Timeout != 2*time.Minute {
+		t.Errorf("timeout = %v, want 2m", client.Timeout)
+	}
+
+	client, err = newHTTPClient(models.Connection{SkipTLSVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Transport != insecureTransport || sharedTransport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("skipTlsVerify must use a separate insecure transport")
+	}
+
+	if _, err := newHTTPClient(models.Connection{HTTPTimeout: "soon"}); err == nil {
+		t.Error("expected error for invalid httpTimeout")
+	}
+}
+
+func TestNewHTTPClientCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	client, err := newHTTPClient(models.Connection{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Error("expected certificate error without caCert")
+	}
+
+	client, err = newHTTPClient(models.Connection{CACert: caPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with caCert: %v", err)
+	}
+	resp.Body.Close()
+
+	again, _ := newHTTPClient(models.Connection{CACert: caPEM})
+	if again.Transport != client.Transport {
+		t.Error("connections with the same caCert must share a transport")
+	}
+
+	if _, err := newHTTPClient(models.Connection{CACert: "-----BEGIN CERTIFICATE-----\nbroken"}); err == nil {
+		t.Error("expected error for invalid caCert")
+	}
+}
+
+func TestNewHTTPClientClientCert(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	certPEM, keyPEM := testClientCertificate(t)
+
+	client, err := newHTTPClient(models.Connection{CACert: caPEM, ClientCert: certPEM, ClientKey: keyPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("request with client certificate: %v", err)
+	}
+	resp.Body.Close()
+
+	_, otherKey := testClientCertificate(t)
+	if _, err := newHTTPClient(models.Connection{ClientCert: certPEM, ClientKey: otherKey}); err == nil {
+		t.Error("expected error for mismatched clientKey")
+	}
+	if _, err := newHTTPClient(models.Connection{ClientCert: certPEM}); err == nil {
+		t.Error("expected error without clientKey")
+	}
+}
+
+func testClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// DefaultHTTPRetryAttempts - попыток на идемпотентный запрос HTTP-драйверов
+// (первая и два повтора)
+const DefaultHTTPRetryAttempts = 3
+
+const (
+	httpRetryBaseDelay = 200 * time.Millisecond
+	httpRetryMaxDelay  = 3 * time.Second
+)
+
+var httpRetryAttempts atomic.Int32
+
+func init() {
+	httpRetryAttempts.Store(DefaultHTTPRetryAttempts)
+}
+
+// SetHTTPRetryAttempts задает число попыток; 0 - значение по умолчанию,
+// 1 - без повторов
+func SetHTTPRetryAttempts(attempts int) {
+	if attempts <= 0 {
+		attempts = DefaultHTTPRetryAttempts
+	}
+	httpRetryAttempts.Store(int32(attempts))
+}
+
+// doIdempotent выполняет запрос, повторяя его при сетевых сбоях и ответах
+// 429/502/503/504 с экспоненциальной задержкой и джиттером. Вызывать только
+// для запросов без побочных эффектов: ping, списки, чтение метаданных.
+// Тело запроса повторяется через GetBody (его заполняет http.NewRequest
+// для bytes.Buffer/Reader и strings.Reader)
+func doIdempotent(client *http.Client, req *http.Request) (*http.Response, error) {
+	attempts := int(httpRetryAttempts.Load())
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= attempts || !retryableHTTPResult(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		if err := sleepCtx(req.Context(), httpRetryDelay(attempt)); err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+func retryableHTTPResult(resp *http.Response, err error) bool {
+	if err != nil {
+		return isTransientNetError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransientNetError: отказ или разрыв соединения. Отмена контекста, таймаут
+// всего запроса, ошибки TLS-сертификата и несуществующий хост повтором не
+// исправить
+func isTransientNetError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET)
+}
+
+// httpRetryDelay - "full jitter": случайная задержка до base*2^(attempt-1)
+func httpRetryDelay(attempt int) time.Duration {
+	ceiling := httpRetryBaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > httpRetryMaxDelay {
+		ceiling = httpRetryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling))) + time.Millisecond
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -21,9 +21,19 @@ type InfluxDBDriver struct {
 }
 
 func NewInfluxDBDriver() *InfluxDBDriver {
-	return &InfluxDBDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &InfluxDBDriver{}
+}
+
+// Capabilities: InfluxDB 2.x принимает Flux, 1.x - InfluxQL
+func (d *InfluxDBDriver) Capabilities() models.DriverCapabilities {
+	caps := models.DriverCapabilities{
+		QueryLanguage:          "influxql",
+		SupportsCreateDatabase: true,
+	}
+	if d.version == "2" {
+		caps.QueryLanguage = "flux"
 	}
+	return caps
 }
 
 func (d *InfluxDBDriver) Connect(ctx context.Context, conn models.Connection) error {
@@ -34,6 +44,12 @@ func (d *InfluxDBDriver) Connect(ctx context.Context, conn models.Connection) er
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.detectVersion(ctx); err != nil {
 		return fmt.Errorf("ошибка определения версии InfluxDB: %w", err)
 	}
@@ -52,7 +68,7 @@ func (d *InfluxDBDriver) detectVersion(ctx context.Context) error {
 		return err
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -93,7 +109,7 @@ func (d *InfluxDBDriver) Ping(ctx context.Context) error {
 		return err
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -266,6 +282,81 @@ func (d *InfluxDBDriver) executeQueryV2(ctx context.Context, query string) (*mod
 	}, nil
 }
 
+// WritePoints записывает точки через /write (1.x) или /api/v2/write (2.x).
+// В 1.x база - req.Database или база подключения, в 2.x bucket обязателен,
+// а организация берется из подключения
+func (d *InfluxDBDriver) WritePoints(ctx context.Context, req models.WriteRowsRequest) (*models.WriteResult, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	precision, err := influxPrecision(req.Precision)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(req.Lines)
+	if body == "" {
+		body, err = influxLineProtocol(req.Table, req.Rows, precision)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var points int64
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			points++
+		}
+	}
+
+	params := url.Values{}
+	var writeURL string
+	if d.version == "2" {
+		if req.Database == "" {
+			return nil, fmt.Errorf("укажите bucket в поле database")
+		}
+		writeURL = fmt.Sprintf("%s/api/v2/write", d.baseURL)
+		params.Set("org", d.conn.Database)
+		params.Set("bucket", req.Database)
+		params.Set("precision", precision)
+	} else {
+		database := req.Database
+		if database == "" {
+			database = d.conn.Database
+		}
+		writeURL = fmt.Sprintf("%s/write", d.baseURL)
+		params.Set("db", database)
+		// В 1.x микросекунды обозначаются u
+		if precision == "us" {
+			params.Set("precision", "u")
+		} else {
+			params.Set("precision", precision)
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", writeURL+"?"+params.Encode(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if d.conn.Username != "" {
+		httpReq.SetBasicAuth(d.conn.Username, d.conn.Password)
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ошибка записи точек: %s", string(respBody))
+	}
+
+	return &models.WriteResult{RowsAffected: points}, nil
+}
+
 func (d *InfluxDBDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
@@ -369,7 +460,7 @@ func (d *InfluxDBDriver) listDatabasesV1(ctx context.Context) ([]models.Database
 		return nil, err
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -416,7 +507,7 @@ func (d *InfluxDBDriver) listDatabasesV2(ctx context.Context) ([]models.Database
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -569,7 +660,7 @@ func (d *InfluxDBDriver) ListTables(ctx context.Context) ([]models.TableInfo, er
 			return nil, err
 		}
 
-		resp, err := d.client.Do(req)
+		resp, err := doIdempotent(d.client, req)
 		if err != nil {
 			return nil, err
 		}
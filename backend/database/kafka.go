@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 )
 
 type KafkaDriver struct {
@@ -18,8 +17,12 @@ type KafkaDriver struct {
 }
 
 func NewKafkaDriver() *KafkaDriver {
-	return &KafkaDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &KafkaDriver{}
+}
+
+func (d *KafkaDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		SupportsCreateDatabase: true,
 	}
 }
 
@@ -31,6 +34,12 @@ func (d *KafkaDriver) Connect(ctx context.Context, conn models.Connection) error
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Kafka: %w", err)
 	}
@@ -63,7 +72,7 @@ func (d *KafkaDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -146,7 +155,7 @@ func (d *KafkaDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo,
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -241,7 +250,7 @@ func (d *KafkaDriver) ListTables(ctx context.Context) ([]models.TableInfo, error
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
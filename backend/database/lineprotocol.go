@@ -0,0 +1,163 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// influxPrecisions - единицы времени точек: длительность единицы
+var influxPrecisions = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+var (
+	measurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	tagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+	stringFieldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// influxPrecision проверяет единицу времени; пусто - ns
+func influxPrecision(precision string) (string, error) {
+	if precision == "" {
+		return "ns", nil
+	}
+	if _, ok := influxPrecisions[precision]; !ok {
+		return "", fmt.Errorf("неверный precision %q, допустимо: ns, us, ms, s", precision)
+	}
+	return precision, nil
+}
+
+// influxLineProtocol собирает точки измерения measurement в line protocol.
+// Точка - {"tags": {...}, "fields": {...}, "time": ...}; без "fields" полями
+// считаются все ключи, кроме tags и time. Числа из JSON записываются как
+// float, целые Go-типы - с суффиксом i. time - число в единицах precision
+// или строка RFC3339; без time время ставит сервер. Теги и поля сортируются
+func influxLineProtocol(measurement string, points []map[string]interface{}, precision string) (string, error) {
+	if measurement == "" {
+		return "", fmt.Errorf("не указано измерение (table)")
+	}
+	unit, ok := influxPrecisions[precision]
+	if !ok {
+		return "", fmt.Errorf("неверный precision %q", precision)
+	}
+
+	lines := make([]string, 0, len(points))
+	for i, point := range points {
+		line, err := influxPointLine(measurement, point, unit)
+		if err != nil {
+			return "", fmt.Errorf("точка %d: %w", i+1, err)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func influxPointLine(measurement string, point map[string]interface{}, unit time.Duration) (string, error) {
+	var b strings.Builder
+	b.WriteString(measurementEscaper.Replace(measurement))
+
+	if rawTags, ok := point["tags"]; ok && rawTags != nil {
+		tags, ok := rawTags.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("tags должен быть объектом")
+		}
+		for _, key := range sortedKeys(tags) {
+			if tags[key] == nil {
+				continue
+			}
+			fmt.Fprintf(&b, ",%s=%s", tagEscaper.Replace(key), tagEscaper.Replace(fmt.Sprint(tags[key])))
+		}
+	}
+
+	var fields map[string]interface{}
+	if rawFields, ok := point["fields"]; ok {
+		if fields, ok = rawFields.(map[string]interface{}); !ok {
+			return "", fmt.Errorf("fields должен быть объектом")
+		}
+	} else {
+		fields = make(map[string]interface{}, len(point))
+		for key, value := range point {
+			if key != "tags" && key != "time" {
+				fields[key] = value
+			}
+		}
+	}
+
+	written := 0
+	for _, key := range sortedKeys(fields) {
+		value, err := influxFieldValue(fields[key])
+		if err != nil {
+			return "", fmt.Errorf("поле %s: %w", key, err)
+		}
+		if value == "" {
+			continue
+		}
+		separator := ","
+		if written == 0 {
+			separator = " "
+		}
+		fmt.Fprintf(&b, "%s%s=%s", separator, tagEscaper.Replace(key), value)
+		written++
+	}
+	if written == 0 {
+		return "", fmt.Errorf("нет полей со значениями")
+	}
+
+	if rawTime, ok := point["time"]; ok && rawTime != nil {
+		timestamp, err := influxTimestamp(rawTime, unit)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(" " + timestamp)
+	}
+	return b.String(), nil
+}
+
+// influxFieldValue форматирует значение поля; "" - значение пустое (NULL),
+// поле пропускается
+func influxFieldValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case int:
+		return strconv.Itoa(v) + "i", nil
+	case int64:
+		return strconv.FormatInt(v, 10) + "i", nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10) + "i", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return `"` + stringFieldEscaper.Replace(v) + `"`, nil
+	default:
+		return "", fmt.Errorf("неподдерживаемый тип значения %T", value)
+	}
+}
+
+func influxTimestamp(value interface{}, unit time.Duration) (string, error) {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case string:
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return "", fmt.Errorf("time: ожидается число или RFC3339, получено %q", v)
+		}
+		return strconv.FormatInt(t.UnixNano()/int64(unit), 10), nil
+	default:
+		return "", fmt.Errorf("time: неподдерживаемый тип %T", value)
+	}
+}
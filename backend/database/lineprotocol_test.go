@@ -0,0 +1,44 @@
+package database
+
+import "testing"
+
+func TestInfluxLineProtocol(t *testing.T) {
+	points := []map[string]interface{}{
+		{
+			"tags":   map[string]interface{}{"region": "eu west", "host": "a,1"},
+			"fields": map[string]interface{}{"value": 0.5, "count": int64(3), "ok": true, "note": `say "hi"`},
+			"time":   "2024-01-12T12:00:00Z",
+		},
+		// Без fields полями считаются остальные ключи; NULL пропускается
+		{"usage": float64(42), "comment": nil, "time": float64(1705060800)},
+	}
+
+	got, err := influxLineProtocol("cpu load", points, "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `cpu\ load,host=a\,1,region=eu\ west count=3i,note="say \"hi\"",ok=true,value=0.5 1705060800` + "\n" +
+		`cpu\ load usage=42 1705060800`
+	if got != want {
+		t.Errorf("influxLineProtocol() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := influxLineProtocol("cpu", []map[string]interface{}{{"tags": map[string]interface{}{"a": "b"}}}, "ns"); err == nil {
+		t.Error("expected error for point without fields")
+	}
+	if _, err := influxLineProtocol("cpu", []map[string]interface{}{{"v": 1.0, "time": "yesterday"}}, "ns"); err == nil {
+		t.Error("expected error for invalid time")
+	}
+	if _, err := influxLineProtocol("", points, "ns"); err == nil {
+		t.Error("expected error for empty measurement")
+	}
+}
+
+func TestInfluxPrecision(t *testing.T) {
+	if p, err := influxPrecision(""); err != nil || p != "ns" {
+		t.Errorf("influxPrecision(\"\") = %q, %v", p, err)
+	}
+	if _, err := influxPrecision("h"); err == nil {
+		t.Error("expected error for unsupported precision")
+	}
+}
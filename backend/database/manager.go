@@ -3,78 +3,344 @@ package database
 import (
 	"context"
 	"database-manager/models"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrDriverBusy возвращает Disconnect, если запросы через драйвер не
+// завершились за disconnectWait
+var ErrDriverBusy = errors.New("подключение занято: дождитесь завершения выполняющихся запросов")
+
+// disconnectWait - сколько Disconnect ждет завершения выполняющихся запросов
+const disconnectWait = 30 * time.Second
+
+// ErrQueryLimit возвращает AcquireQuerySlot, если все слоты запросов
+// подключения заняты дольше времени ожидания в очереди
+var ErrQueryLimit = errors.New("слишком много одновременных запросов к подключению, повторите позже")
+
+// driverEntry - драйвер и число операций, которые выполняются через него.
+// active увеличивается только под RLock, пока запись есть в drivers, поэтому
+// после удаления записи из drivers счетчик может только уменьшаться
+type driverEntry struct {
+	driver DatabaseDriver
+	active int64
+	// slots ограничивает число одновременных запросов; nil - без предела
+	slots chan struct{}
+}
+
+// waitIdle ждет завершения операций драйвера; false - ctx истек раньше
+func (e *driverEntry) waitIdle(ctx context.Context) bool {
+	ticker := time.NewTicker(20 * time.Millisecond)
+	defer ticker.Stop()
+
+	for atomic.LoadInt64(&e.active) > 0 {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+	return true
+}
+
 type ConnectionManager struct {
-	drivers map[string]DatabaseDriver
+	drivers map[string]*driverEntry
 	factory *DriverFactory
 	mu      sync.RWMutex
+
+	// lastUsed обновляется при каждом обращении к драйверу; отдельный
+	// мьютекс нужен, чтобы AcquireDriver оставался под RLock
+	lastUsed map[string]time.Time
+	usedMu   sync.Mutex
+
+	// queryLimit и queueTimeout задает SetQueryLimit
+	queryLimit   int
+	queueTimeout time.Duration
+
+	// envPrefix задает SetEnvPrefix
+	envPrefix string
+
+	// restoreReport - итог RestoreConnections при запуске сервера
+	restoreReport models.RestoreReport
+	restoreMu     sync.RWMutex
+
+	// reconnectMu не дает запросам, одновременно получившим ошибку
+	// соединения, переподключаться параллельно
+	reconnectMu sync.Mutex
 }
 
 func NewConnectionManager() *ConnectionManager {
 	return &ConnectionManager{
-		drivers: make(map[string]DatabaseDriver),
-		factory: NewDriverFactory(),
+		drivers:  make(map[string]*driverEntry),
+		factory:  NewDriverFactory(),
+		lastUsed: make(map[string]time.Time),
 	}
 }
 
+// Connect подключается вне блокировки: недоступный хост не должен
+// задерживать другие подключения и запросы к уже подключенным драйверам
 func (m *ConnectionManager) Connect(ctx context.Context, conn models.Connection) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.connect(ctx, conn, nil)
+}
+
+// UseDatabase переключает подключение на базу name. Драйвер, через который
+// уже идут запросы, не меняется: подключается новый драйвер, переключается
+// на name до того, как его получат запросы, и подменяет прежний, как при
+// повторном Connect
+func (m *ConnectionManager) UseDatabase(ctx context.Context, conn models.Connection, name string) error {
+	m.mu.RLock()
+	_, exists := m.drivers[conn.ID]
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("подключение с ID %s не найдено", conn.ID)
+	}
+
+	return m.connect(ctx, conn, func(driver DatabaseDriver) error {
+		switcher, ok := driver.(DatabaseSwitcher)
+		if !ok {
+			return fmt.Errorf("переключение базы не поддерживается для %s", conn.Type)
+		}
+		return switcher.UseDatabase(ctx, name)
+	})
+}
 
+// connect подключает новый драйвер и, если задан prepare, готовит его до
+// того, как драйвер станет доступен запросам
+func (m *ConnectionManager) connect(ctx context.Context, conn models.Connection, prepare func(DatabaseDriver) error) error {
 	driver := m.factory.CreateDriver(conn.Type)
 	if driver == nil {
 		return fmt.Errorf("неподдерживаемый тип БД: %s", conn.Type)
 	}
 
-	if err := driver.Connect(ctx, conn); err != nil {
+	// Ссылки ${NAME} раскрываются только в копии для драйвера
+	m.mu.RLock()
+	envPrefix := m.envPrefix
+	m.mu.RUnlock()
+	resolved, err := conn.ResolveEnv(envPrefix)
+	if err != nil {
+		return fmt.Errorf("ошибка подстановки переменных окружения: %w", err)
+	}
+
+	if err := driver.Connect(ctx, resolved); err != nil {
 		return fmt.Errorf("ошибка подключения: %w", err)
 	}
+	if prepare != nil {
+		if err := prepare(driver); err != nil {
+			driver.Disconnect(context.Background())
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	entry := &driverEntry{driver: driver}
+	limit := conn.MaxConcurrentQueries
+	if limit == 0 {
+		limit = m.queryLimit
+	}
+	if limit > 0 {
+		entry.slots = make(chan struct{}, limit)
+	}
+	previous := m.drivers[conn.ID]
+	m.drivers[conn.ID] = entry
+	m.mu.Unlock()
 
-	m.drivers[conn.ID] = driver
+	// Повторное подключение заменяет драйвер: старый закрывается после
+	// своих запросов, иначе его пул соединений остался бы открытым
+	if previous != nil {
+		go m.closeWhenIdle(conn.ID, previous)
+	}
+
+	m.Touch(conn.ID)
 	return nil
 }
 
+// Disconnect сразу перестает выдавать драйвер новым запросам и ждет
+// завершения уже выполняющихся. Если они не успели за disconnectWait,
+// подключение остается активным и возвращается ErrDriverBusy
 func (m *ConnectionManager) Disconnect(connectionID string) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
-
-	driver, exists := m.drivers[connectionID]
+	entry, exists := m.drivers[connectionID]
 	if !exists {
+		m.mu.Unlock()
 		return fmt.Errorf("подключение с ID %s не найдено", connectionID)
 	}
+	delete(m.drivers, connectionID)
+	m.mu.Unlock()
+
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), disconnectWait)
+	defer cancelWait()
+
+	if !entry.waitIdle(waitCtx) {
+		m.mu.Lock()
+		_, replaced := m.drivers[connectionID]
+		if !replaced {
+			m.drivers[connectionID] = entry
+		}
+		m.mu.Unlock()
+
+		if replaced {
+			// Пока ждали, подключение установили заново: старый драйвер
+			// больше никому не нужен и закрывается после своих запросов
+			go m.closeWhenIdle(connectionID, entry)
+		}
+		return ErrDriverBusy
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := driver.Disconnect(ctx); err != nil {
+	if err := entry.driver.Disconnect(ctx); err != nil {
 		return fmt.Errorf("ошибка отключения: %w", err)
 	}
 
-	delete(m.drivers, connectionID)
+	m.forget(connectionID)
 	return nil
 }
 
-func (m *ConnectionManager) GetDriver(connectionID string) (DatabaseDriver, error) {
+// Reconnect заново устанавливает подключение после обрыва соединения, если
+// оно все еще использует драйвер stale; если драйвер уже заменен другим
+// запросом, ничего не делает. Отключенное пользователем подключение не
+// восстанавливается. Старый драйвер Connect закрывает после его запросов
+func (m *ConnectionManager) Reconnect(ctx context.Context, conn models.Connection, stale DatabaseDriver) error {
+	m.reconnectMu.Lock()
+	defer m.reconnectMu.Unlock()
+
+	m.mu.RLock()
+	entry, exists := m.drivers[conn.ID]
+	m.mu.RUnlock()
+	if !exists {
+		return fmt.Errorf("подключение с ID %s не найдено", conn.ID)
+	}
+	if entry.driver != stale {
+		return nil
+	}
+
+	return m.Connect(ctx, conn)
+}
+
+func (m *ConnectionManager) closeWhenIdle(connectionID string, entry *driverEntry) {
+	entry.waitIdle(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := entry.driver.Disconnect(ctx); err != nil {
+		fmt.Printf("Ошибка отключения подключения %s: %v\n", connectionID, err)
+	}
+}
+
+// AcquireDriver возвращает драйвер подключения и отмечает операцию как
+// выполняющуюся: пока не вызван release, Disconnect не закроет драйвер.
+// release можно вызывать повторно
+func (m *ConnectionManager) AcquireDriver(connectionID string) (DatabaseDriver, func(), error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	driver, exists := m.drivers[connectionID]
+	entry, exists := m.drivers[connectionID]
+	if !exists {
+		return nil, nil, fmt.Errorf("подключение с ID %s не найдено", connectionID)
+	}
+
+	atomic.AddInt64(&entry.active, 1)
+	m.Touch(connectionID)
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			atomic.AddInt64(&entry.active, -1)
+		})
+	}
+	return entry.driver, release, nil
+}
+
+// SetQueryLimit задает общий предел одновременных запросов на подключение
+// (0 - без предела) и сколько запрос ждет свободного слота. Предел из
+// настроек подключения имеет приоритет. Действует для подключений,
+// установленных после вызова
+func (m *ConnectionManager) SetQueryLimit(limit int, queueTimeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.queryLimit = limit
+	m.queueTimeout = queueTimeout
+}
+
+// SetEnvPrefix ограничивает переменные окружения, которые можно подставить
+// в поля подключения через ${NAME}, именами с префиксом; пусто - подстановка
+// запрещена
+func (m *ConnectionManager) SetEnvPrefix(prefix string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.envPrefix = prefix
+}
+
+// AcquireQuerySlot занимает слот запроса подключения. Если все слоты заняты,
+// запрос ждет в очереди не дольше queueTimeout и получает ErrQueryLimit.
+// releaseSlot можно вызывать повторно
+func (m *ConnectionManager) AcquireQuerySlot(ctx context.Context, connectionID string) (func(), error) {
+	m.mu.RLock()
+	entry, exists := m.drivers[connectionID]
+	queueTimeout := m.queueTimeout
+	m.mu.RUnlock()
+
 	if !exists {
 		return nil, fmt.Errorf("подключение с ID %s не найдено", connectionID)
 	}
+	if entry.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case entry.slots <- struct{}{}:
+	default:
+		if queueTimeout <= 0 {
+			return nil, ErrQueryLimit
+		}
 
-	return driver, nil
+		timer := time.NewTimer(queueTimeout)
+		defer timer.Stop()
+
+		select {
+		case entry.slots <- struct{}{}:
+		case <-timer.C:
+			return nil, ErrQueryLimit
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	var once sync.Once
+	releaseSlot := func() {
+		once.Do(func() {
+			<-entry.slots
+		})
+	}
+	return releaseSlot, nil
+}
+
+// Touch отмечает подключение как используемое. Нужен долгим сессиям
+// (терминал), которые выполняют запросы без повторного AcquireDriver
+func (m *ConnectionManager) Touch(connectionID string) {
+	m.usedMu.Lock()
+	m.lastUsed[connectionID] = time.Now()
+	m.usedMu.Unlock()
+}
+
+func (m *ConnectionManager) forget(connectionID string) {
+	m.usedMu.Lock()
+	delete(m.lastUsed, connectionID)
+	m.usedMu.Unlock()
 }
 
 func (m *ConnectionManager) IsConnected(connectionID string) bool {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	driver, exists := m.drivers[connectionID]
+	entry, exists := m.drivers[connectionID]
 	if !exists {
 		return false
 	}
@@ -82,21 +348,142 @@ func (m *ConnectionManager) IsConnected(connectionID string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	return driver.IsConnected(ctx)
+	return entry.driver.IsConnected(ctx)
 }
 
+// ConnectionStatuses проверяет все установленные подключения параллельно
+// и возвращает их состояние по ID. Драйверы проверяются вне блокировки,
+// чтобы медленный ping одного подключения не задерживал остальные запросы
+func (m *ConnectionManager) ConnectionStatuses() map[string]bool {
+	// Проверка тоже операция через драйвер: счетчик не дает закрыть его
+	// посреди ping
+	m.mu.RLock()
+	drivers := make(map[string]DatabaseDriver, len(m.drivers))
+	for id, entry := range m.drivers {
+		atomic.AddInt64(&entry.active, 1)
+		defer atomic.AddInt64(&entry.active, -1)
+		drivers[id] = entry.driver
+	}
+	m.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	statuses := make(map[string]bool, len(drivers))
+	var statusMu sync.Mutex
+	var wg sync.WaitGroup
+	for id, driver := range drivers {
+		wg.Add(1)
+		go func(id string, driver DatabaseDriver) {
+			defer wg.Done()
+			connected := driver.IsConnected(ctx)
+			statusMu.Lock()
+			statuses[id] = connected
+			statusMu.Unlock()
+		}(id, driver)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// ActiveCount возвращает число установленных подключений без их проверки
+func (m *ConnectionManager) ActiveCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.drivers)
+}
+
+const (
+	// restoreWorkers - сколько подключений восстанавливается одновременно
+	restoreWorkers = 8
+	// restoreTimeout ограничивает восстановление целиком: подключения,
+	// до которых не дошла очередь, отмечаются как невосстановленные
+	restoreTimeout = time.Minute
+)
+
+// RestoreConnections переподключает подключения, активные до остановки
+// сервера, параллельно (не больше restoreWorkers одновременно). Ошибка одного
+// подключения не прерывает восстановление остальных; итог по каждому
+// подключению доступен через RestoreReport
 func (m *ConnectionManager) RestoreConnections(ctx context.Context, connections []models.Connection) error {
+	ctx, cancel := context.WithTimeout(ctx, restoreTimeout)
+	defer cancel()
+
+	report := models.RestoreReport{StartedAt: time.Now()}
+
+	pending := make([]models.Connection, 0, len(connections))
 	for _, conn := range connections {
 		if conn.Connected {
-			if err := m.Connect(ctx, conn); err != nil {
-				fmt.Printf("Не удалось восстановить подключение %s: %v\n", conn.ID, err)
-				continue
+			pending = append(pending, conn)
+		}
+	}
+
+	// Результаты пишутся по индексу, поэтому порядок в отчете совпадает
+	// с порядком подключений в конфигурации
+	results := make([]models.RestoreResult, len(pending))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < restoreWorkers && w < len(pending); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = m.restoreConnection(ctx, pending[i])
 			}
+		}()
+	}
+	for i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range results {
+		if result.Restored {
+			report.Restored++
+		} else {
+			report.Failed++
 		}
 	}
+	report.Results = results
+	report.FinishedAt = time.Now()
+	fmt.Printf("Восстановлено подключений: %d, с ошибкой: %d\n", report.Restored, report.Failed)
+
+	m.restoreMu.Lock()
+	m.restoreReport = report
+	m.restoreMu.Unlock()
 	return nil
 }
 
+func (m *ConnectionManager) restoreConnection(ctx context.Context, conn models.Connection) models.RestoreResult {
+	result := models.RestoreResult{ID: conn.ID, Name: conn.Name, Type: conn.Type}
+	if ctx.Err() != nil {
+		result.Error = "истекло время восстановления подключений"
+		return result
+	}
+
+	started := time.Now()
+	if err := m.Connect(ctx, conn); err != nil {
+		fmt.Printf("Не удалось восстановить подключение %s: %v\n", conn.ID, err)
+		result.Error = err.Error()
+	} else {
+		result.Restored = true
+	}
+	result.DurationMs = time.Since(started).Milliseconds()
+	return result
+}
+
+// RestoreReport возвращает итог последнего RestoreConnections
+func (m *ConnectionManager) RestoreReport() models.RestoreReport {
+	m.restoreMu.RLock()
+	defer m.restoreMu.RUnlock()
+
+	report := m.restoreReport
+	report.Results = append([]models.RestoreResult{}, report.Results...)
+	return report
+}
+
 func (m *ConnectionManager) CloseAll() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -104,9 +491,75 @@ func (m *ConnectionManager) CloseAll() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	for id, driver := range m.drivers {
-		driver.Disconnect(ctx)
+	// При остановке сервера ждем выполняющиеся запросы, пока не истек ctx,
+	// затем закрываем драйверы в любом случае
+	for id, entry := range m.drivers {
+		entry.waitIdle(ctx)
+		entry.driver.Disconnect(ctx)
 		delete(m.drivers, id)
+		m.forget(id)
 	}
 }
 
+// DisconnectIdle отключает драйверы, к которым не обращались дольше
+// idleTimeout и через которые сейчас не выполняются запросы, и возвращает
+// ID отключенных подключений
+func (m *ConnectionManager) DisconnectIdle(idleTimeout time.Duration) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m.usedMu.Lock()
+	deadline := time.Now().Add(-idleTimeout)
+	idle := make([]string, 0)
+	for id, entry := range m.drivers {
+		if m.lastUsed[id].Before(deadline) && atomic.LoadInt64(&entry.active) == 0 {
+			idle = append(idle, id)
+		}
+	}
+	m.usedMu.Unlock()
+
+	for _, id := range idle {
+		if err := m.drivers[id].driver.Disconnect(ctx); err != nil {
+			fmt.Printf("Ошибка отключения простаивающего подключения %s: %v\n", id, err)
+		}
+		delete(m.drivers, id)
+		m.forget(id)
+	}
+
+	return idle
+}
+
+// StartIdleSweeper запускает фоновую проверку простаивающих подключений.
+// onDisconnect вызывается для каждого отключенного подключения, чтобы
+// вызывающий код обновил сохраненное состояние. Останавливается с ctx
+func (m *ConnectionManager) StartIdleSweeper(ctx context.Context, idleTimeout time.Duration, onDisconnect func(connectionID string)) {
+	interval := idleTimeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	if interval > time.Minute {
+		interval = time.Minute
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, id := range m.DisconnectIdle(idleTimeout) {
+					if onDisconnect != nil {
+						onDisconnect(id)
+					}
+				}
+			}
+		}
+	}()
+}
+
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"testing"
+	"time"
+)
+
+func TestConnectClosesReplacedDriver(t *testing.T) {
+	ctx := context.Background()
+	m := NewConnectionManager()
+	conn := models.Connection{ID: "1", Type: models.SQLite, Database: ":memory:"}
+
+	if err := m.Connect(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	old, release, err := m.AcquireDriver(conn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Connect(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	current, releaseCurrent, err := m.AcquireDriver(conn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseCurrent()
+	if current == old {
+		t.Fatal("Connect не заменил драйвер")
+	}
+
+	// Пока через старый драйвер выполняется операция, он не закрывается
+	time.Sleep(50 * time.Millisecond)
+	if !old.IsConnected(ctx) {
+		t.Fatal("старый драйвер закрыт до завершения операции")
+	}
+	release()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for old.IsConnected(ctx) {
+		if time.Now().After(deadline) {
+			t.Fatal("старый драйвер не закрыт после замены")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if !current.IsConnected(ctx) {
+		t.Error("новый драйвер должен оставаться подключенным")
+	}
+}
+
+func TestUseDatabaseKeepsDriverOnError(t *testing.T) {
+	ctx := context.Background()
+	m := NewConnectionManager()
+	conn := models.Connection{ID: "1", Type: models.SQLite, Database: ":memory:"}
+
+	if err := m.UseDatabase(ctx, conn, "other"); err == nil {
+		t.Error("UseDatabase без подключения: ожидалась ошибка")
+	}
+
+	if err := m.Connect(ctx, conn); err != nil {
+		t.Fatal(err)
+	}
+	before, release, err := m.AcquireDriver(conn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+
+	// SQLite не переключает базу: новый драйвер закрывается, прежний остается
+	if err := m.UseDatabase(ctx, conn, "other"); err == nil {
+		t.Error("UseDatabase для SQLite: ожидалась ошибка")
+	}
+	after, release, err := m.AcquireDriver(conn.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	if after != before || !after.IsConnected(ctx) {
+		t.Error("после ошибки UseDatabase должен остаться прежний драйвер")
+	}
+}
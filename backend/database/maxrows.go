@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+)
+
+type maxRowsKey struct{}
+
+// WithMaxRows передает драйверу предел строк результата. SQL-драйверы
+// (PostgreSQL и совместимые, ClickHouse, Oracle, SQLite) и Neo4j по Bolt
+// прекращают чтение курсора на пределе; для остальных ответ обрезает
+// TruncateRows
+func WithMaxRows(ctx context.Context, maxRows int) context.Context {
+	if maxRows <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, maxRowsKey{}, maxRows)
+}
+
+// maxRowsFromContext возвращает предел строк; 0 - без предела
+func maxRowsFromContext(ctx context.Context) int {
+	maxRows, _ := ctx.Value(maxRowsKey{}).(int)
+	return maxRows
+}
+
+// TruncateRows оставляет в ответе не больше maxRows строк и отмечает Truncated
+func TruncateRows(resp *models.QueryResponse, maxRows int) {
+	if resp == nil || maxRows <= 0 || len(resp.Rows) <= maxRows {
+		return
+	}
+	resp.Rows = resp.Rows[:maxRows]
+	resp.RowCount = maxRows
+	resp.Truncated = true
+}
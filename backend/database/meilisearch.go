@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -18,8 +19,14 @@ type MeilisearchDriver struct {
 }
 
 func NewMeilisearchDriver() *MeilisearchDriver {
-	return &MeilisearchDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &MeilisearchDriver{}
+}
+
+func (d *MeilisearchDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "json",
+		SupportsCreateDatabase: true,
+		SupportsDeleteTable:    true,
 	}
 }
 
@@ -31,6 +38,12 @@ func (d *MeilisearchDriver) Connect(ctx context.Context, conn models.Connection)
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Meilisearch: %w", err)
 	}
@@ -62,7 +75,7 @@ func (d *MeilisearchDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -96,6 +109,41 @@ func (d *MeilisearchDriver) ExecuteQuery(ctx context.Context, query string) (*mo
 		}, nil
 	}
 
+	return d.search(ctx, index, searchQuery, startTime)
+}
+
+// CompileFilter собирает поисковый запрос с пустым q и строкой filter
+func (d *MeilisearchDriver) CompileFilter(filter models.QueryFilter) (string, error) {
+	if err := validateFilter(filter); err != nil {
+		return "", err
+	}
+	expr, err := compileMeiliFilter(filter)
+	if err != nil {
+		return "", err
+	}
+
+	query := map[string]interface{}{"q": ""}
+	if expr != "" {
+		query["filter"] = expr
+	}
+	return marshalCompiledQuery(query)
+}
+
+func (d *MeilisearchDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	searchQuery := map[string]interface{}{
+		"q":      "",
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	return d.search(ctx, table, searchQuery, time.Now())
+}
+
+func (d *MeilisearchDriver) search(ctx context.Context, index string, searchQuery map[string]interface{}, startTime time.Time) (*models.QueryResponse, error) {
 	url := fmt.Sprintf("%s/indexes/%s/search", d.baseURL, index)
 	body, _ := json.Marshal(searchQuery)
 
@@ -129,19 +177,12 @@ func (d *MeilisearchDriver) ExecuteQuery(ctx context.Context, query string) (*mo
 
 	hits, _ := result["hits"].([]interface{})
 
-	columns := []string{}
+	columns := newColumnSet()
 	rowsData := make([]map[string]interface{}, 0)
 
-	if len(hits) > 0 {
-		if firstHit, ok := hits[0].(map[string]interface{}); ok {
-			for key := range firstHit {
-				columns = append(columns, key)
-			}
-		}
-	}
-
 	for _, hit := range hits {
 		if hitMap, ok := hit.(map[string]interface{}); ok {
+			columns.addKeys(hitMap)
 			row := make(map[string]interface{})
 			for key, value := range hitMap {
 				row[key] = value
@@ -153,13 +194,166 @@ func (d *MeilisearchDriver) ExecuteQuery(ctx context.Context, query string) (*mo
 	executionTime := time.Since(startTime).Milliseconds()
 
 	return &models.QueryResponse{
-		Columns:       columns,
+		Columns:       columns.list(),
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
 	}, nil
 }
 
+// doJSON выполняет запрос к API с JSON телом payload (если не nil)
+// и разбирает ответ в target (если не nil)
+func (d *MeilisearchDriver) doJSON(ctx context.Context, method, path string, payload, target interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		jsonBody, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("ошибка сериализации запроса: %w", err)
+		}
+		body = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if d.conn.Username != "" {
+		req.SetBasicAuth(d.conn.Username, d.conn.Password)
+	}
+
+	var resp *http.Response
+	if method == http.MethodGet {
+		resp, err = doIdempotent(d.client, req)
+	} else {
+		resp, err = d.client.Do(req)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("статус %d, ответ: %s", resp.StatusCode, string(respBody))
+	}
+
+	if target != nil {
+		if err := json.Unmarshal(respBody, target); err != nil {
+			return fmt.Errorf("ошибка парсинга ответа: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetIndexSettings возвращает настройки индекса: searchableAttributes,
+// rankingRules, stopWords и остальные
+func (d *MeilisearchDriver) GetIndexSettings(ctx context.Context, index string) (map[string]interface{}, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var settings map[string]interface{}
+	if err := d.doJSON(ctx, "GET", "/indexes/"+url.PathEscape(index)+"/settings", nil, &settings); err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек индекса: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateIndexSettings частично обновляет настройки индекса. Meilisearch
+// применяет их асинхронно, поэтому возвращается поставленная задача
+func (d *MeilisearchDriver) UpdateIndexSettings(ctx context.Context, index string, settings map[string]interface{}) (*models.AsyncTask, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var task models.AsyncTask
+	if err := d.doJSON(ctx, "PATCH", "/indexes/"+url.PathEscape(index)+"/settings", settings, &task); err != nil {
+		return nil, fmt.Errorf("ошибка обновления настроек индекса: %w", err)
+	}
+	return &task, nil
+}
+
+// InsertRows добавляет документы в индекс; документы с существующим
+// первичным ключом заменяются целиком
+func (d *MeilisearchDriver) InsertRows(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error) {
+	return d.writeDocuments(ctx, "POST", table, rows)
+}
+
+// UpdateRows частично обновляет документы: переданные поля перезаписываются,
+// остальные остаются без изменений
+func (d *MeilisearchDriver) UpdateRows(ctx context.Context, table string, rows []map[string]interface{}) (*models.WriteResult, error) {
+	return d.writeDocuments(ctx, "PUT", table, rows)
+}
+
+func (d *MeilisearchDriver) DeleteRow(ctx context.Context, table, id string) (*models.WriteResult, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var task models.AsyncTask
+	path := fmt.Sprintf("/indexes/%s/documents/%s", url.PathEscape(table), url.PathEscape(id))
+	if err := d.doJSON(ctx, "DELETE", path, nil, &task); err != nil {
+		return nil, fmt.Errorf("ошибка удаления документа: %w", err)
+	}
+	return &models.WriteResult{Task: &task}, nil
+}
+
+func (d *MeilisearchDriver) writeDocuments(ctx context.Context, method, table string, rows []map[string]interface{}) (*models.WriteResult, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var task models.AsyncTask
+	if err := d.doJSON(ctx, method, "/indexes/"+url.PathEscape(table)+"/documents", rows, &task); err != nil {
+		return nil, fmt.Errorf("ошибка записи документов: %w", err)
+	}
+	return &models.WriteResult{Task: &task}, nil
+}
+
+func (d *MeilisearchDriver) GetTaskStatus(ctx context.Context, taskID string) (*models.TaskStatus, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var task struct {
+		UID     int64                  `json:"uid"`
+		Status  string                 `json:"status"`
+		Type    string                 `json:"type"`
+		Details map[string]interface{} `json:"details"`
+		Error   *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := d.doJSON(ctx, "GET", "/tasks/"+url.PathEscape(taskID), nil, &task); err != nil {
+		return nil, fmt.Errorf("ошибка получения статуса задачи: %w", err)
+	}
+
+	status := &models.TaskStatus{
+		ID:     taskID,
+		Status: task.Status,
+		Type:   task.Type,
+	}
+	switch task.Status {
+	case models.TaskSucceeded, models.TaskFailed, models.TaskCanceled:
+		status.Completed = true
+	}
+	if task.Error != nil {
+		status.Error = task.Error.Message
+	}
+	if received, ok := task.Details["receivedDocuments"].(float64); ok {
+		status.Total = int64(received)
+	}
+	if indexed, ok := task.Details["indexedDocuments"].(float64); ok {
+		status.Processed = int64(indexed)
+	}
+
+	return status, nil
+}
+
 func (d *MeilisearchDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
@@ -217,7 +411,7 @@ func (d *MeilisearchDriver) ListDatabases(ctx context.Context) ([]models.Databas
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
@@ -341,7 +535,7 @@ func (d *MeilisearchDriver) ListTables(ctx context.Context) ([]models.TableInfo,
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
 	}
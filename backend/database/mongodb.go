@@ -4,6 +4,10 @@ import (
 	"context"
 	"database-manager/models"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,6 +15,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const mongoDescribeSampleSize = 100
+
 type MongoDBDriver struct {
 	client *mongo.Client
 	conn   models.Connection
@@ -20,15 +26,29 @@ func NewMongoDBDriver() *MongoDBDriver {
 	return &MongoDBDriver{}
 }
 
-func (d *MongoDBDriver) Connect(ctx context.Context, conn models.Connection) error {
-	dsn := fmt.Sprintf("mongodb://%s:%s@%s:%s/%s",
-		conn.Username, conn.Password, conn.Host, conn.Port, conn.Database)
-	
-	if conn.SSL {
-		dsn += "?ssl=true"
+func (d *MongoDBDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "json",
+		SupportsCreateDatabase: true,
+		SupportsRenameDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
+		SupportsRename:         true,
+		SupportsUsers:          true,
 	}
+}
 
-	clientOptions := options.Client().ApplyURI(dsn)
+func (d *MongoDBDriver) Connect(ctx context.Context, conn models.Connection) error {
+	clientOptions := options.Client().ApplyURI(mongoURI(conn))
+	// Частный CA и клиентский сертификат задаются через tls.Config: в URI
+	// можно передать только пути к файлам
+	if conn.CACert != "" || conn.ClientCert != "" {
+		tlsConfig, err := connTLSConfig(conn)
+		if err != nil {
+			return err
+		}
+		clientOptions.SetTLSConfig(tlsConfig)
+	}
 	client, err := mongo.Connect(ctx, clientOptions)
 	if err != nil {
 		return fmt.Errorf("ошибка подключения к MongoDB: %w", err)
@@ -43,6 +63,46 @@ func (d *MongoDBDriver) Connect(ctx context.Context, conn models.Connection) err
 	return nil
 }
 
+// mongoURI возвращает DSN подключения как есть (replica set, mongodb+srv,
+// authSource, readPreference и т.д.), иначе собирает URI из полей.
+// Логин и пароль экранируются, чтобы символы вроде @ и / не ломали URI.
+func mongoURI(conn models.Connection) string {
+	if conn.DSN != "" {
+		return conn.DSN
+	}
+
+	u := url.URL{
+		Scheme: "mongodb",
+		Host:   conn.Host,
+		Path:   "/" + conn.Database,
+	}
+	if conn.Port != "" {
+		u.Host = net.JoinHostPort(conn.Host, conn.Port)
+	}
+	if conn.Username != "" || conn.Password != "" {
+		u.User = url.UserPassword(conn.Username, conn.Password)
+	}
+	if conn.SSL {
+		u.RawQuery = "ssl=true"
+		if conn.SkipTLSVerify {
+			u.RawQuery += "&tlsInsecure=true"
+		}
+	}
+
+	return u.String()
+}
+
+// UseDatabase переключает базу, с которой работают остальные методы;
+// клиент MongoDB не привязан к базе, поэтому переподключение не нужно
+func (d *MongoDBDriver) UseDatabase(ctx context.Context, name string) error {
+	if d.client == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	d.conn.Database = name
+	return nil
+}
+
 func (d *MongoDBDriver) Disconnect(ctx context.Context) error {
 	if d.client != nil {
 		return d.client.Disconnect(ctx)
@@ -70,6 +130,14 @@ func (d *MongoDBDriver) ExecuteQuery(ctx context.Context, query string) (*models
 	}
 
 	startTime := time.Now()
+
+	command, database, isCommand, err := parseMongoCommand(query)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+	if isCommand {
+		return d.runCommand(ctx, command, database, startTime), nil
+	}
 	
 	var filter bson.M
 	if err := bson.UnmarshalExtJSON([]byte(query), true, &filter); err != nil {
@@ -96,20 +164,213 @@ func (d *MongoDBDriver) ExecuteQuery(ctx context.Context, query string) (*models
 		}, nil
 	}
 
-	columns := []string{"_id"}
+	return documentsToResponse(results, startTime), nil
+}
+
+// parseMongoCommand распознает запрос-команду {"command": {...}} с
+// необязательной базой {"database": "admin"}. Команда разбирается в bson.D:
+// имя команды должно остаться первым ключом. Документ с другими ключами или
+// command не-документом - обычный фильтр find (false)
+func parseMongoCommand(query string) (bson.D, string, bool, error) {
+	var doc bson.D
+	if err := bson.UnmarshalExtJSON([]byte(query), true, &doc); err != nil {
+		// Ошибку разбора сообщит обычный путь find
+		return nil, "", false, nil
+	}
+
+	fields := make(map[string]interface{}, len(doc))
+	for _, elem := range doc {
+		if elem.Key != "command" && elem.Key != "database" {
+			return nil, "", false, nil
+		}
+		fields[elem.Key] = elem.Value
+	}
+	command, ok := fields["command"].(bson.D)
+	if !ok {
+		return nil, "", false, nil
+	}
+	if len(command) == 0 {
+		return nil, "", false, fmt.Errorf("command не может быть пустым, например {\"command\": {\"collStats\": \"users\"}}")
+	}
+
+	database := ""
+	if value, ok := fields["database"]; ok {
+		if database, ok = value.(string); !ok {
+			return nil, "", false, fmt.Errorf("database должен быть строкой")
+		}
+	}
+	return command, database, true, nil
+}
+
+// runCommand выполняет команду (collStats, serverStatus, currentOp...) через
+// RunCommand и возвращает ответ сервера одной строкой. База по умолчанию -
+// база подключения
+func (d *MongoDBDriver) runCommand(ctx context.Context, command bson.D, database string, startTime time.Time) *models.QueryResponse {
+	if database == "" {
+		database = d.conn.Database
+	}
+
+	var result bson.M
+	if err := d.client.Database(database).RunCommand(ctx, command).Decode(&result); err != nil {
+		return &models.QueryResponse{Error: err.Error()}
+	}
+
+	columns := newColumnSet()
+	columns.addKeys(result)
+	row := make(map[string]interface{}, len(result))
+	for key, value := range result {
+		row[key] = normalizeBSON(value)
+	}
+	return &models.QueryResponse{
+		Columns:       columns.list(),
+		Rows:          []map[string]interface{}{row},
+		RowCount:      1,
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+	}
+}
+
+// CompileFilter собирает фильтр find в Extended JSON, который принимает ExecuteQuery
+func (d *MongoDBDriver) CompileFilter(filter models.QueryFilter) (string, error) {
+	if err := validateFilter(filter); err != nil {
+		return "", err
+	}
+	return marshalCompiledQuery(compileMongoFilter(filter))
+}
+
+func (d *MongoDBDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	return d.find(ctx, table, bson.M{}, limit, offset)
+}
+
+// DescribeTable определяет поля коллекции по выборке документов,
+// так как у MongoDB нет фиксированной схемы
+func (d *MongoDBDriver) DescribeTable(ctx context.Context, table string) ([]models.TableColumn, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	collection := d.client.Database(d.conn.Database).Collection(table)
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{{{Key: "$sample", Value: bson.M{"size": mongoDescribeSampleSize}}}})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения документов: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("ошибка чтения документов: %w", err)
+	}
+
+	columns := make([]models.TableColumn, 0)
+	seen := make(map[string]int)
+	for _, result := range results {
+		for _, key := range sortedKeys(result) {
+			fieldType := mongoFieldType(result[key])
+			if idx, ok := seen[key]; ok {
+				if columns[idx].Type != fieldType {
+					columns[idx].Type = "mixed"
+				}
+				continue
+			}
+			seen[key] = len(columns)
+			columns = append(columns, models.TableColumn{
+				Name:       key,
+				Type:       fieldType,
+				Nullable:   true,
+				PrimaryKey: key == "_id",
+			})
+		}
+	}
+
+	return columns, nil
+}
+
+// SearchTable ищет документы, у которых хотя бы одно из полей содержит text
+// (регулярное выражение без учета регистра). Если поля не заданы,
+// используются строковые поля, найденные DescribeTable.
+func (d *MongoDBDriver) SearchTable(ctx context.Context, table, text string, columns []string, limit, offset int) (*models.QueryResponse, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	if len(columns) == 0 {
+		described, err := d.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range described {
+			if col.Type == "string" {
+				columns = append(columns, col.Name)
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("в коллекции %s нет строковых полей", table)
+		}
+	}
+
+	conditions := make(bson.A, len(columns))
+	for i, col := range columns {
+		conditions[i] = bson.M{col: bson.M{"$regex": regexp.QuoteMeta(text), "$options": "i"}}
+	}
+
+	return d.find(ctx, table, bson.M{"$or": conditions}, limit, offset)
+}
+
+func (d *MongoDBDriver) find(ctx context.Context, table string, filter bson.M, limit, offset int) (*models.QueryResponse, error) {
+	startTime := time.Now()
+
+	collection := d.client.Database(d.conn.Database).Collection(table)
+	findOptions := options.Find().SetLimit(int64(limit)).SetSkip(int64(offset))
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err := cursor.All(ctx, &results); err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return documentsToResponse(results, startTime), nil
+}
+
+func mongoFieldType(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case int32, int64, float64:
+		return "number"
+	case bool:
+		return "bool"
+	case bson.M, bson.D:
+		return "object"
+	case bson.A:
+		return "array"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func documentsToResponse(results []bson.M, startTime time.Time) *models.QueryResponse {
+	columns := newColumnSet("_id")
 	rowsData := make([]map[string]interface{}, 0)
 	
 	for _, result := range results {
 		row := make(map[string]interface{})
+		columns.addKeys(result)
 		for key, value := range result {
-			if key == "_id" {
-				row["_id"] = value
-			} else {
-				if !contains(columns, key) {
-					columns = append(columns, key)
-				}
-				row[key] = value
-			}
+			row[key] = normalizeBSON(value)
 		}
 		rowsData = append(rowsData, row)
 	}
@@ -117,11 +378,11 @@ func (d *MongoDBDriver) ExecuteQuery(ctx context.Context, query string) (*models
 	executionTime := time.Since(startTime).Milliseconds()
 
 	return &models.QueryResponse{
-		Columns:       columns,
+		Columns:       columns.list(),
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
-	}, nil
+	}
 }
 
 func (d *MongoDBDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
@@ -155,15 +416,24 @@ func (d *MongoDBDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInf
 		var stats bson.M
 		err := db.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats)
 		size := "N/A"
+		var objectCount *int64
 		if err == nil {
 			if dataSize, ok := stats["dataSize"].(int64); ok {
 				size = fmt.Sprintf("%.2f MB", float64(dataSize)/(1024*1024))
 			}
+			switch collections := stats["collections"].(type) {
+			case int32:
+				count := int64(collections)
+				objectCount = &count
+			case int64:
+				objectCount = &collections
+			}
 		}
 
 		result = append(result, models.DatabaseInfo{
-			Name: dbName,
-			Size: size,
+			Name:        dbName,
+			Size:        size,
+			ObjectCount: objectCount,
 		})
 	}
 
@@ -283,6 +553,46 @@ func (d *MongoDBDriver) DeleteTable(ctx context.Context, name string) error {
 	return db.Collection(name).Drop(ctx)
 }
 
+// TruncateTable удаляет все документы, сохраняя коллекцию и ее индексы
+func (d *MongoDBDriver) TruncateTable(ctx context.Context, name string) error {
+	if d.client == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	db := d.client.Database(d.conn.Database)
+	if _, err := db.Collection(name).DeleteMany(ctx, bson.M{}); err != nil {
+		return fmt.Errorf("ошибка очистки коллекции: %w", err)
+	}
+	return nil
+}
+
+// DeleteRows удаляет документы коллекции по фильтру deleteMany в Extended
+// JSON, например {"status": "test"}. Пустой filter удаляет все документы
+func (d *MongoDBDriver) DeleteRows(ctx context.Context, table, filter string, args []interface{}) (int64, error) {
+	if d.client == nil {
+		return 0, fmt.Errorf("подключение не установлено")
+	}
+	if table == "" {
+		return 0, fmt.Errorf("не указана коллекция")
+	}
+	if len(args) > 0 {
+		return 0, fmt.Errorf("MongoDB не поддерживает параметры фильтра: передайте значения в самом фильтре")
+	}
+
+	deleteFilter := bson.M{}
+	if filter != "" {
+		if err := bson.UnmarshalExtJSON([]byte(filter), true, &deleteFilter); err != nil {
+			return 0, fmt.Errorf("ошибка парсинга фильтра: %w", err)
+		}
+	}
+
+	result, err := d.client.Database(d.conn.Database).Collection(table).DeleteMany(ctx, deleteFilter)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления документов: %w", err)
+	}
+	return result.DeletedCount, nil
+}
+
 func (d *MongoDBDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
 	if d.client == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -395,6 +705,40 @@ func (d *MongoDBDriver) ListUsers(ctx context.Context) ([]models.UserInfo, error
 	return users, nil
 }
 
+// ListGrantableRoles возвращает встроенные и пользовательские роли базы
+// подключения (rolesInfo): CreateUser выдает роли в этой базе
+func (d *MongoDBDriver) ListGrantableRoles(ctx context.Context) ([]models.GrantableRole, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	db := d.client.Database(d.conn.Database)
+	command := bson.D{{Key: "rolesInfo", Value: 1}, {Key: "showBuiltinRoles", Value: true}}
+
+	var result bson.M
+	if err := db.RunCommand(ctx, command).Decode(&result); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка ролей: %w", err)
+	}
+
+	roles := make([]models.GrantableRole, 0)
+	if rolesData, ok := result["roles"].(bson.A); ok {
+		for _, roleData := range rolesData {
+			roleMap, ok := roleData.(bson.M)
+			if !ok {
+				continue
+			}
+			name, _ := roleMap["role"].(string)
+			if name == "" {
+				continue
+			}
+			builtIn, _ := roleMap["isBuiltin"].(bool)
+			roles = append(roles, models.GrantableRole{Name: name, Kind: models.GrantKindRole, BuiltIn: builtIn})
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
 func (d *MongoDBDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
 	if d.client == nil {
 		return fmt.Errorf("подключение не установлено")
@@ -457,13 +801,3 @@ func (d *MongoDBDriver) DeleteUser(ctx context.Context, username string) error {
 
 	return nil
 }
-
-func contains(slice []string, item string) bool {
-	for _, s := range slice {
-		if s == item {
-			return true
-		}
-	}
-	return false
-}
-
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mongoUnauthorized - код ошибки MongoDB "Unauthorized"
+const mongoUnauthorized = 13
+
+// ListActivity возвращает операции сервера через агрегацию $currentOp
+// (аналог db.currentOp()): PID - opid, State - active или idle, Query -
+// команда в JSON. Нужна роль clusterMonitor (привилегия inprog). Операции в
+// шардированном кластере со строковым opid вида "shard:123" пропускаются
+func (d *MongoDBDriver) ListActivity(ctx context.Context) ([]models.QueryActivity, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	ops, err := d.currentOps(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := make([]models.QueryActivity, 0, len(ops))
+	for _, op := range ops {
+		pid, ok := mongoOpID(op["opid"])
+		if !ok || isCurrentOpCommand(op["command"]) {
+			continue
+		}
+		activity = append(activity, mongoActivity(op, pid))
+	}
+	return activity, nil
+}
+
+// TerminateBackend прерывает операцию командой killOp. Нужна роль
+// hostManager (привилегия killop)
+func (d *MongoDBDriver) TerminateBackend(ctx context.Context, pid int) error {
+	if d.client == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	// killOp отвечает ok и для несуществующей операции, поэтому она ищется заранее
+	ops, err := d.currentOps(ctx, bson.D{{Key: "opid", Value: pid}})
+	if err != nil {
+		return err
+	}
+	if len(ops) == 0 {
+		return fmt.Errorf("операция %d не найдена", pid)
+	}
+
+	err = d.client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "killOp", Value: 1},
+		{Key: "op", Value: pid},
+	}).Err()
+	if err != nil {
+		return mongoAdminError(fmt.Sprintf("завершения операции %d", pid), "hostManager (killop)", err)
+	}
+	return nil
+}
+
+// currentOps выполняет $currentOp по всем пользователям, без простаивающих
+// соединений, с дополнительным фильтром match
+func (d *MongoDBDriver) currentOps(ctx context.Context, match bson.D) ([]bson.M, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}, {Key: "idleConnections", Value: false}}}},
+	}
+	if len(match) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: match}})
+	}
+
+	cursor, err := d.client.Database("admin").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoAdminError("получения активности", "clusterMonitor (inprog)", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ops []bson.M
+	if err := cursor.All(ctx, &ops); err != nil {
+		return nil, fmt.Errorf("ошибка получения активности: %w", err)
+	}
+	return ops, nil
+}
+
+// mongoAdminError поясняет ошибку прав: какая роль нужна пользователю
+func mongoAdminError(action, role string, err error) error {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(mongoUnauthorized) {
+		return fmt.Errorf("%w: для %s пользователю подключения нужна роль %s: %v", ErrPermissionDenied, action, role, err)
+	}
+	return fmt.Errorf("ошибка %s: %w", action, err)
+}
+
+func mongoActivity(op bson.M, pid int) models.QueryActivity {
+	activity := models.QueryActivity{PID: pid, State: "idle"}
+	if active, _ := op["active"].(bool); active {
+		activity.State = "active"
+	}
+	if ns, ok := op["ns"].(string); ok {
+		activity.Database, _, _ = strings.Cut(ns, ".")
+	}
+	if users, ok := op["effectiveUsers"].(bson.A); ok && len(users) > 0 {
+		if user, ok := users[0].(bson.M); ok {
+			activity.Username, _ = user["user"].(string)
+		}
+	}
+	if command, ok := op["command"].(bson.M); ok {
+		if data, err := bson.MarshalExtJSON(command, false, false); err == nil {
+			activity.Query = string(data)
+		}
+	}
+	if started, ok := op["currentOpTime"].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, started); err == nil {
+			activity.StartedAt = &t
+		}
+	}
+	if micros, ok := mongoOpID(op["microsecs_running"]); ok {
+		activity.DurationMs = int64(micros) / 1000
+	}
+	return activity
+}
+
+// mongoOpID приводит целое число BSON (int32 или int64) к int
+func mongoOpID(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// isCurrentOpCommand - это сама агрегация $currentOp, которая читает активность
+func isCurrentOpCommand(value interface{}) bool {
+	command, ok := value.(bson.M)
+	if !ok {
+		return false
+	}
+	pipeline, ok := command["pipeline"].(bson.A)
+	if !ok || len(pipeline) == 0 {
+		return false
+	}
+	stage, ok := pipeline[0].(bson.M)
+	if !ok {
+		return false
+	}
+	_, ok = stage["$currentOp"]
+	return ok
+}
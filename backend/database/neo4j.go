@@ -9,21 +9,36 @@ import (
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
+// Neo4jDriver работает через Bolt (официальный драйвер) или через
+// транзакционный HTTP API - см. Connection.Neo4jProtocol. При Bolt задан bolt,
+// при HTTP - client и baseURL
 type Neo4jDriver struct {
+	bolt    neo4j.DriverWithContext
 	client  *http.Client
 	baseURL string
 	conn    models.Connection
 }
 
 func NewNeo4jDriver() *Neo4jDriver {
-	return &Neo4jDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &Neo4jDriver{}
+}
+
+func (d *Neo4jDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "cypher",
+		SupportsCreateDatabase: true,
 	}
 }
 
 func (d *Neo4jDriver) Connect(ctx context.Context, conn models.Connection) error {
+	if conn.Neo4jProtocol() == models.Neo4jProtocolBolt {
+		return d.connectBolt(ctx, conn)
+	}
+
 	scheme := "http"
 	if conn.SSL {
 		scheme = "https"
@@ -31,6 +46,12 @@ func (d *Neo4jDriver) Connect(ctx context.Context, conn models.Connection) error
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к Neo4j: %w", err)
 	}
@@ -39,16 +60,24 @@ func (d *Neo4jDriver) Connect(ctx context.Context, conn models.Connection) error
 }
 
 func (d *Neo4jDriver) Disconnect(ctx context.Context) error {
+	if d.bolt != nil {
+		err := d.bolt.Close(ctx)
+		d.bolt = nil
+		return err
+	}
 	d.client = nil
 	d.baseURL = ""
 	return nil
 }
 
 func (d *Neo4jDriver) IsConnected(ctx context.Context) bool {
-	return d.baseURL != "" && d.Ping(ctx) == nil
+	return (d.bolt != nil || d.baseURL != "") && d.Ping(ctx) == nil
 }
 
 func (d *Neo4jDriver) Ping(ctx context.Context) error {
+	if d.bolt != nil {
+		return d.bolt.VerifyConnectivity(ctx)
+	}
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
 	}
@@ -61,7 +90,7 @@ func (d *Neo4jDriver) Ping(ctx context.Context) error {
 
 	d.setAuth(req)
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -88,6 +117,20 @@ func (d *Neo4jDriver) setAuth(req *http.Request) {
 }
 
 func (d *Neo4jDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	return d.executeQuery(ctx, query, false)
+}
+
+// ExecuteQueryGraph выполняет запрос и, кроме строк, возвращает в Graph узлы
+// и связи результата: по Bolt они берутся из значений, по HTTP - из части
+// "graph" ответа
+func (d *Neo4jDriver) ExecuteQueryGraph(ctx context.Context, query string) (*models.QueryResponse, error) {
+	return d.executeQuery(ctx, query, true)
+}
+
+func (d *Neo4jDriver) executeQuery(ctx context.Context, query string, withGraph bool) (*models.QueryResponse, error) {
+	if d.bolt != nil {
+		return d.boltExecuteQuery(ctx, query, withGraph)
+	}
 	if d.baseURL == "" {
 		return nil, fmt.Errorf("подключение не установлено")
 	}
@@ -96,12 +139,14 @@ func (d *Neo4jDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 
 	queryURL := fmt.Sprintf("%s/db/%s/tx/commit", d.baseURL, d.getDatabase())
 
+	statement := map[string]interface{}{
+		"statement": query,
+	}
+	if withGraph {
+		statement["resultDataContents"] = []string{"row", "graph"}
+	}
 	requestBody := map[string]interface{}{
-		"statements": []map[string]interface{}{
-			{
-				"statement": query,
-			},
-		},
+		"statements": []map[string]interface{}{statement},
 	}
 
 	jsonBody, _ := json.Marshal(requestBody)
@@ -132,11 +177,19 @@ func (d *Neo4jDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 
 	columns := []string{}
 	rowsData := make([]map[string]interface{}, 0)
+	graph := newGraphCollector()
 
 	if results, ok := result["results"].([]interface{}); ok && len(results) > 0 {
 		if firstResult, ok := results[0].(map[string]interface{}); ok {
+			if resultColumns, ok := firstResult["columns"].([]interface{}); ok {
+				for _, col := range resultColumns {
+					if name, ok := col.(string); ok {
+						columns = append(columns, name)
+					}
+				}
+			}
 			if data, ok := firstResult["data"].([]interface{}); ok {
-				if len(data) > 0 {
+				if len(columns) == 0 && len(data) > 0 {
 					if firstData, ok := data[0].(map[string]interface{}); ok {
 						if meta, ok := firstData["meta"].([]interface{}); ok {
 							for _, m := range meta {
@@ -161,6 +214,9 @@ func (d *Neo4jDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 							}
 							rowsData = append(rowsData, rowData)
 						}
+						if withGraph {
+							graph.addRESTGraph(dataMap["graph"])
+						}
 					}
 				}
 			}
@@ -181,16 +237,23 @@ func (d *Neo4jDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
+		Graph:         graph.graph(),
 	}, nil
 }
 
 func (d *Neo4jDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
+	query := fmt.Sprintf("CREATE DATABASE %s IF NOT EXISTS", name)
+	if d.bolt != nil {
+		if err := d.boltExec(ctx, neo4jSystemDatabase, query); err != nil {
+			return fmt.Errorf("ошибка создания базы данных: %w", err)
+		}
+		return nil
+	}
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
 	}
 
 	queryURL := fmt.Sprintf("%s/db/neo4j/tx/commit", d.baseURL)
-	query := fmt.Sprintf("CREATE DATABASE %s IF NOT EXISTS", name)
 
 	requestBody := map[string]interface{}{
 		"statements": []map[string]interface{}{
@@ -223,6 +286,9 @@ func (d *Neo4jDriver) CreateDatabase(ctx context.Context, name string, options m
 }
 
 func (d *Neo4jDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
+	if d.bolt != nil {
+		return d.boltListDatabases(ctx)
+	}
 	if d.baseURL == "" {
 		return nil, fmt.Errorf("подключение не установлено")
 	}
@@ -246,7 +312,7 @@ func (d *Neo4jDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo,
 	req.Header.Set("Content-Type", "application/json")
 	d.setAuth(req)
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -285,12 +351,18 @@ func (d *Neo4jDriver) UpdateDatabase(ctx context.Context, oldName, newName strin
 }
 
 func (d *Neo4jDriver) DeleteDatabase(ctx context.Context, name string) error {
+	query := fmt.Sprintf("DROP DATABASE %s IF EXISTS", name)
+	if d.bolt != nil {
+		if err := d.boltExec(ctx, neo4jSystemDatabase, query); err != nil {
+			return fmt.Errorf("ошибка удаления базы данных: %w", err)
+		}
+		return nil
+	}
 	if d.baseURL == "" {
 		return fmt.Errorf("подключение не установлено")
 	}
 
 	queryURL := fmt.Sprintf("%s/db/neo4j/tx/commit", d.baseURL)
-	query := fmt.Sprintf("DROP DATABASE %s IF EXISTS", name)
 
 	requestBody := map[string]interface{}{
 		"statements": []map[string]interface{}{
@@ -327,6 +399,9 @@ func (d *Neo4jDriver) CreateTable(ctx context.Context, name string, columns []mo
 }
 
 func (d *Neo4jDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.bolt != nil {
+		return d.boltListTables(ctx)
+	}
 	if d.baseURL == "" {
 		return nil, fmt.Errorf("подключение не установлено")
 	}
@@ -350,7 +425,7 @@ func (d *Neo4jDriver) ListTables(ctx context.Context) ([]models.TableInfo, error
 	req.Header.Set("Content-Type", "application/json")
 	d.setAuth(req)
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
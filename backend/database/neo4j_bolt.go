@@ -0,0 +1,314 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"fmt"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j/dbtype"
+)
+
+// neo4jSystemDatabase - база, в которой выполняются административные команды
+// (SHOW, CREATE и DROP DATABASE)
+const neo4jSystemDatabase = "system"
+
+// connectBolt подключается по протоколу Bolt через официальный драйвер.
+// Используется прямое подключение (bolt://) к указанному серверу, без
+// маршрутизации по кластеру: адреса узлов кластера часто недоступны снаружи
+func (d *Neo4jDriver) connectBolt(ctx context.Context, conn models.Connection) error {
+	uri := neo4jBoltURI(conn)
+
+	auth := neo4j.NoAuth()
+	if conn.Username != "" {
+		auth = neo4j.BasicAuth(conn.Username, conn.Password, "")
+	}
+
+	var configurers []func(*config.Config)
+	if conn.SSL && (conn.SkipTLSVerify || conn.CACert != "" || conn.ClientCert != "") {
+		tlsConfig, err := connTLSConfig(conn)
+		if err != nil {
+			return err
+		}
+		// Драйвер берет TlsConfig только при шифровании (bolt+s, bolt+ssc);
+		// InsecureSkipVerify он выставляет сам по схеме
+		configurers = append(configurers, func(c *config.Config) {
+			c.TlsConfig = tlsConfig
+		})
+	}
+
+	driver, err := neo4j.NewDriverWithContext(uri, auth, configurers...)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к Neo4j: %w", err)
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return fmt.Errorf("ошибка подключения к Neo4j: %w", err)
+	}
+
+	d.bolt = driver
+	d.conn = conn
+	return nil
+}
+
+// neo4jBoltURI строит адрес Bolt: bolt+s - TLS с проверкой сертификата,
+// bolt+ssc - TLS без проверки (skipTlsVerify), bolt - без шифрования.
+// Схема bolt отключает TLS целиком, включая TlsConfig
+func neo4jBoltURI(conn models.Connection) string {
+	scheme := "bolt"
+	if conn.SSL {
+		scheme = "bolt+s"
+		if conn.SkipTLSVerify {
+			scheme = "bolt+ssc"
+		}
+	}
+	return fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
+}
+
+// boltRun выполняет запрос в автокоммит-транзакции и читает результат не
+// дальше предела maxRows из контекста
+func (d *Neo4jDriver) boltRun(ctx context.Context, database, query string) ([]string, []*neo4j.Record, neo4j.ResultSummary, bool, error) {
+	session := d.bolt.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, nil)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	keys, err := result.Keys()
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	maxRows := maxRowsFromContext(ctx)
+	records := make([]*neo4j.Record, 0)
+	truncated := false
+	for result.Next(ctx) {
+		if maxRows > 0 && len(records) >= maxRows {
+			truncated = true
+			break
+		}
+		records = append(records, result.Record())
+	}
+	if err := result.Err(); err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	summary, err := result.Consume(ctx)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	return keys, records, summary, truncated, nil
+}
+
+func (d *Neo4jDriver) boltExecuteQuery(ctx context.Context, query string, withGraph bool) (*models.QueryResponse, error) {
+	startTime := time.Now()
+
+	keys, records, summary, truncated, err := d.boltRun(ctx, d.getDatabase(), query)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+
+	rowsData := make([]map[string]interface{}, 0, len(records))
+	graph := newGraphCollector()
+	for _, record := range records {
+		rowData := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			if i < len(record.Values) {
+				rowData[key] = normalizeNeo4j(record.Values[i])
+				if withGraph {
+					collectBoltGraph(graph, record.Values[i])
+				}
+			}
+		}
+		rowsData = append(rowsData, rowData)
+	}
+
+	columns := keys
+	if len(columns) == 0 {
+		columns = []string{"result"}
+		rowsData = append(rowsData, map[string]interface{}{
+			"result": "Запрос выполнен успешно",
+		})
+	}
+
+	var graphResult *models.GraphResult
+	if withGraph {
+		graphResult = graph.graph()
+	}
+
+	return &models.QueryResponse{
+		Columns:       columns,
+		Rows:          rowsData,
+		RowCount:      len(rowsData),
+		RowsAffected:  neo4jAffected(summary.Counters()),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+		Truncated:     truncated,
+		Graph:         graphResult,
+	}, nil
+}
+
+// collectBoltGraph добавляет в граф узлы, связи и пути из значения Bolt,
+// в том числе вложенные в списки и словари
+func collectBoltGraph(graph *graphCollector, value interface{}) {
+	switch v := value.(type) {
+	case dbtype.Node:
+		graph.addNode(boltGraphNode(v))
+	case dbtype.Relationship:
+		graph.addRelationship(boltGraphRelationship(v))
+	case dbtype.Path:
+		for _, node := range v.Nodes {
+			graph.addNode(boltGraphNode(node))
+		}
+		for _, rel := range v.Relationships {
+			graph.addRelationship(boltGraphRelationship(rel))
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectBoltGraph(graph, item)
+		}
+	case map[string]interface{}:
+		for _, item := range v {
+			collectBoltGraph(graph, item)
+		}
+	}
+}
+
+func boltGraphNode(node dbtype.Node) models.GraphNode {
+	properties, _ := normalizeNeo4j(node.Props).(map[string]interface{})
+	return models.GraphNode{
+		ID:         node.ElementId,
+		Labels:     node.Labels,
+		Properties: properties,
+	}
+}
+
+func boltGraphRelationship(rel dbtype.Relationship) models.GraphRelationship {
+	properties, _ := normalizeNeo4j(rel.Props).(map[string]interface{})
+	return models.GraphRelationship{
+		ID:         rel.ElementId,
+		Type:       rel.Type,
+		StartNode:  rel.StartElementId,
+		EndNode:    rel.EndElementId,
+		Properties: properties,
+	}
+}
+
+// neo4jAffected - число созданных и удаленных узлов и связей и
+// установленных свойств
+func neo4jAffected(counters neo4j.Counters) int64 {
+	return int64(counters.NodesCreated() + counters.NodesDeleted() +
+		counters.RelationshipsCreated() + counters.RelationshipsDeleted() +
+		counters.PropertiesSet())
+}
+
+func (d *Neo4jDriver) boltExec(ctx context.Context, database, query string) error {
+	_, _, _, _, err := d.boltRun(ctx, database, query)
+	return err
+}
+
+func (d *Neo4jDriver) boltListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
+	_, records, _, _, err := d.boltRun(ctx, neo4jSystemDatabase, "SHOW DATABASES YIELD name RETURN DISTINCT name")
+	if err != nil {
+		return nil, err
+	}
+
+	databases := make([]models.DatabaseInfo, 0, len(records))
+	for _, record := range records {
+		if name, ok := record.Values[0].(string); ok {
+			databases = append(databases, models.DatabaseInfo{Name: name})
+		}
+	}
+	return databases, nil
+}
+
+func (d *Neo4jDriver) boltListTables(ctx context.Context) ([]models.TableInfo, error) {
+	_, records, _, _, err := d.boltRun(ctx, d.getDatabase(), "CALL db.labels()")
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]models.TableInfo, 0, len(records))
+	for _, record := range records {
+		if label, ok := record.Values[0].(string); ok {
+			tables = append(tables, models.TableInfo{
+				Name:     label,
+				Database: d.getDatabase(),
+			})
+		}
+	}
+	return tables, nil
+}
+
+// normalizeNeo4j приводит значения Bolt к JSON: узел - объект с elementId,
+// labels и properties, связь - с type и elementId концов, путь - списки
+// узлов и связей, даты и длительности - строки в формате Cypher, точки -
+// объекты с srid и координатами
+func normalizeNeo4j(value interface{}) interface{} {
+	switch v := value.(type) {
+	case dbtype.Node:
+		return neo4jNode(v)
+	case dbtype.Relationship:
+		return neo4jRelationship(v)
+	case dbtype.Path:
+		nodes := make([]interface{}, len(v.Nodes))
+		for i, node := range v.Nodes {
+			nodes[i] = neo4jNode(node)
+		}
+		relationships := make([]interface{}, len(v.Relationships))
+		for i, rel := range v.Relationships {
+			relationships[i] = neo4jRelationship(rel)
+		}
+		return map[string]interface{}{"nodes": nodes, "relationships": relationships}
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case dbtype.Date:
+		return v.String()
+	case dbtype.LocalTime:
+		return v.String()
+	case dbtype.LocalDateTime:
+		return v.String()
+	case dbtype.Time:
+		return v.String()
+	case dbtype.Duration:
+		return v.String()
+	case dbtype.Point2D:
+		return map[string]interface{}{"srid": v.SpatialRefId, "x": v.X, "y": v.Y}
+	case dbtype.Point3D:
+		return map[string]interface{}{"srid": v.SpatialRefId, "x": v.X, "y": v.Y, "z": v.Z}
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[key] = normalizeNeo4j(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalizeNeo4j(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
+func neo4jNode(node dbtype.Node) map[string]interface{} {
+	return map[string]interface{}{
+		"elementId":  node.ElementId,
+		"labels":     node.Labels,
+		"properties": normalizeNeo4j(node.Props),
+	}
+}
+
+func neo4jRelationship(rel dbtype.Relationship) map[string]interface{} {
+	return map[string]interface{}{
+		"elementId":      rel.ElementId,
+		"type":           rel.Type,
+		"startElementId": rel.StartElementId,
+		"endElementId":   rel.EndElementId,
+		"properties":     normalizeNeo4j(rel.Props),
+	}
+}
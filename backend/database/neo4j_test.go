@@ -0,0 +1,27 @@
+package database
+
+import (
+	"database-manager/models"
+	"testing"
+)
+
+func TestNeo4jBoltURI(t *testing.T) {
+	tests := []struct {
+		conn models.Connection
+		want string
+	}{
+		{models.Connection{Host: "db", Port: "7687"}, "bolt://db:7687"},
+		{models.Connection{Host: "db", Port: "7687", SSL: true}, "bolt+s://db:7687"},
+		{models.Connection{Host: "db", Port: "7687", SSL: true, CACert: "cert"}, "bolt+s://db:7687"},
+		{models.Connection{Host: "db", Port: "7687", SSL: true, ClientCert: "cert", ClientKey: "key"}, "bolt+s://db:7687"},
+		{models.Connection{Host: "db", Port: "7687", SSL: true, SkipTLSVerify: true}, "bolt+ssc://db:7687"},
+		{models.Connection{Host: "db", Port: "7687", SkipTLSVerify: true}, "bolt://db:7687"},
+	}
+
+	for _, tt := range tests {
+		if got := neo4jBoltURI(tt.conn); got != tt.want {
+			t.Errorf("neo4jBoltURI(ssl=%v, skipVerify=%v, caCert=%q, clientCert=%q) = %q, want %q",
+				tt.conn.SSL, tt.conn.SkipTLSVerify, tt.conn.CACert, tt.conn.ClientCert, got, tt.want)
+		}
+	}
+}
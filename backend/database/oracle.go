@@ -0,0 +1,534 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	go_ora "github.com/sijms/go-ora/v2"
+)
+
+// OracleDriver работает через go-ora (чистый Go, без Instant Client).
+// Connection.Database - имя сервиса, Connection.Schema - схема (владелец
+// таблиц), по умолчанию схема пользователя подключения
+type OracleDriver struct {
+	db   *sql.DB
+	conn models.Connection
+}
+
+func NewOracleDriver() *OracleDriver {
+	return &OracleDriver{}
+}
+
+func (d *OracleDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:       "sql",
+		SupportsCreateTable: true,
+		SupportsDeleteTable: true,
+		SupportsRename:      true,
+		SupportsUsers:       true,
+	}
+}
+
+func (d *OracleDriver) Connect(ctx context.Context, conn models.Connection) error {
+	dsn := conn.DSN
+	if dsn == "" {
+		port := 1521
+		if conn.Port != "" {
+			p, err := strconv.Atoi(conn.Port)
+			if err != nil {
+				return fmt.Errorf("неверный порт %q", conn.Port)
+			}
+			port = p
+		}
+
+		options := map[string]string{}
+		if conn.SSL {
+			options["SSL"] = "enable"
+			if conn.SkipTLSVerify {
+				options["SSL VERIFY"] = "false"
+			}
+		}
+		dsn = go_ora.BuildUrl(conn.Host, port, conn.Database, conn.Username, conn.Password, options)
+	}
+
+	db, err := sql.Open("oracle", dsn)
+	if err != nil {
+		return fmt.Errorf("ошибка подключения к Oracle: %w", err)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("ошибка проверки подключения: %w", err)
+	}
+
+	d.db = db
+	d.conn = conn
+	return nil
+}
+
+// schema возвращает владельца таблиц в верхнем регистре, как Oracle
+// хранит имена без кавычек
+func (d *OracleDriver) schema() string {
+	if d.conn.Schema != "" {
+		return strings.ToUpper(d.conn.Schema)
+	}
+	return strings.ToUpper(d.conn.Username)
+}
+
+func (d *OracleDriver) Disconnect(ctx context.Context) error {
+	if d.db != nil {
+		err := d.db.Close()
+		d.db = nil
+		return err
+	}
+	return nil
+}
+
+func (d *OracleDriver) IsConnected(ctx context.Context) bool {
+	if d.db == nil {
+		return false
+	}
+	return d.db.PingContext(ctx) == nil
+}
+
+func (d *OracleDriver) Ping(ctx context.Context) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+	return d.db.PingContext(ctx)
+}
+
+func (d *OracleDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	return runSQLQuery(ctx, d.db, trimOracleStatement(query))
+}
+
+// trimOracleStatement убирает завершающую точку с запятой: Oracle не
+// принимает ее в SQL-запросах, но требует в конце блока PL/SQL (END;)
+func trimOracleStatement(query string) string {
+	query = strings.TrimSpace(query)
+	trimmed := strings.TrimRight(strings.TrimSuffix(query, ";"), " \t\r\n")
+	if trimmed == query {
+		return query
+	}
+
+	words := strings.Fields(strings.ToUpper(trimmed))
+	if len(words) > 0 && words[len(words)-1] == "END" {
+		return query
+	}
+	return trimmed
+}
+
+func (d *OracleDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	quoted, err := quoteOracleTable(table)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT * FROM %s OFFSET %d ROWS FETCH NEXT %d ROWS ONLY", quoted, offset, limit)
+	return d.ExecuteQuery(ctx, query)
+}
+
+// quoteOracleTable заключает в кавычки каждую часть имени schema.table.
+// Имя сравнивается с учетом регистра, как его возвращает ListTables.
+// Кавычки внутри идентификатора Oracle не допускает вовсе
+func quoteOracleTable(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		if part == "" || strings.Contains(part, `"`) {
+			return "", fmt.Errorf("неверное имя таблицы %q", name)
+		}
+		parts[i] = `"` + part + `"`
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// Схемы Oracle существуют только вместе с пользователями, поэтому
+// операции над базами недоступны
+var (
+	errOracleCreateSchema = errors.New("в Oracle схема создается вместе с пользователем: используйте создание пользователя")
+	errOracleRenameSchema = errors.New("Oracle не поддерживает переименование схем")
+	errOracleDropSchema   = errors.New("в Oracle схема удаляется вместе с пользователем: используйте удаление пользователя")
+)
+
+// CreateDatabase: в Oracle схема создается вместе с пользователем
+func (d *OracleDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
+	return errOracleCreateSchema
+}
+
+// ListDatabases возвращает схемы пользователей с числом их таблиц
+func (d *OracleDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := `
+		SELECT u.username,
+			(SELECT count(*) FROM all_tables t WHERE t.owner = u.username) AS object_count
+		FROM all_users u
+		WHERE u.oracle_maintained = 'N'
+		ORDER BY u.username
+	`
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка схем: %w", err)
+	}
+	defer rows.Close()
+
+	databases := make([]models.DatabaseInfo, 0)
+	for rows.Next() {
+		var name string
+		var count int64
+		if err := rows.Scan(&name, &count); err != nil {
+			continue
+		}
+		databases = append(databases, models.DatabaseInfo{
+			Name:        name,
+			Owner:       name,
+			ObjectCount: &count,
+		})
+	}
+
+	return databases, nil
+}
+
+func (d *OracleDriver) UpdateDatabase(ctx context.Context, oldName, newName string, options map[string]interface{}) error {
+	return errOracleRenameSchema
+}
+
+func (d *OracleDriver) DeleteDatabase(ctx context.Context, name string) error {
+	return errOracleDropSchema
+}
+
+func (d *OracleDriver) PlanCreateDatabase(name string, options map[string]interface{}) ([]string, error) {
+	return nil, errOracleCreateSchema
+}
+
+func (d *OracleDriver) PlanUpdateDatabase(oldName, newName string, options map[string]interface{}) ([]string, error) {
+	return nil, errOracleRenameSchema
+}
+
+func (d *OracleDriver) PlanDeleteDatabase(name string) ([]string, error) {
+	return nil, errOracleDropSchema
+}
+
+func (d *OracleDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return d.CreateTableWithConstraints(ctx, name, columns, models.TableConstraints{})
+}
+
+func (d *OracleDriver) CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	query, err := sqlCreateTable(name, columns, constraints)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ошибка создания таблицы: %w", err)
+	}
+	return nil
+}
+
+func (d *OracleDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	// num_rows - оценка по статистике, как reltuples в PostgreSQL
+	query := `
+		SELECT table_name, owner, num_rows
+		FROM all_tables
+		WHERE owner = :1
+		ORDER BY table_name
+	`
+
+	rows, err := d.db.QueryContext(ctx, query, d.schema())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]models.TableInfo, 0)
+	for rows.Next() {
+		var table models.TableInfo
+		var rowCount sql.NullInt64
+		if err := rows.Scan(&table.Name, &table.Database, &rowCount); err != nil {
+			continue
+		}
+		if rowCount.Valid {
+			table.Rows = rowCount.Int64
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+func (d *OracleDriver) DeleteTable(ctx context.Context, name string) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if _, err := d.db.ExecContext(ctx, oracleDropTable(name)); err != nil {
+		return fmt.Errorf("ошибка удаления таблицы: %w", err)
+	}
+	return nil
+}
+
+func oracleDropTable(name string) string {
+	return fmt.Sprintf("DROP TABLE %s CASCADE CONSTRAINTS", name)
+}
+
+// UpdateTable выполняет изменения по одному: DDL в Oracle фиксируется
+// сразу, поэтому общей транзакции, как в PostgreSQL, нет
+func (d *OracleDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	changes, err := planTableChanges(oldName, newName, columns, "ALTER TABLE %s RENAME TO %s", oracleAlterColumn)
+	if err != nil {
+		return err
+	}
+
+	if changes.rename != "" {
+		if _, err := d.db.ExecContext(ctx, changes.rename); err != nil {
+			return fmt.Errorf("ошибка переименования таблицы: %w", err)
+		}
+	}
+
+	for i, col := range columns {
+		if _, err := d.db.ExecContext(ctx, changes.columns[i]); err != nil {
+			return fmt.Errorf("ошибка изменения колонки %s: %w", col.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *OracleDriver) PlanCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) ([]string, error) {
+	query, err := sqlCreateTable(name, columns, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return []string{query}, nil
+}
+
+func (d *OracleDriver) PlanUpdateTable(oldName, newName string, columns []models.TableColumn) ([]string, error) {
+	changes, err := planTableChanges(oldName, newName, columns, "ALTER TABLE %s RENAME TO %s", oracleAlterColumn)
+	if err != nil {
+		return nil, err
+	}
+	return changes.statements(), nil
+}
+
+func (d *OracleDriver) PlanDeleteTable(name string) ([]string, error) {
+	return []string{oracleDropTable(name)}, nil
+}
+
+func oracleAlterColumn(table string, col models.TableColumn) (string, error) {
+	switch columnOperation(col) {
+	case models.ColumnAdd:
+		colDef, err := sqlColumnDefinition(col)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ALTER TABLE %s ADD (%s)", table, colDef), nil
+	case models.ColumnDrop:
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, col.Name), nil
+	case models.ColumnAlter:
+		if col.Type == "" {
+			return "", fmt.Errorf("для изменения колонки %s укажите type", col.Name)
+		}
+		return fmt.Sprintf("ALTER TABLE %s MODIFY (%s %s)", table, col.Name, col.Type), nil
+	case models.ColumnRename:
+		if col.NewName == "" {
+			return "", fmt.Errorf("для переименования колонки %s укажите newName", col.Name)
+		}
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, col.Name, col.NewName), nil
+	}
+	return "", fmt.Errorf("неизвестная операция %q для колонки %s", col.Operation, col.Name)
+}
+
+// oracleQuotePassword заключает пароль в кавычки; двойную кавычку
+// Oracle в пароле не допускает
+func oracleQuotePassword(password string) (string, error) {
+	if strings.Contains(password, `"`) {
+		return "", fmt.Errorf("пароль Oracle не может содержать двойные кавычки")
+	}
+	return `"` + password + `"`, nil
+}
+
+// CreateUser создает пользователя (и одноименную схему) с правом
+// CREATE SESSION и переданными системными привилегиями или ролями
+func (d *OracleDriver) CreateUser(ctx context.Context, username, password, database string, permissions []string) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	quoted, err := oracleQuotePassword(password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("CREATE USER %s IDENTIFIED BY %s", username, quoted)); err != nil {
+		return fmt.Errorf("ошибка создания пользователя: %w", err)
+	}
+
+	grants := append([]string{"CREATE SESSION"}, permissions...)
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("GRANT %s TO %s", strings.Join(grants, ", "), username)); err != nil {
+		return fmt.Errorf("ошибка выдачи прав: %w", err)
+	}
+
+	return nil
+}
+
+func (d *OracleDriver) ListUsers(ctx context.Context) ([]models.UserInfo, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	// dba_* доступны только пользователю с правами администратора
+	query := `
+		SELECT u.username,
+			(SELECT LISTAGG(p.privilege, ',') WITHIN GROUP (ORDER BY p.privilege)
+				FROM dba_sys_privs p WHERE p.grantee = u.username) AS privileges,
+			(SELECT LISTAGG(r.granted_role, ',') WITHIN GROUP (ORDER BY r.granted_role)
+				FROM dba_role_privs r WHERE r.grantee = u.username) AS roles
+		FROM dba_users u
+		WHERE u.oracle_maintained = 'N'
+		ORDER BY u.username
+	`
+
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	users := make([]models.UserInfo, 0)
+	for rows.Next() {
+		var username string
+		var privileges, roles sql.NullString
+		if err := rows.Scan(&username, &privileges, &roles); err != nil {
+			continue
+		}
+
+		permissions := make([]string, 0)
+		isSuperuser := false
+		for _, list := range []sql.NullString{privileges, roles} {
+			if !list.Valid || list.String == "" {
+				continue
+			}
+			for _, name := range strings.Split(list.String, ",") {
+				permissions = append(permissions, name)
+				if name == "DBA" {
+					isSuperuser = true
+				}
+			}
+		}
+
+		users = append(users, models.UserInfo{
+			Username:    username,
+			Permissions: permissions,
+			IsSuperuser: isSuperuser,
+		})
+	}
+
+	return users, nil
+}
+
+// UpdateUser меняет пароль и заменяет набор прав: выданные ранее системные
+// привилегии и роли (кроме CREATE SESSION) отзываются
+func (d *OracleDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if password != "" {
+		quoted, err := oracleQuotePassword(password)
+		if err != nil {
+			return err
+		}
+		if _, err := d.db.ExecContext(ctx, fmt.Sprintf("ALTER USER %s IDENTIFIED BY %s", username, quoted)); err != nil {
+			return fmt.Errorf("ошибка обновления пароля: %w", err)
+		}
+	}
+
+	if permissions == nil {
+		return nil
+	}
+
+	current, err := d.grantedPrivileges(ctx, username)
+	if err != nil {
+		return err
+	}
+	for _, name := range current {
+		if name == "CREATE SESSION" {
+			continue
+		}
+		if _, err := d.db.ExecContext(ctx, fmt.Sprintf("REVOKE %s FROM %s", name, username)); err != nil {
+			return fmt.Errorf("ошибка отзыва права %s: %w", name, err)
+		}
+	}
+
+	if len(permissions) > 0 {
+		if _, err := d.db.ExecContext(ctx, fmt.Sprintf("GRANT %s TO %s", strings.Join(permissions, ", "), username)); err != nil {
+			return fmt.Errorf("ошибка обновления прав: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *OracleDriver) grantedPrivileges(ctx context.Context, username string) ([]string, error) {
+	query := `
+		SELECT privilege FROM dba_sys_privs WHERE grantee = :1
+		UNION ALL
+		SELECT granted_role FROM dba_role_privs WHERE grantee = :2
+	`
+
+	grantee := strings.ToUpper(username)
+	rows, err := d.db.QueryContext(ctx, query, grantee, grantee)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения прав пользователя: %w", err)
+	}
+	defer rows.Close()
+
+	names := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func (d *OracleDriver) DeleteUser(ctx context.Context, username string) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	// CASCADE удаляет и объекты схемы пользователя
+	if _, err := d.db.ExecContext(ctx, fmt.Sprintf("DROP USER %s CASCADE", username)); err != nil {
+		return fmt.Errorf("ошибка удаления пользователя: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database-manager/models"
+	"testing"
+)
+
+func TestTrimOracleStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT * FROM dual;", "SELECT * FROM dual"},
+		{"  SELECT 1 FROM dual ;  \n", "SELECT 1 FROM dual"},
+		{"SELECT 1 FROM dual", "SELECT 1 FROM dual"},
+		{"BEGIN\n  NULL;\nEND;", "BEGIN\n  NULL;\nEND;"},
+		{"begin dbms_stats.gather_table_stats(user, 'T'); end;", "begin dbms_stats.gather_table_stats(user, 'T'); end;"},
+	}
+
+	for _, tt := range tests {
+		if got := trimOracleStatement(tt.query); got != tt.want {
+			t.Errorf("trimOracleStatement(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestOracleAlterColumn(t *testing.T) {
+	tests := []struct {
+		col  models.TableColumn
+		want string
+	}{
+		{models.TableColumn{Name: "status", Type: "VARCHAR2(20)", Default: "'new'"}, "ALTER TABLE orders ADD (status VARCHAR2(20) DEFAULT 'new' NOT NULL)"},
+		{models.TableColumn{Name: "note", Type: "CLOB", Nullable: true}, "ALTER TABLE orders ADD (note CLOB)"},
+		{models.TableColumn{Name: "note", Operation: models.ColumnDrop}, "ALTER TABLE orders DROP COLUMN note"},
+		{models.TableColumn{Name: "total", Type: "NUMBER(12,2)", Operation: models.ColumnAlter}, "ALTER TABLE orders MODIFY (total NUMBER(12,2))"},
+		{models.TableColumn{Name: "total", NewName: "amount", Operation: models.ColumnRename}, "ALTER TABLE orders RENAME COLUMN total TO amount"},
+	}
+
+	for _, tt := range tests {
+		got, err := oracleAlterColumn("orders", tt.col)
+		if err != nil {
+			t.Errorf("oracleAlterColumn(%+v): %v", tt.col, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("oracleAlterColumn(%+v) = %q, want %q", tt.col, got, tt.want)
+		}
+	}
+
+	if _, err := oracleAlterColumn("orders", models.TableColumn{Name: "x", Operation: models.ColumnAlter}); err == nil {
+		t.Error("expected error for alter without type")
+	}
+}
+
+func TestQuoteOracleTable(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"ORDERS", `"ORDERS"`, false},
+		{"HR.Employees", `"HR"."Employees"`, false},
+		{`ORDERS" WHERE 1=1 --`, "", true},
+		{"HR.", "", true},
+		{"ORDERS; DROP TABLE X", `"ORDERS; DROP TABLE X"`, false},
+	}
+
+	for _, tt := range tests {
+		got, err := quoteOracleTable(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("quoteOracleTable(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
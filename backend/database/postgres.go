@@ -5,12 +5,19 @@ import (
 	"crypto/tls"
 	"database/sql"
 	"database-manager/models"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// allSchemas в Connection.Schema означает работу со всеми пользовательскими схемами
+const allSchemas = "*"
+
 type PostgreSQLDriver struct {
 	pool *pgxpool.Pool
 	conn models.Connection
@@ -20,7 +27,107 @@ func NewPostgreSQLDriver() *PostgreSQLDriver {
 	return &PostgreSQLDriver{}
 }
 
+func (d *PostgreSQLDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:          "sql",
+		SupportsCreateDatabase: true,
+		SupportsRenameDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
+		SupportsRename:         true,
+		SupportsUsers:          true,
+	}
+}
+
 func (d *PostgreSQLDriver) Connect(ctx context.Context, conn models.Connection) error {
+	config, err := postgresPoolConfig(conn)
+	if err != nil {
+		return err
+	}
+
+	pool, err := openPostgresPool(ctx, conn, config)
+	if err != nil {
+		return err
+	}
+
+	d.pool = pool
+	d.conn = conn
+	return nil
+}
+
+// UseDatabase переключает подключение на другую базу того же сервера.
+// В PostgreSQL база выбирается при установке соединения, поэтому пул
+// пересоздаётся; при ошибке остаётся прежний
+func (d *PostgreSQLDriver) UseDatabase(ctx context.Context, name string) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	config, err := postgresPoolConfig(d.conn)
+	if err != nil {
+		return err
+	}
+	config.ConnConfig.Database = name
+
+	pool, err := openPostgresPool(ctx, d.conn, config)
+	if err != nil {
+		return err
+	}
+
+	d.pool.Close()
+	d.pool = pool
+	d.conn.Database = name
+	return nil
+}
+
+func openPostgresPool(ctx context.Context, conn models.Connection, config *pgxpool.Config) (*pgxpool.Pool, error) {
+	// Неквалифицированные имена в CreateTable, DropTable и запросах
+	// разрешаются в выбранную схему; public оставляем для расширений
+	if conn.Schema != "" && conn.Schema != allSchemas && conn.Schema != "public" {
+		config.ConnConfig.RuntimeParams["search_path"] = pgx.Identifier{conn.Schema}.Sanitize() + ", public"
+	}
+
+	// Увеличиваем таймауты для медленных подключений, если DSN не задает свой
+	if config.ConnConfig.ConnectTimeout == 0 {
+		config.ConnConfig.ConnectTimeout = 15 * time.Second
+	}
+
+	if err := applyPoolOptions(config, conn.Pool); err != nil {
+		return nil, err
+	}
+
+	cc := config.ConnConfig
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подключения к PostgreSQL: %w (хост=%s, порт=%d, пользователь=%s, база=%s, длина_пароля=%d)", 
+			err, cc.Host, cc.Port, cc.User, cc.Database, len(cc.Password))
+	}
+
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("ошибка ping PostgreSQL: %w (хост=%s, порт=%d, пользователь=%s, база=%s)", 
+			err, cc.Host, cc.Port, cc.User, cc.Database)
+	}
+
+	return pool, nil
+}
+
+// postgresPoolConfig разбирает DSN, если он задан: так из строки подключения
+// управляемых сервисов берутся sslmode, options, application_name и т.д.
+// Иначе конфигурация собирается по отдельным полям подключения.
+func postgresPoolConfig(conn models.Connection) (*pgxpool.Config, error) {
+	if conn.DSN != "" {
+		config, err := pgxpool.ParseConfig(conn.DSN)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка разбора DSN: %w", err)
+		}
+		// caCert и клиентский сертификат подключения дополняют sslmode из DSN
+		if err := applyPostgresTLSFiles(config, conn); err != nil {
+			return nil, err
+		}
+		return config, nil
+	}
+
 	// Используем прямое создание конфигурации вместо DSN строки
 	// чтобы избежать проблем с экранированием паролей со спецсимволами
 	port := conn.Port
@@ -30,13 +137,13 @@ func (d *PostgreSQLDriver) Connect(ctx context.Context, conn models.Connection)
 
 	// Проверяем, что пароль не пустой
 	if conn.Password == "" {
-		return fmt.Errorf("пароль не указан для подключения")
+		return nil, fmt.Errorf("пароль не указан для подключения")
 	}
 
 	// Создаем конфигурацию напрямую
 	config, err := pgxpool.ParseConfig("")
 	if err != nil {
-		return fmt.Errorf("ошибка создания конфигурации: %w", err)
+		return nil, fmt.Errorf("ошибка создания конфигурации: %w", err)
 	}
 
 	// Устанавливаем параметры подключения напрямую
@@ -54,31 +161,96 @@ func (d *PostgreSQLDriver) Connect(ctx context.Context, conn models.Connection)
 	config.ConnConfig.Database = conn.Database
 	
 	if conn.SSL {
-		config.ConnConfig.TLSConfig = &tls.Config{
-			InsecureSkipVerify: false,
+		tlsConfig, err := connTLSConfig(conn)
+		if err != nil {
+			return nil, err
 		}
+		config.ConnConfig.TLSConfig = tlsConfig
 	}
 
-	// Увеличиваем таймауты для медленных подключений
-	config.ConnConfig.ConnectTimeout = 15 * time.Second
+	return config, nil
+}
 
-	pool, err := pgxpool.NewWithConfig(ctx, config)
+func applyPostgresTLSFiles(config *pgxpool.Config, conn models.Connection) error {
+	if config.ConnConfig.TLSConfig == nil {
+		return nil
+	}
+	rootCAs, err := conn.CACertPool()
+	if err != nil {
+		return err
+	}
+	cert, err := conn.ClientCertificate()
 	if err != nil {
-		return fmt.Errorf("ошибка подключения к PostgreSQL: %w (хост=%s, порт=%s, пользователь=%s, база=%s, длина_пароля=%d)", 
-			err, conn.Host, port, conn.Username, conn.Database, len(conn.Password))
+		return err
 	}
 
-	if err := pool.Ping(ctx); err != nil {
-		pool.Close()
-		return fmt.Errorf("ошибка ping PostgreSQL: %w (хост=%s, порт=%s, пользователь=%s, база=%s)", 
-			err, conn.Host, port, conn.Username, conn.Database)
+	tlsConfigs := []*tls.Config{config.ConnConfig.TLSConfig}
+	for _, fallback := range config.ConnConfig.Fallbacks {
+		if fallback.TLSConfig != nil {
+			tlsConfigs = append(tlsConfigs, fallback.TLSConfig)
+		}
+	}
+	for _, tlsConfig := range tlsConfigs {
+		if rootCAs != nil {
+			tlsConfig.RootCAs = rootCAs
+		}
+		if cert != nil {
+			tlsConfig.Certificates = []tls.Certificate{*cert}
+		}
+	}
+	return nil
+}
+
+// applyPoolOptions переопределяет размер пула; без настроек остаются
+// значения pgxpool (или pool_max_conns и т.п. из DSN)
+func applyPoolOptions(config *pgxpool.Config, opts *models.PoolOptions) error {
+	if opts == nil {
+		return nil
+	}
+
+	if opts.MaxConns < 0 || opts.MinConns < 0 {
+		return fmt.Errorf("размер пула не может быть отрицательным")
+	}
+	if opts.MaxConns > 0 {
+		config.MaxConns = opts.MaxConns
+	}
+	if opts.MinConns > 0 {
+		config.MinConns = opts.MinConns
+	}
+	if config.MinConns > config.MaxConns {
+		return fmt.Errorf("minConns (%d) больше maxConns (%d)", config.MinConns, config.MaxConns)
+	}
+
+	if opts.MaxConnLifetime != "" {
+		lifetime, err := time.ParseDuration(opts.MaxConnLifetime)
+		if err != nil || lifetime <= 0 {
+			return fmt.Errorf("неверное значение maxConnLifetime: %q", opts.MaxConnLifetime)
+		}
+		config.MaxConnLifetime = lifetime
 	}
 
-	d.pool = pool
-	d.conn = conn
 	return nil
 }
 
+func (d *PostgreSQLDriver) schema() string {
+	if d.conn.Schema == "" {
+		return "public"
+	}
+	return d.conn.Schema
+}
+
+// splitTable разделяет schema.table; без схемы используется схема подключения
+func (d *PostgreSQLDriver) splitTable(table string) (string, string) {
+	schema, name := d.schema(), table
+	if schema == allSchemas {
+		schema = "public"
+	}
+	if idx := strings.Index(table, "."); idx != -1 {
+		schema, name = table[:idx], table[idx+1:]
+	}
+	return schema, name
+}
+
 func (d *PostgreSQLDriver) Disconnect(ctx context.Context) error {
 	if d.pool != nil {
 		d.pool.Close()
@@ -106,8 +278,145 @@ func (d *PostgreSQLDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	return d.query(ctx, query)
+}
+
+// ExecuteQueryArgs выполняет запрос с плейсхолдерами $1, $2... и привязанными
+// значениями: они передаются серверу отдельно от текста запроса
+func (d *PostgreSQLDriver) ExecuteQueryArgs(ctx context.Context, query string, args []interface{}) (*models.QueryResponse, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	return d.query(ctx, query, postgresArgs(args)...)
+}
+
+// ValidateSessionVars проверяет имена переменных по postgresSessionVars
+func (d *PostgreSQLDriver) ValidateSessionVars(vars map[string]string) error {
+	_, err := validateSessionVars(vars, postgresSessionVars)
+	return err
+}
+
+// ExecuteQueryWithSessionVars выполняет запрос в транзакции, в начале
+// которой переменные задаются через set_config(..., true) - то же, что
+// SET LOCAL, но значение передается параметром. После запроса транзакция
+// фиксируется, и переменные возвращаются к прежним значениям
+func (d *PostgreSQLDriver) ExecuteQueryWithSessionVars(ctx context.Context, query string, args []interface{}, vars map[string]string) (*models.QueryResponse, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	names, err := validateSessionVars(vars, postgresSessionVars)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, name := range names {
+		if _, err := tx.Exec(ctx, "SELECT set_config($1, $2, true)", strings.ToLower(name), vars[name]); err != nil {
+			return &models.QueryResponse{Error: fmt.Sprintf("ошибка установки %s: %v", name, err)}, nil
+		}
+	}
+
+	result, err := runPostgresQuery(ctx, tx, query, postgresArgs(args)...)
+	if err != nil || result.Error != "" {
+		return result, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+	return result, nil
+}
+
+// postgresArgs приводит значения из JSON к типам, которые понимает pgx:
+// json.Number становится int64 или float64 (слишком большие числа остаются
+// строкой и разбираются сервером). Строки pgx передает в текстовом формате,
+// поэтому даты в виде "2024-05-01T10:00:00Z" приводятся к типу параметра на сервере
+func postgresArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		number, ok := arg.(json.Number)
+		if !ok {
+			converted[i] = arg
+			continue
+		}
+		if n, err := number.Int64(); err == nil {
+			converted[i] = n
+		} else if f, err := number.Float64(); err == nil && strings.ContainsAny(number.String(), ".eE") {
+			converted[i] = f
+		} else {
+			converted[i] = number.String()
+		}
+	}
+	return converted
+}
+
+func (d *PostgreSQLDriver) query(ctx context.Context, query string, args ...interface{}) (*models.QueryResponse, error) {
+	return runPostgresQuery(ctx, d.pool, query, args...)
+}
+
+// postgresQuerier - общее у пула и выделенного соединения сессии
+type postgresQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// OpenSession закрепляет за сессией одно соединение пула: SET, временные
+// таблицы и открытые транзакции сохраняются между запросами
+func (d *PostgreSQLDriver) OpenSession(ctx context.Context) (QuerySession, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	conn, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия сессии: %w", err)
+	}
+	return &postgresSession{conn: conn}, nil
+}
+
+type postgresSession struct {
+	conn *pgxpool.Conn
+}
+
+func (s *postgresSession) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	return runPostgresQuery(ctx, s.conn, query)
+}
+
+// Close закрывает соединение, а не возвращает его в пул, чтобы состояние
+// сессии не досталось другим запросам
+func (s *postgresSession) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.conn.Hijack().Close(ctx)
+}
+
+func runPostgresQuery(ctx context.Context, q postgresQuerier, query string, args ...interface{}) (*models.QueryResponse, error) {
 	startTime := time.Now()
-	rows, err := d.pool.Query(ctx, query)
+
+	isWrite := isWriteStatement(query)
+	if isWrite && !hasReturningClause(query) {
+		tag, err := q.Exec(ctx, query, args...)
+		if err != nil {
+			return &models.QueryResponse{
+				Error: err.Error(),
+			}, nil
+		}
+
+		return &models.QueryResponse{
+			Columns:       []string{},
+			Rows:          []map[string]interface{}{},
+			RowsAffected:  tag.RowsAffected(),
+			ExecutionTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	rows, err := q.Query(ctx, query, args...)
 	if err != nil {
 		return &models.QueryResponse{
 			Error: err.Error(),
@@ -121,8 +430,15 @@ func (d *PostgreSQLDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 		columns = append(columns, string(desc.Name))
 	}
 
+	maxRows := maxRowsFromContext(ctx)
+	truncated := false
 	rowsData := make([]map[string]interface{}, 0)
 	for rows.Next() {
+		// Следующая строка есть, а предел достигнут - дальше не читаем
+		if maxRows > 0 && len(rowsData) >= maxRows {
+			truncated = true
+			break
+		}
 		values, err := rows.Values()
 		if err != nil {
 			continue
@@ -139,12 +455,147 @@ func (d *PostgreSQLDriver) ExecuteQuery(ctx context.Context, query string) (*mod
 
 	executionTime := time.Since(startTime).Milliseconds()
 
-	return &models.QueryResponse{
+	response := &models.QueryResponse{
 		Columns:       columns,
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
-	}, nil
+		Truncated:     truncated,
+	}
+	// INSERT ... RETURNING и подобные: число измененных строк берем из итога команды
+	if isWrite {
+		response.RowsAffected = rows.CommandTag().RowsAffected()
+	}
+	return response, nil
+}
+
+func (d *PostgreSQLDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", quotePostgresTable(table), limit, offset)
+	return d.ExecuteQuery(ctx, query)
+}
+
+func (d *PostgreSQLDriver) DescribeTable(ctx context.Context, table string) ([]models.TableColumn, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	schema, name := d.splitTable(table)
+
+	rows, err := d.pool.Query(ctx, `
+		SELECT column_name, data_type, is_nullable = 'YES'
+		FROM information_schema.columns
+		WHERE table_schema = $1 AND table_name = $2
+		ORDER BY ordinal_position
+	`, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения столбцов таблицы: %w", err)
+	}
+	defer rows.Close()
+
+	var columns []models.TableColumn
+	for rows.Next() {
+		var col models.TableColumn
+		if err := rows.Scan(&col.Name, &col.Type, &col.Nullable); err != nil {
+			return nil, fmt.Errorf("ошибка чтения столбца: %w", err)
+		}
+		columns = append(columns, col)
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("таблица %s не найдена", table)
+	}
+	return columns, rows.Err()
+}
+
+// SearchTable ищет строки, в которых хотя бы один из столбцов содержит text
+// (без учета регистра). Если столбцы не заданы, используются все текстовые.
+func (d *PostgreSQLDriver) SearchTable(ctx context.Context, table, text string, columns []string, limit, offset int) (*models.QueryResponse, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	if len(columns) == 0 {
+		described, err := d.DescribeTable(ctx, table)
+		if err != nil {
+			return nil, err
+		}
+		for _, col := range described {
+			if isPostgresTextType(col.Type) {
+				columns = append(columns, col.Name)
+			}
+		}
+		if len(columns) == 0 {
+			return nil, fmt.Errorf("в таблице %s нет текстовых столбцов", table)
+		}
+	}
+
+	conditions := make([]string, len(columns))
+	for i, col := range columns {
+		conditions[i] = fmt.Sprintf("%s::text ILIKE $1", pgx.Identifier{col}.Sanitize())
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s LIMIT %d OFFSET %d",
+		pgx.Identifier(strings.Split(table, ".")).Sanitize(), strings.Join(conditions, " OR "), limit, offset)
+	return d.query(ctx, query, "%"+escapeLikePattern(text)+"%")
+}
+
+func isPostgresTextType(dataType string) bool {
+	switch dataType {
+	case "text", "character varying", "character", "citext", "name", "uuid", "json", "jsonb":
+		return true
+	}
+	return false
+}
+
+// escapeLikePattern экранирует спецсимволы LIKE, чтобы текст искался буквально
+func escapeLikePattern(text string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text)
+}
+
+// Listen выполняет LISTEN на отдельном соединении, изъятом из пула,
+// чтобы подписка не осталась на соединении после возврата в пул
+func (d *PostgreSQLDriver) Listen(ctx context.Context, channel string, notify func(models.Notification) error) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	pooled, err := d.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка получения соединения: %w", err)
+	}
+	conn := pooled.Hijack()
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+		return fmt.Errorf("ошибка подписки на канал %s: %w", channel, err)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("ошибка ожидания уведомления: %w", err)
+		}
+
+		if err := notify(models.Notification{
+			Channel:    n.Channel,
+			Payload:    n.Payload,
+			PID:        n.PID,
+			ReceivedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+	}
 }
 
 func (d *PostgreSQLDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
@@ -152,6 +603,12 @@ func (d *PostgreSQLDriver) CreateDatabase(ctx context.Context, name string, opti
 		return fmt.Errorf("подключение не установлено")
 	}
 
+	_, err := d.pool.Exec(ctx, postgresCreateDatabase(name, options))
+	return err
+}
+
+// postgresCreateDatabase строит CREATE DATABASE с параметрами owner, encoding и locale
+func postgresCreateDatabase(name string, options map[string]interface{}) string {
 	query := fmt.Sprintf("CREATE DATABASE %s", name)
 	
 	if owner, ok := options["owner"].(string); ok && owner != "" {
@@ -166,8 +623,11 @@ func (d *PostgreSQLDriver) CreateDatabase(ctx context.Context, name string, opti
 		query += fmt.Sprintf(" LC_COLLATE = '%s' LC_CTYPE = '%s'", locale, locale)
 	}
 
-	_, err := d.pool.Exec(ctx, query)
-	return err
+	return query
+}
+
+func (d *PostgreSQLDriver) PlanCreateDatabase(name string, options map[string]interface{}) ([]string, error) {
+	return []string{postgresCreateDatabase(name, options)}, nil
 }
 
 func (d *PostgreSQLDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
@@ -175,13 +635,20 @@ func (d *PostgreSQLDriver) ListDatabases(ctx context.Context) ([]models.Database
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	// Таблицы других баз не видны без подключения к ним,
+	// поэтому число объектов считается только для текущей базы
 	query := `
 		SELECT 
 			datname as name,
 			pg_catalog.pg_get_userbyid(datdba) as owner,
 			pg_size_pretty(pg_database_size(datname)) as size,
 			pg_encoding_to_char(encoding) as encoding,
-			datcollate as collation
+			datcollate as collation,
+			CASE WHEN datname = current_database() THEN
+				(SELECT count(*) FROM information_schema.tables
+					WHERE table_type = 'BASE TABLE'
+						AND table_schema NOT IN ('pg_catalog', 'information_schema'))
+			END as object_count
 		FROM pg_catalog.pg_database
 		WHERE datistemplate = false
 		ORDER BY datname
@@ -196,7 +663,7 @@ func (d *PostgreSQLDriver) ListDatabases(ctx context.Context) ([]models.Database
 	databases := make([]models.DatabaseInfo, 0)
 	for rows.Next() {
 		var db models.DatabaseInfo
-		err := rows.Scan(&db.Name, &db.Owner, &db.Size, &db.Encoding, &db.Collation)
+		err := rows.Scan(&db.Name, &db.Owner, &db.Size, &db.Encoding, &db.Collation, &db.ObjectCount)
 		if err != nil {
 			continue
 		}
@@ -211,21 +678,16 @@ func (d *PostgreSQLDriver) UpdateDatabase(ctx context.Context, oldName, newName
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	if newName != "" && newName != oldName {
-		query := fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", oldName, newName)
-		_, err := d.pool.Exec(ctx, query)
+	rename, owner := postgresUpdateDatabase(oldName, newName, options)
+	if rename != "" {
+		_, err := d.pool.Exec(ctx, rename)
 		if err != nil {
 			return fmt.Errorf("ошибка переименования базы данных: %w", err)
 		}
 	}
 
-	if owner, ok := options["owner"].(string); ok && owner != "" {
-		dbName := newName
-		if dbName == "" {
-			dbName = oldName
-		}
-		query := fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", dbName, owner)
-		_, err := d.pool.Exec(ctx, query)
+	if owner != "" {
+		_, err := d.pool.Exec(ctx, owner)
 		if err != nil {
 			return fmt.Errorf("ошибка изменения владельца: %w", err)
 		}
@@ -234,13 +696,41 @@ func (d *PostgreSQLDriver) UpdateDatabase(ctx context.Context, oldName, newName
 	return nil
 }
 
+// postgresUpdateDatabase строит команды переименования и смены владельца;
+// пустая строка - команда не нужна
+func postgresUpdateDatabase(oldName, newName string, options map[string]interface{}) (rename, owner string) {
+	if newName != "" && newName != oldName {
+		rename = fmt.Sprintf("ALTER DATABASE %s RENAME TO %s", oldName, newName)
+	}
+
+	if newOwner, ok := options["owner"].(string); ok && newOwner != "" {
+		dbName := newName
+		if dbName == "" {
+			dbName = oldName
+		}
+		owner = fmt.Sprintf("ALTER DATABASE %s OWNER TO %s", dbName, newOwner)
+	}
+
+	return rename, owner
+}
+
+func (d *PostgreSQLDriver) PlanUpdateDatabase(oldName, newName string, options map[string]interface{}) ([]string, error) {
+	statements := make([]string, 0, 2)
+	rename, owner := postgresUpdateDatabase(oldName, newName, options)
+	for _, query := range []string{rename, owner} {
+		if query != "" {
+			statements = append(statements, query)
+		}
+	}
+	return statements, nil
+}
+
 func (d *PostgreSQLDriver) DeleteDatabase(ctx context.Context, name string) error {
 	if d.pool == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	query := fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)
-	_, err := d.pool.Exec(ctx, query)
+	_, err := d.pool.Exec(ctx, postgresDropDatabase(name))
 	if err != nil {
 		return fmt.Errorf("ошибка удаления базы данных: %w", err)
 	}
@@ -248,43 +738,143 @@ func (d *PostgreSQLDriver) DeleteDatabase(ctx context.Context, name string) erro
 	return nil
 }
 
+func postgresDropDatabase(name string) string {
+	return fmt.Sprintf("DROP DATABASE IF EXISTS %s", name)
+}
+
+func (d *PostgreSQLDriver) PlanDeleteDatabase(name string) ([]string, error) {
+	return []string{postgresDropDatabase(name)}, nil
+}
+
 func (d *PostgreSQLDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return d.CreateTableWithConstraints(ctx, name, columns, models.TableConstraints{})
+}
+
+// CreateTableWithConstraints создает таблицу; первичный ключ (в том числе
+// составной) и ограничения CHECK добавляются на уровне таблицы
+func (d *PostgreSQLDriver) CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error {
+	query, err := d.buildCreateTable(ctx, name, columns, constraints)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.pool.Exec(ctx, query)
+	return err
+}
+
+func (d *PostgreSQLDriver) PlanCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) ([]string, error) {
+	query, err := d.buildCreateTable(ctx, name, columns, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return []string{query}, nil
+}
+
+// buildCreateTable строит CREATE TABLE; внешние ключи проверяются по
+// структуре существующих таблиц, поэтому нужно подключение
+func (d *PostgreSQLDriver) buildCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) (string, error) {
 	if d.pool == nil {
-		return fmt.Errorf("подключение не установлено")
+		return "", fmt.Errorf("подключение не установлено")
 	}
 
 	if len(columns) == 0 {
-		return fmt.Errorf("необходимо указать хотя бы одну колонку")
+		return "", fmt.Errorf("необходимо указать хотя бы одну колонку")
 	}
 
-	cols := make([]string, 0, len(columns))
-	for _, col := range columns {
-		colDef := fmt.Sprintf("  %s %s", col.Name, col.Type)
-		if col.PrimaryKey {
-			colDef += " PRIMARY KEY"
-		}
+	primaryKey, err := tablePrimaryKey(columns, constraints)
+	if err != nil {
+		return "", err
+	}
+
+	if err := d.validateReferences(ctx, name, columns); err != nil {
+		return "", err
+	}
+
+	return postgresCreateTable(name, columns, primaryKey, constraints.Constraints), nil
+}
+
+// postgresCreateTable собирает CREATE TABLE. Имена таблицы и столбцов
+// экранируются так же, как в DropTable, TruncateTable и UpdateTable
+func postgresCreateTable(name string, columns []models.TableColumn, primaryKey, checks []string) string {
+	cols := make([]string, 0, len(columns)+len(checks)+1)
+	for _, col := range columns {
+		colDef := fmt.Sprintf("  %s %s", pgx.Identifier{col.Name}.Sanitize(), col.Type)
 		if !col.Nullable {
 			colDef += " NOT NULL"
 		}
 		if col.Unique && !col.PrimaryKey {
 			colDef += " UNIQUE"
 		}
+		if col.Default != "" {
+			colDef += " DEFAULT " + col.Default
+		}
+		if col.References != "" {
+			refTable, refColumn, _ := parseColumnReference(col.References)
+			colDef += fmt.Sprintf(" REFERENCES %s (%s)", quotePostgresTable(refTable), pgx.Identifier{refColumn}.Sanitize())
+		}
 		cols = append(cols, colDef)
 	}
+	if len(primaryKey) > 0 {
+		quoted := make([]string, len(primaryKey))
+		for i, column := range primaryKey {
+			quoted[i] = pgx.Identifier{column}.Sanitize()
+		}
+		cols = append(cols, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(quoted, ", ")))
+	}
+	for _, check := range checks {
+		cols = append(cols, fmt.Sprintf("  CHECK (%s)", check))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n)", quotePostgresTable(name), strings.Join(cols, ",\n"))
+}
 
-	var query string
-	if len(cols) == 1 {
-		query = fmt.Sprintf("CREATE TABLE %s (\n%s\n)", name, cols[0])
-	} else {
-		query = fmt.Sprintf("CREATE TABLE %s (\n%s", name, cols[0])
-		for i := 1; i < len(cols); i++ {
-			query += ",\n" + cols[i]
+// validateReferences проверяет, что столбцы из References существуют.
+// Ссылка на создаваемую таблицу проверяется по списку ее столбцов
+func (d *PostgreSQLDriver) validateReferences(ctx context.Context, table string, columns []models.TableColumn) error {
+	for _, col := range columns {
+		if col.References == "" {
+			continue
+		}
+
+		refTable, refColumn, err := parseColumnReference(col.References)
+		if err != nil {
+			return fmt.Errorf("колонка %s: %w", col.Name, err)
+		}
+
+		var refColumns []models.TableColumn
+		if refTable == table {
+			refColumns = columns
+		} else if refColumns, err = d.DescribeTable(ctx, refTable); err != nil {
+			return fmt.Errorf("колонка %s ссылается на %s: %w", col.Name, col.References, err)
+		}
+
+		found := false
+		for _, c := range refColumns {
+			if c.Name == refColumn {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("колонка %s ссылается на несуществующий столбец %s", col.Name, col.References)
 		}
-		query += "\n)"
 	}
+	return nil
+}
 
-	_, err := d.pool.Exec(ctx, query)
-	return err
+// parseColumnReference разбирает ссылку вида table(column) или schema.table(column)
+func parseColumnReference(ref string) (string, string, error) {
+	open := strings.Index(ref, "(")
+	if open <= 0 || !strings.HasSuffix(ref, ")") {
+		return "", "", fmt.Errorf("ссылка %q должна иметь вид table(column)", ref)
+	}
+
+	table := strings.TrimSpace(ref[:open])
+	column := strings.TrimSpace(ref[open+1 : len(ref)-1])
+	if table == "" || column == "" || strings.ContainsAny(column, "(),") {
+		return "", "", fmt.Errorf("ссылка %q должна иметь вид table(column)", ref)
+	}
+	return table, column, nil
 }
 
 func (d *PostgreSQLDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
@@ -292,19 +882,25 @@ func (d *PostgreSQLDriver) ListTables(ctx context.Context) ([]models.TableInfo,
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	// При Schema = "*" возвращаем таблицы всех пользовательских схем
+	// с именами вида schema.table
 	query := `
 		SELECT 
-			t.table_name,
+			CASE WHEN $1 = '*' THEN t.table_schema || '.' || t.table_name ELSE t.table_name END,
 			current_database() as database_name,
 			pg_size_pretty(pg_total_relation_size(quote_ident(t.table_schema)||'.'||quote_ident(t.table_name))) as size,
-			(SELECT reltuples::bigint FROM pg_class WHERE relname = t.table_name) as row_count
+			(SELECT c.reltuples::bigint FROM pg_class c
+				JOIN pg_namespace n ON n.oid = c.relnamespace
+				WHERE c.relname = t.table_name AND n.nspname = t.table_schema) as row_count
 		FROM information_schema.tables t
-		WHERE t.table_schema = 'public'
-			AND t.table_type = 'BASE TABLE'
-		ORDER BY t.table_name
+		WHERE t.table_type = 'BASE TABLE'
+			AND (t.table_schema = $1 OR ($1 = '*'
+				AND t.table_schema NOT IN ('pg_catalog', 'information_schema')
+				AND t.table_schema NOT LIKE 'pg_toast%'))
+		ORDER BY t.table_schema, t.table_name
 	`
 
-	rows, err := d.pool.Query(ctx, query)
+	rows, err := d.pool.Query(ctx, query, d.schema())
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
 	}
@@ -341,13 +937,29 @@ func (d *PostgreSQLDriver) ListTables(ctx context.Context) ([]models.TableInfo,
 	return tables, nil
 }
 
+func (d *PostgreSQLDriver) CountRows(ctx context.Context, table string) (int64, error) {
+	if d.pool == nil {
+		return 0, fmt.Errorf("подключение не установлено")
+	}
+
+	var count int64
+	query := "SELECT count(*) FROM " + pgx.Identifier(strings.Split(table, ".")).Sanitize()
+	if err := d.pool.QueryRow(ctx, query).Scan(&count); err != nil {
+		return 0, fmt.Errorf("ошибка подсчета строк таблицы %s: %w", table, err)
+	}
+	return count, nil
+}
+
 func (d *PostgreSQLDriver) DeleteTable(ctx context.Context, name string) error {
+	return d.DropTable(ctx, name, true)
+}
+
+func (d *PostgreSQLDriver) DropTable(ctx context.Context, name string, cascade bool) error {
 	if d.pool == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	query := fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", name)
-	_, err := d.pool.Exec(ctx, query)
+	_, err := d.pool.Exec(ctx, postgresDropTable(name, cascade))
 	if err != nil {
 		return fmt.Errorf("ошибка удаления таблицы: %w", err)
 	}
@@ -355,66 +967,211 @@ func (d *PostgreSQLDriver) DeleteTable(ctx context.Context, name string) error {
 	return nil
 }
 
+func postgresDropTable(name string, cascade bool) string {
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", quotePostgresTable(name))
+	if cascade {
+		query += " CASCADE"
+	}
+	return query
+}
+
+// PlanDeleteTable - команда DeleteTable, то есть удаление с CASCADE
+func (d *PostgreSQLDriver) PlanDeleteTable(name string) ([]string, error) {
+	return []string{postgresDropTable(name, true)}, nil
+}
+
+func (d *PostgreSQLDriver) TruncateTable(ctx context.Context, name string) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	query := "TRUNCATE TABLE " + pgx.Identifier(strings.Split(name, ".")).Sanitize()
+	if _, err := d.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка очистки таблицы: %w", err)
+	}
+	return nil
+}
+
+// DeleteRows удаляет строки по условию WHERE с параметрами $1, $2...
+// Пустой filter удаляет все строки таблицы
+func (d *PostgreSQLDriver) DeleteRows(ctx context.Context, table, filter string, args []interface{}) (int64, error) {
+	if d.pool == nil {
+		return 0, fmt.Errorf("подключение не установлено")
+	}
+	if table == "" {
+		return 0, fmt.Errorf("не указана таблица")
+	}
+
+	query := "DELETE FROM " + quotePostgresTable(table)
+	if filter != "" {
+		query += " WHERE " + filter
+	}
+
+	tag, err := d.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка удаления строк: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (d *PostgreSQLDriver) TableDependencies(ctx context.Context) (map[string][]string, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := `
+		SELECT conrelid::regclass::text, confrelid::regclass::text
+		FROM pg_catalog.pg_constraint
+		WHERE contype = 'f'
+	`
+
+	rows, err := d.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения внешних ключей: %w", err)
+	}
+	defer rows.Close()
+
+	deps := make(map[string][]string)
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			continue
+		}
+		deps[child] = append(deps[child], parent)
+	}
+
+	return deps, nil
+}
+
+// UpdateTable переименовывает таблицу и применяет операции над столбцами
+// (Operation: add по умолчанию, drop, alter - смена типа, rename) в одной
+// транзакции: при ошибке ни одно изменение не сохраняется
 func (d *PostgreSQLDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
 	if d.pool == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	if newName != "" && newName != oldName {
-		query := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", oldName, newName)
-		_, err := d.pool.Exec(ctx, query)
+	changes, err := postgresTableChanges(oldName, newName, columns)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if changes.rename != "" {
+		_, err := tx.Exec(ctx, changes.rename)
 		if err != nil {
 			return fmt.Errorf("ошибка переименования таблицы: %w", err)
 		}
-		oldName = newName
 	}
 
-	if len(columns) > 0 {
-		for _, col := range columns {
-			colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
-			if col.PrimaryKey {
-				colDef += " PRIMARY KEY"
-			}
-			if !col.Nullable {
-				colDef += " NOT NULL"
-			}
-			if col.Unique && !col.PrimaryKey {
-				colDef += " UNIQUE"
-			}
-
-			query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", oldName, colDef)
-			_, err := d.pool.Exec(ctx, query)
-			if err != nil {
-				return fmt.Errorf("ошибка добавления колонки %s: %w", col.Name, err)
-			}
+	for i, col := range columns {
+		if _, err := tx.Exec(ctx, changes.columns[i]); err != nil {
+			return fmt.Errorf("ошибка изменения колонки %s (%s): %w", col.Name, columnOperation(col), err)
 		}
 	}
 
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка сохранения изменений таблицы: %w", err)
+	}
 	return nil
 }
 
+func (d *PostgreSQLDriver) PlanUpdateTable(oldName, newName string, columns []models.TableColumn) ([]string, error) {
+	changes, err := postgresTableChanges(oldName, newName, columns)
+	if err != nil {
+		return nil, err
+	}
+	return changes.statements(), nil
+}
+
+func columnOperation(col models.TableColumn) string {
+	if col.Operation == "" {
+		return models.ColumnAdd
+	}
+	return col.Operation
+}
+
+// postgresTableChanges планирует изменения таблицы с экранированием имен
+// при переименовании
+func postgresTableChanges(oldName, newName string, columns []models.TableColumn) (tableChanges, error) {
+	changes, err := planTableChanges(oldName, newName, columns, "ALTER TABLE %s RENAME TO %s", postgresAlterColumn)
+	if err != nil {
+		return tableChanges{}, err
+	}
+	if changes.rename != "" {
+		changes.rename = fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quotePostgresTable(oldName), quotePostgresTable(newName))
+	}
+	return changes, nil
+}
+
+// postgresAlterColumn строит ALTER TABLE для одной операции над столбцом.
+// Добавление сохраняет прежний формат определения столбца
+func postgresAlterColumn(table string, col models.TableColumn) (string, error) {
+	column := pgx.Identifier{col.Name}.Sanitize()
+
+	switch columnOperation(col) {
+	case models.ColumnAdd:
+		colDef := fmt.Sprintf("%s %s", column, col.Type)
+		if col.PrimaryKey {
+			colDef += " PRIMARY KEY"
+		}
+		if !col.Nullable {
+			colDef += " NOT NULL"
+		}
+		if col.Unique && !col.PrimaryKey {
+			colDef += " UNIQUE"
+		}
+		return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", quotePostgresTable(table), colDef), nil
+	case models.ColumnDrop:
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quotePostgresTable(table), column), nil
+	case models.ColumnAlter:
+		if col.Type == "" {
+			return "", fmt.Errorf("для смены типа колонки %s укажите type", col.Name)
+		}
+		return fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s",
+			quotePostgresTable(table), column, col.Type, column, col.Type), nil
+	case models.ColumnRename:
+		if col.NewName == "" {
+			return "", fmt.Errorf("для переименования колонки %s укажите newName", col.Name)
+		}
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s",
+			quotePostgresTable(table), column, pgx.Identifier{col.NewName}.Sanitize()), nil
+	}
+	return "", fmt.Errorf("неизвестная операция %q для колонки %s: допустимы add, drop, alter, rename", col.Operation, col.Name)
+}
+
+// quotePostgresLiteral записывает строку как литерал в одинарных кавычках
+func quotePostgresLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func quotePostgresTable(table string) string {
+	return pgx.Identifier(strings.Split(table, ".")).Sanitize()
+}
+
 func (d *PostgreSQLDriver) CreateUser(ctx context.Context, username, password, database string, permissions []string) error {
 	if d.pool == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	createUserQuery := fmt.Sprintf("CREATE USER %s WITH PASSWORD '%s'", username, password)
-	_, err := d.pool.Exec(ctx, createUserQuery)
+	grants, err := parsePostgresPermissions(permissions)
+	if err != nil {
+		return err
+	}
+
+	createUserQuery := fmt.Sprintf("CREATE USER %s WITH PASSWORD %s", pgx.Identifier{username}.Sanitize(), quotePostgresLiteral(password))
+	_, err = d.pool.Exec(ctx, createUserQuery)
 	if err != nil {
 		return fmt.Errorf("ошибка создания пользователя: %w", err)
 	}
 
-	if len(permissions) > 0 {
-		grantQuery := fmt.Sprintf("GRANT %s TO %s", permissions[0], username)
-		if len(permissions) > 1 {
-			permsStr := permissions[0]
-			for i := 1; i < len(permissions); i++ {
-				permsStr += ", " + permissions[i]
-			}
-			grantQuery = fmt.Sprintf("GRANT %s TO %s", permsStr, username)
-		}
-		_, err = d.pool.Exec(ctx, grantQuery)
-		if err != nil {
+	for _, grant := range grants {
+		if _, err := d.pool.Exec(ctx, grant.grantSQL(username)); err != nil {
 			return fmt.Errorf("ошибка выдачи прав: %w", err)
 		}
 	}
@@ -469,13 +1226,64 @@ func (d *PostgreSQLDriver) ListUsers(ctx context.Context) ([]models.UserInfo, er
 	return users, nil
 }
 
+// SearchUsers - ListUsers с поиском по имени (rolname ILIKE) и пагинацией
+// на стороне сервера. Total - число ролей с правом входа, подходящих под поиск
+func (d *PostgreSQLDriver) SearchUsers(ctx context.Context, filter models.UserFilter) (*models.UserList, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	pattern := "%" + escapeLikePattern(filter.Search) + "%"
+	var total int
+	err := d.pool.QueryRow(ctx, "SELECT count(*) FROM pg_catalog.pg_roles WHERE rolcanlogin AND rolname ILIKE $1", pattern).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подсчета пользователей: %w", err)
+	}
+
+	// LIMIT NULL - без ограничения
+	var limit interface{}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	query := `
+		SELECT
+			rolname,
+			rolsuper,
+			ARRAY(
+				SELECT b.rolname
+				FROM pg_catalog.pg_auth_members m
+				JOIN pg_catalog.pg_roles b ON (m.roleid = b.oid)
+				WHERE m.member = r.oid
+			)
+		FROM pg_catalog.pg_roles r
+		WHERE rolcanlogin AND rolname ILIKE $1
+		ORDER BY rolname
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := d.pool.Query(ctx, query, pattern, limit, filter.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка пользователей: %w", err)
+	}
+	defer rows.Close()
+
+	list := &models.UserList{Users: []models.UserInfo{}, Total: total}
+	for rows.Next() {
+		var user models.UserInfo
+		if err := rows.Scan(&user.Username, &user.IsSuperuser, &user.Permissions); err != nil {
+			return nil, fmt.Errorf("ошибка чтения пользователя: %w", err)
+		}
+		list.Users = append(list.Users, user)
+	}
+	return list, rows.Err()
+}
+
 func (d *PostgreSQLDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
 	if d.pool == nil {
 		return fmt.Errorf("подключение не установлено")
 	}
 
 	if password != "" {
-		alterQuery := fmt.Sprintf("ALTER USER %s WITH PASSWORD '%s'", username, password)
+		alterQuery := fmt.Sprintf("ALTER USER %s WITH PASSWORD %s", pgx.Identifier{username}.Sanitize(), quotePostgresLiteral(password))
 		_, err := d.pool.Exec(ctx, alterQuery)
 		if err != nil {
 			return fmt.Errorf("ошибка обновления пароля: %w", err)
@@ -483,22 +1291,94 @@ func (d *PostgreSQLDriver) UpdateUser(ctx context.Context, username, password st
 	}
 
 	if permissions != nil {
-		revokeQuery := fmt.Sprintf("REVOKE ALL PRIVILEGES ON DATABASE %s FROM %s", d.conn.Database, username)
-		d.pool.Exec(ctx, revokeQuery)
+		if err := d.replaceUserGrants(ctx, username, permissions); err != nil {
+			return err
+		}
+	}
 
-		if len(permissions) > 0 {
-			permsStr := permissions[0]
-			for i := 1; i < len(permissions); i++ {
-				permsStr += ", " + permissions[i]
-			}
-			grantQuery := fmt.Sprintf("GRANT %s TO %s", permsStr, username)
-			_, err := d.pool.Exec(ctx, grantQuery)
-			if err != nil {
-				return fmt.Errorf("ошибка обновления прав: %w", err)
-			}
+	return nil
+}
+
+// replaceUserGrants приводит права пользователя к списку permissions одной
+// транзакцией. Членство в ролях заменяется целиком: роли не из списка
+// отзываются. Привилегии на объекты заменяются только для объектов из
+// списка (REVOKE ALL на объект, затем GRANT); права на остальные объекты
+// не меняются
+func (d *PostgreSQLDriver) replaceUserGrants(ctx context.Context, username string, permissions []string) error {
+	grants, err := parsePostgresPermissions(permissions)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// $1::regrole разбирает имя как идентификатор, поэтому передается в кавычках,
+	// как в CREATE USER
+	rows, err := tx.Query(ctx, `
+		SELECT b.rolname
+		FROM pg_catalog.pg_auth_members m
+		JOIN pg_catalog.pg_roles b ON m.roleid = b.oid
+		WHERE m.member = $1::regrole`, pgx.Identifier{username}.Sanitize())
+	if err != nil {
+		return fmt.Errorf("ошибка получения ролей пользователя: %w", err)
+	}
+	var currentRoles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			rows.Close()
+			return fmt.Errorf("ошибка чтения ролей пользователя: %w", err)
+		}
+		currentRoles = append(currentRoles, role)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ошибка получения ролей пользователя: %w", err)
+	}
+
+	wantedRoles := make(map[string]bool)
+	for _, grant := range grants {
+		if grant.Role != "" {
+			wantedRoles[grant.Role] = true
+		}
+	}
+	for _, role := range currentRoles {
+		if wantedRoles[role] {
+			continue
+		}
+		if _, err := tx.Exec(ctx, postgresGrant{Role: role}.revokeSQL(username)); err != nil {
+			return fmt.Errorf("ошибка отзыва роли %s: %w", role, err)
 		}
 	}
 
+	revoked := make(map[string]bool)
+	for _, grant := range grants {
+		if grant.Role != "" {
+			continue
+		}
+		revoke := grant.revokeSQL(username)
+		if revoked[revoke] {
+			continue
+		}
+		revoked[revoke] = true
+		if _, err := tx.Exec(ctx, revoke); err != nil {
+			return fmt.Errorf("ошибка отзыва прав: %w", err)
+		}
+	}
+
+	for _, grant := range grants {
+		if _, err := tx.Exec(ctx, grant.grantSQL(username)); err != nil {
+			return fmt.Errorf("ошибка обновления прав: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("ошибка сохранения прав: %w", err)
+	}
 	return nil
 }
 
@@ -507,7 +1387,7 @@ func (d *PostgreSQLDriver) DeleteUser(ctx context.Context, username string) erro
 		return fmt.Errorf("подключение не установлено")
 	}
 
-	dropQuery := fmt.Sprintf("DROP USER IF EXISTS %s", username)
+	dropQuery := fmt.Sprintf("DROP USER IF EXISTS %s", pgx.Identifier{username}.Sanitize())
 	_, err := d.pool.Exec(ctx, dropQuery)
 	if err != nil {
 		return fmt.Errorf("ошибка удаления пользователя: %w", err)
@@ -516,3 +1396,259 @@ func (d *PostgreSQLDriver) DeleteUser(ctx context.Context, username string) erro
 	return nil
 }
 
+
+// ListActivity возвращает клиентские сессии из pg_stat_activity, кроме текущей
+func (d *PostgreSQLDriver) ListActivity(ctx context.Context) ([]models.QueryActivity, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := `
+		SELECT
+			pid,
+			COALESCE(usename, ''),
+			COALESCE(datname, ''),
+			COALESCE(state, ''),
+			COALESCE(query, ''),
+			query_start,
+			COALESCE(EXTRACT(EPOCH FROM (now() - query_start)) * 1000, 0)::bigint
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend'
+			AND pid <> pg_backend_pid()
+		ORDER BY query_start NULLS LAST
+	`
+
+	rows, err := d.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения активности: %w", err)
+	}
+	defer rows.Close()
+
+	activity := make([]models.QueryActivity, 0)
+	for rows.Next() {
+		var a models.QueryActivity
+		if err := rows.Scan(&a.PID, &a.Username, &a.Database, &a.State, &a.Query, &a.StartedAt, &a.DurationMs); err != nil {
+			continue
+		}
+		activity = append(activity, a)
+	}
+
+	return activity, nil
+}
+
+func (d *PostgreSQLDriver) TerminateBackend(ctx context.Context, pid int) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	var terminated bool
+	if err := d.pool.QueryRow(ctx, "SELECT pg_terminate_backend($1)", pid).Scan(&terminated); err != nil {
+		return fmt.Errorf("ошибка завершения процесса %d: %w", pid, err)
+	}
+	if !terminated {
+		return fmt.Errorf("процесс %d не найден", pid)
+	}
+
+	return nil
+}
+
+func (d *PostgreSQLDriver) ListSettings(ctx context.Context) ([]models.ServerSetting, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := `
+		SELECT 
+			name,
+			COALESCE(setting, ''),
+			COALESCE(boot_val, ''),
+			COALESCE(unit, ''),
+			COALESCE(short_desc, ''),
+			context
+		FROM pg_catalog.pg_settings
+		ORDER BY name
+	`
+
+	rows, err := d.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения настроек: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make([]models.ServerSetting, 0)
+	for rows.Next() {
+		var setting models.ServerSetting
+		var settingContext string
+		if err := rows.Scan(&setting.Name, &setting.Value, &setting.Default, &setting.Unit, &setting.Description, &settingContext); err != nil {
+			continue
+		}
+		// internal-параметры задаются только при сборке сервера
+		setting.Changeable = settingContext != "internal"
+		settings = append(settings, setting)
+	}
+
+	return settings, nil
+}
+
+// SetSetting меняет параметр через ALTER SYSTEM (scope "system", по умолчанию)
+// или ALTER DATABASE (scope "database"). Обычный SET не подходит: он действует
+// только на одно соединение из пула.
+func (d *PostgreSQLDriver) SetSetting(ctx context.Context, name, value, scope string) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	var settingContext string
+	err := d.pool.QueryRow(ctx, "SELECT context FROM pg_catalog.pg_settings WHERE name = $1", name).Scan(&settingContext)
+	if err != nil {
+		return fmt.Errorf("неизвестный параметр %s", name)
+	}
+	if settingContext == "internal" {
+		return fmt.Errorf("параметр %s нельзя изменить", name)
+	}
+
+	quotedValue := quotePostgresLiteral(value)
+
+	switch scope {
+	case "", "system":
+		query := fmt.Sprintf("ALTER SYSTEM SET %s = %s", name, quotedValue)
+		if _, err := d.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("ошибка изменения параметра: %w", err)
+		}
+		if _, err := d.pool.Exec(ctx, "SELECT pg_reload_conf()"); err != nil {
+			return fmt.Errorf("ошибка перечитывания конфигурации: %w", err)
+		}
+	case "database":
+		query := fmt.Sprintf("ALTER DATABASE %s SET %s = %s", pgx.Identifier{d.conn.Database}.Sanitize(), name, quotedValue)
+		if _, err := d.pool.Exec(ctx, query); err != nil {
+			return fmt.Errorf("ошибка изменения параметра: %w", err)
+		}
+	default:
+		return fmt.Errorf("неизвестная область применения: %s", scope)
+	}
+
+	return nil
+}
+
+var postgresIndexMethods = map[string]bool{
+	"btree":  true,
+	"hash":   true,
+	"gist":   true,
+	"spgist": true,
+	"gin":    true,
+	"brin":   true,
+}
+
+func (d *PostgreSQLDriver) ListIndexes(ctx context.Context, table string) ([]models.IndexInfo, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	schema, name := d.splitTable(table)
+	rows, err := d.pool.Query(ctx, `
+		SELECT indexname, indexdef
+		FROM pg_indexes
+		WHERE schemaname = $1 AND tablename = $2
+		ORDER BY indexname
+	`, schema, name)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка индексов: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := make([]models.IndexInfo, 0)
+	for rows.Next() {
+		index := models.IndexInfo{Table: table}
+		if err := rows.Scan(&index.Name, &index.Definition); err != nil {
+			return nil, fmt.Errorf("ошибка чтения индекса: %w", err)
+		}
+		index.Unique = strings.HasPrefix(index.Definition, "CREATE UNIQUE INDEX")
+		index.Type, index.Columns = parsePostgresIndexDef(index.Definition)
+		indexes = append(indexes, index)
+	}
+
+	return indexes, rows.Err()
+}
+
+func (d *PostgreSQLDriver) CreateIndex(ctx context.Context, req models.CreateIndexRequest) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	method := strings.ToLower(req.Type)
+	if method != "" && !postgresIndexMethods[method] {
+		return fmt.Errorf("неизвестный метод индекса %q: допустимы btree, hash, gist, spgist, gin, brin", req.Type)
+	}
+
+	columns := make([]string, len(req.Columns))
+	for i, column := range req.Columns {
+		columns[i] = pgx.Identifier{column}.Sanitize()
+	}
+
+	query := "CREATE "
+	if req.Unique {
+		query += "UNIQUE "
+	}
+	query += "INDEX " + pgx.Identifier{req.Name}.Sanitize() +
+		" ON " + pgx.Identifier(strings.Split(req.Table, ".")).Sanitize()
+	if method != "" {
+		query += " USING " + method
+	}
+	query += " (" + strings.Join(columns, ", ") + ")"
+
+	if _, err := d.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка создания индекса: %w", err)
+	}
+	return nil
+}
+
+// DropIndex удаляет индекс из схемы таблицы: в PostgreSQL индекс живет
+// в той же схеме, что и таблица
+func (d *PostgreSQLDriver) DropIndex(ctx context.Context, table, name string) error {
+	if d.pool == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	schema, _ := d.splitTable(table)
+	query := "DROP INDEX " + pgx.Identifier{schema, name}.Sanitize()
+	if _, err := d.pool.Exec(ctx, query); err != nil {
+		return fmt.Errorf("ошибка удаления индекса: %w", err)
+	}
+	return nil
+}
+
+// parsePostgresIndexDef извлекает метод и ключевые столбцы (выражения)
+// из определения вида "CREATE INDEX i ON t USING btree (a, lower(b))"
+func parsePostgresIndexDef(def string) (string, []string) {
+	idx := strings.Index(def, " USING ")
+	if idx == -1 {
+		return "", nil
+	}
+	rest := def[idx+len(" USING "):]
+
+	open := strings.Index(rest, "(")
+	if open == -1 {
+		return strings.TrimSpace(rest), nil
+	}
+	method := strings.TrimSpace(rest[:open])
+
+	var columns []string
+	depth, start := 0, open+1
+	for i := open; i < len(rest); i++ {
+		switch rest[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return method, append(columns, strings.TrimSpace(rest[start:i]))
+			}
+		case ',':
+			if depth == 1 {
+				columns = append(columns, strings.TrimSpace(rest[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return method, columns
+}
@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// postgresObjectPrivileges - допустимые привилегии по типу объекта
+var postgresObjectPrivileges = map[string]map[string]bool{
+	"DATABASE": {"CREATE": true, "CONNECT": true, "TEMPORARY": true, "TEMP": true, "ALL": true},
+	"SCHEMA":   {"CREATE": true, "USAGE": true, "ALL": true},
+	"TABLE": {"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+		"TRUNCATE": true, "REFERENCES": true, "TRIGGER": true, "ALL": true},
+}
+
+// postgresGrant - разобранная строка прав пользователя: либо членство в
+// роли (Role), либо привилегии на объект
+type postgresGrant struct {
+	Role       string
+	Privileges []string
+	ObjectType string // DATABASE, SCHEMA, TABLE или ALL TABLES IN SCHEMA
+	Object     string // экранированное имя объекта
+}
+
+// parsePostgresPermission разбирает строку прав. Имя без ON - роль, в
+// которую включается пользователь (GRANT роль TO user). Строка с ON -
+// привилегии на объект: "SELECT, INSERT ON TABLE public.orders",
+// "USAGE ON SCHEMA sales", "CONNECT ON DATABASE shop",
+// "SELECT ON ALL TABLES IN SCHEMA public"; без типа объекта - таблица.
+// Привилегии проверяются по типу объекта, ALL PRIVILEGES - то же, что ALL
+func parsePostgresPermission(permission string) (postgresGrant, error) {
+	permission = strings.TrimSpace(permission)
+	if permission == "" {
+		return postgresGrant{}, fmt.Errorf("пустая строка прав")
+	}
+
+	on := strings.Index(strings.ToUpper(permission), " ON ")
+	if on < 0 {
+		if postgresObjectPrivileges["TABLE"][strings.ToUpper(permission)] ||
+			postgresObjectPrivileges["DATABASE"][strings.ToUpper(permission)] ||
+			strings.EqualFold(permission, "USAGE") {
+			return postgresGrant{}, fmt.Errorf("для привилегии %s укажите объект: %s ON DATABASE|SCHEMA|TABLE имя", permission, strings.ToUpper(permission))
+		}
+		return postgresGrant{Role: permission}, nil
+	}
+
+	grant := postgresGrant{}
+	target := strings.TrimSpace(permission[on+len(" ON "):])
+	upperTarget := strings.ToUpper(target)
+	privilegeKind := "TABLE"
+	switch {
+	case strings.HasPrefix(upperTarget, "ALL TABLES IN SCHEMA "):
+		grant.ObjectType = "ALL TABLES IN SCHEMA"
+		target = target[len("ALL TABLES IN SCHEMA "):]
+	case strings.HasPrefix(upperTarget, "DATABASE "), strings.HasPrefix(upperTarget, "SCHEMA "), strings.HasPrefix(upperTarget, "TABLE "):
+		grant.ObjectType = upperTarget[:strings.Index(upperTarget, " ")]
+		privilegeKind = grant.ObjectType
+		target = target[len(grant.ObjectType)+1:]
+	case upperTarget == "DATABASE" || upperTarget == "SCHEMA" || upperTarget == "TABLE":
+		return postgresGrant{}, fmt.Errorf("не указано имя объекта в правах %q", permission)
+	default:
+		grant.ObjectType = "TABLE"
+	}
+	target = strings.TrimSpace(target)
+	if target == "" || strings.ContainsAny(target, " \t,;") {
+		return postgresGrant{}, fmt.Errorf("неверное имя объекта в правах %q", permission)
+	}
+	if grant.ObjectType == "TABLE" {
+		grant.Object = quotePostgresTable(target)
+	} else {
+		grant.Object = pgx.Identifier{target}.Sanitize()
+	}
+
+	allowed := postgresObjectPrivileges[privilegeKind]
+	for _, privilege := range strings.Split(permission[:on], ",") {
+		privilege = strings.ToUpper(strings.Join(strings.Fields(privilege), " "))
+		if privilege == "ALL PRIVILEGES" {
+			privilege = "ALL"
+		}
+		if !allowed[privilege] {
+			return postgresGrant{}, fmt.Errorf("привилегия %q недопустима для %s", privilege, strings.ToLower(grant.ObjectType))
+		}
+		grant.Privileges = append(grant.Privileges, privilege)
+	}
+	return grant, nil
+}
+
+// parsePostgresPermissions разбирает все строки прав до выполнения GRANT,
+// чтобы ошибка в одной строке не оставила права измененными наполовину
+func parsePostgresPermissions(permissions []string) ([]postgresGrant, error) {
+	grants := make([]postgresGrant, 0, len(permissions))
+	for _, permission := range permissions {
+		grant, err := parsePostgresPermission(permission)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// grantSQL - команда выдачи права пользователю. Имя пользователя
+// экранируется, как в CREATE USER и ALTER USER драйвера
+func (g postgresGrant) grantSQL(username string) string {
+	user := pgx.Identifier{username}.Sanitize()
+	if g.Role != "" {
+		return fmt.Sprintf("GRANT %s TO %s", pgx.Identifier{g.Role}.Sanitize(), user)
+	}
+	return fmt.Sprintf("GRANT %s ON %s %s TO %s", strings.Join(g.Privileges, ", "), g.ObjectType, g.Object, user)
+}
+
+// revokeSQL - команда, снимающая с пользователя все привилегии на объект
+// права (для роли - членство в ней)
+func (g postgresGrant) revokeSQL(username string) string {
+	user := pgx.Identifier{username}.Sanitize()
+	if g.Role != "" {
+		return fmt.Sprintf("REVOKE %s FROM %s", pgx.Identifier{g.Role}.Sanitize(), user)
+	}
+	return fmt.Sprintf("REVOKE ALL ON %s %s FROM %s", g.ObjectType, g.Object, user)
+}
+
+// ListGrantableRoles возвращает роли без права входа - групповые роли и
+// встроенные pg_* (pg_read_all_data, pg_monitor...). Привилегии на объекты
+// в список не входят: для них нужен объект, см. parsePostgresPermission
+func (d *PostgreSQLDriver) ListGrantableRoles(ctx context.Context) ([]models.GrantableRole, error) {
+	if d.pool == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	rows, err := d.pool.Query(ctx, "SELECT rolname FROM pg_catalog.pg_roles WHERE NOT rolcanlogin ORDER BY rolname")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка ролей: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make([]models.GrantableRole, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка чтения роли: %w", err)
+		}
+		roles = append(roles, models.GrantableRole{
+			Name:    name,
+			Kind:    models.GrantKindRole,
+			BuiltIn: strings.HasPrefix(name, "pg_"),
+		})
+	}
+	return roles, rows.Err()
+}
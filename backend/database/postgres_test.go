@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPostgresArgsTypedParams(t *testing.T) {
+	var req models.QueryRequest
+	body := `{"query": "SELECT $1, $2, $3, $4, $5, $6", "params": [42, "abc", null, "2024-05-01T10:00:00Z", 1.5, 12345678901234567890]}`
+	if err := json.Unmarshal([]byte(body), &req); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	got := postgresArgs(req.Params.Positional)
+	want := []interface{}{
+		int64(42),
+		"abc",
+		nil,
+		"2024-05-01T10:00:00Z",
+		1.5,
+		"12345678901234567890",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("postgresArgs = %#v, want %#v", got, want)
+	}
+}
+
+func TestPostgresArgsKeepsGoValues(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	args := []interface{}{7, "x", nil, ts}
+
+	got := postgresArgs(args)
+	if !reflect.DeepEqual(got, args) {
+		t.Errorf("postgresArgs = %#v, want %#v", got, args)
+	}
+}
+
+func TestQueryParamsNamed(t *testing.T) {
+	var req models.QueryRequest
+	if err := json.Unmarshal([]byte(`{"params": {"id": 5, "name": "a"}}`), &req); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+
+	if req.Params.Positional != nil {
+		t.Errorf("Positional = %#v, want nil", req.Params.Positional)
+	}
+	want := map[string]interface{}{"id": json.Number("5"), "name": "a"}
+	if !reflect.DeepEqual(req.Params.Named, want) {
+		t.Errorf("Named = %#v, want %#v", req.Params.Named, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"params": "x"}`), &req); err == nil {
+		t.Error("ожидалась ошибка для params-строки")
+	}
+}
+
+func TestParsePostgresIndexDef(t *testing.T) {
+	tests := []struct {
+		def     string
+		method  string
+		columns []string
+	}{
+		{"CREATE UNIQUE INDEX users_pkey ON public.users USING btree (id)", "btree", []string{"id"}},
+		{"CREATE INDEX idx ON public.t USING btree (a, lower((b)::text)) WHERE (a > 0)", "btree", []string{"a", "lower((b)::text)"}},
+		{"CREATE INDEX idx ON public.t USING gin (tags) INCLUDE (id)", "gin", []string{"tags"}},
+		{"CREATE INDEX idx ON t", "", nil},
+	}
+
+	for _, tt := range tests {
+		method, columns := parsePostgresIndexDef(tt.def)
+		if method != tt.method || !reflect.DeepEqual(columns, tt.columns) {
+			t.Errorf("parsePostgresIndexDef(%q) = %q, %v, want %q, %v", tt.def, method, columns, tt.method, tt.columns)
+		}
+	}
+}
+
+func TestPostgresAlterColumn(t *testing.T) {
+	tests := []struct {
+		col  models.TableColumn
+		want string
+	}{
+		{models.TableColumn{Name: "age", Type: "integer", Nullable: true}, `ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "age" integer`},
+		{models.TableColumn{Name: `Age"; DROP TABLE users; --`, Type: "integer", Nullable: true}, `ALTER TABLE "public"."users" ADD COLUMN IF NOT EXISTS "Age""; DROP TABLE users; --" integer`},
+		{models.TableColumn{Name: "age", NewName: `Years"x`, Operation: models.ColumnRename}, `ALTER TABLE "public"."users" RENAME COLUMN "age" TO "Years""x"`},
+		{models.TableColumn{Name: "Age", Operation: models.ColumnDrop}, `ALTER TABLE "public"."users" DROP COLUMN "Age"`},
+		{models.TableColumn{Name: "age", Type: "bigint", Operation: models.ColumnAlter}, `ALTER TABLE "public"."users" ALTER COLUMN "age" TYPE bigint USING "age"::bigint`},
+		{models.TableColumn{Name: "age", NewName: "years", Operation: models.ColumnRename}, `ALTER TABLE "public"."users" RENAME COLUMN "age" TO "years"`},
+	}
+
+	for _, tt := range tests {
+		got, err := postgresAlterColumn("public.users", tt.col)
+		if err != nil {
+			t.Fatalf("postgresAlterColumn(%+v): %v", tt.col, err)
+		}
+		if got != tt.want {
+			t.Errorf("postgresAlterColumn(%+v) = %q, want %q", tt.col, got, tt.want)
+		}
+	}
+
+	if _, err := postgresAlterColumn("users", models.TableColumn{Name: "age", Operation: models.ColumnAlter}); err == nil {
+		t.Error("ожидалась ошибка: alter без type")
+	}
+	if _, err := postgresAlterColumn("users", models.TableColumn{Name: "age", Operation: "truncate"}); err == nil {
+		t.Error("ожидалась ошибка: неизвестная операция")
+	}
+
+	changes, err := postgresTableChanges("Users", `people"x`, []models.TableColumn{{Name: "age", Type: "integer", Nullable: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`ALTER TABLE "Users" RENAME TO "people""x"`,
+		`ALTER TABLE "people""x" ADD COLUMN IF NOT EXISTS "age" integer`,
+	}
+	if got := changes.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("postgresTableChanges() = %q, want %q", got, want)
+	}
+}
+
+func TestPostgresCreateTable(t *testing.T) {
+	columns := []models.TableColumn{
+		{Name: "id", Type: "integer"},
+		{Name: "Order", Type: "text", Nullable: true, Default: "'new'"},
+		{Name: "user_id", Type: "integer", Nullable: true, References: "auth.users(id)"},
+	}
+	got := postgresCreateTable("sales.Orders", columns, []string{"id", "Order"}, []string{"id > 0"})
+	want := `CREATE TABLE "sales"."Orders" (
+  "id" integer NOT NULL,
+  "Order" text DEFAULT 'new',
+  "user_id" integer REFERENCES "auth"."users" ("id"),
+  PRIMARY KEY ("id", "Order"),
+  CHECK (id > 0)
+)`
+	if got != want {
+		t.Errorf("postgresCreateTable() = %s, want %s", got, want)
+	}
+
+	if got, want := postgresDropTable("sales.Orders", true), `DROP TABLE IF EXISTS "sales"."Orders" CASCADE`; got != want {
+		t.Errorf("postgresDropTable() = %s, want %s", got, want)
+	}
+}
+
+func TestParseColumnReference(t *testing.T) {
+	table, column, err := parseColumnReference("sales.orders(id)")
+	if err != nil || table != "sales.orders" || column != "id" {
+		t.Fatalf("parseColumnReference() = %q, %q, %v", table, column, err)
+	}
+
+	for _, ref := range []string{"orders", "(id)", "orders()", "orders(a, b)"} {
+		if _, _, err := parseColumnReference(ref); err == nil {
+			t.Errorf("parseColumnReference(%q): ожидалась ошибка", ref)
+		}
+	}
+}
+
+func TestParsePostgresPermission(t *testing.T) {
+	tests := []struct {
+		permission string
+		grant      string
+		revoke     string
+	}{
+		{"readers", `GRANT "readers" TO "bob"`, `REVOKE "readers" FROM "bob"`},
+		{"select, insert on table public.orders", `GRANT SELECT, INSERT ON TABLE "public"."orders" TO "bob"`, `REVOKE ALL ON TABLE "public"."orders" FROM "bob"`},
+		{"SELECT ON orders", `GRANT SELECT ON TABLE "orders" TO "bob"`, `REVOKE ALL ON TABLE "orders" FROM "bob"`},
+		{"USAGE ON SCHEMA sales", `GRANT USAGE ON SCHEMA "sales" TO "bob"`, `REVOKE ALL ON SCHEMA "sales" FROM "bob"`},
+		{"CONNECT, TEMP ON DATABASE shop", `GRANT CONNECT, TEMP ON DATABASE "shop" TO "bob"`, `REVOKE ALL ON DATABASE "shop" FROM "bob"`},
+		{"ALL PRIVILEGES ON ALL TABLES IN SCHEMA public", `GRANT ALL ON ALL TABLES IN SCHEMA "public" TO "bob"`, `REVOKE ALL ON ALL TABLES IN SCHEMA "public" FROM "bob"`},
+	}
+	for _, tt := range tests {
+		grant, err := parsePostgresPermission(tt.permission)
+		if err != nil {
+			t.Errorf("parsePostgresPermission(%q): %v", tt.permission, err)
+			continue
+		}
+		if got := grant.grantSQL("bob"); got != tt.grant {
+			t.Errorf("parsePostgresPermission(%q).grantSQL = %s, want %s", tt.permission, got, tt.grant)
+		}
+		if got := grant.revokeSQL("bob"); got != tt.revoke {
+			t.Errorf("parsePostgresPermission(%q).revokeSQL = %s, want %s", tt.permission, got, tt.revoke)
+		}
+	}
+
+	for _, permission := range []string{"", "SELECT", "CONNECT ON TABLE orders", "USAGE ON DATABASE shop", "SELECT ON TABLE a, b", "SELECT ON SCHEMA"} {
+		if _, err := parsePostgresPermission(permission); err == nil {
+			t.Errorf("parsePostgresPermission(%q): ожидалась ошибка", permission)
+		}
+	}
+}
+
+func TestQuotePostgresLiteral(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"secret", `'secret'`},
+		{"it's", `'it''s'`},
+		{"x'; DROP ROLE admin; --", `'x''; DROP ROLE admin; --'`},
+	}
+	for _, tt := range tests {
+		if got := quotePostgresLiteral(tt.value); got != tt.want {
+			t.Errorf("quotePostgresLiteral(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
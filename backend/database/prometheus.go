@@ -0,0 +1,381 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusDriver выполняет PromQL через HTTP API Prometheus и совместимых
+// хранилищ (VictoriaMetrics, Thanos, Mimir). Если задан DSN, он используется
+// как базовый URL API, например http://vm:8428 или https://mimir/prometheus
+type PrometheusDriver struct {
+	client  *http.Client
+	baseURL string
+	conn    models.Connection
+}
+
+func NewPrometheusDriver() *PrometheusDriver {
+	return &PrometheusDriver{}
+}
+
+// Capabilities: хранилище доступно только для чтения через PromQL
+func (d *PrometheusDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage: "promql",
+	}
+}
+
+// prometheusRangeQuery - запрос к /api/v1/query_range. Вместо start/end
+// можно указать range (длительность до текущего момента)
+type prometheusRangeQuery struct {
+	Query string `json:"query"`
+	Start string `json:"start,omitempty"` // RFC3339 или unix-время
+	End   string `json:"end,omitempty"`
+	Range string `json:"range,omitempty"` // например "1h"
+	Step  string `json:"step,omitempty"`  // например "30s"; по умолчанию range/250
+}
+
+// prometheusData - поле data ответа API
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type prometheusSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+	Values [][]interface{}   `json:"values"`
+}
+
+func (d *PrometheusDriver) Connect(ctx context.Context, conn models.Connection) error {
+	if conn.DSN != "" {
+		d.baseURL = strings.TrimRight(conn.DSN, "/")
+	} else {
+		scheme := "http"
+		if conn.SSL {
+			scheme = "https"
+		}
+		port := conn.Port
+		if port == "" {
+			port = "9090"
+		}
+		d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, port)
+	}
+	d.conn = conn
+
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
+	if err := d.Ping(ctx); err != nil {
+		d.baseURL = ""
+		return fmt.Errorf("ошибка подключения к Prometheus: %w", err)
+	}
+
+	return nil
+}
+
+func (d *PrometheusDriver) Disconnect(ctx context.Context) error {
+	d.baseURL = ""
+	return nil
+}
+
+func (d *PrometheusDriver) IsConnected(ctx context.Context) bool {
+	return d.baseURL != "" && d.Ping(ctx) == nil
+}
+
+// Ping выполняет простейший запрос: /-/healthy есть не у всех совместимых хранилищ
+func (d *PrometheusDriver) Ping(ctx context.Context) error {
+	if d.baseURL == "" {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	var data prometheusData
+	return d.get(ctx, "/api/v1/query", url.Values{"query": {"1"}}, &data)
+}
+
+// get выполняет GET к API и разбирает поле data ответа
+func (d *PrometheusDriver) get(ctx context.Context, path string, params url.Values, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", d.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	if d.conn.Username != "" {
+		req.SetBasicAuth(d.conn.Username, d.conn.Password)
+	} else if d.conn.Password != "" {
+		req.Header.Set("Authorization", "Bearer "+d.conn.Password)
+	}
+
+	resp, err := doIdempotent(d.client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var envelope struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data"`
+		Error  string          `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return fmt.Errorf("статус %d, ответ: %s", resp.StatusCode, string(body))
+	}
+	if envelope.Status != "success" {
+		return fmt.Errorf("%s", envelope.Error)
+	}
+
+	if err := json.Unmarshal(envelope.Data, target); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+	return nil
+}
+
+// ExecuteQuery принимает PromQL (мгновенный запрос к /api/v1/query) или
+// JSON {"query", "range"|"start"/"end", "step"} для /api/v1/query_range.
+// Каждая точка ряда - строка: метки ряда, timestamp и value
+func (d *PrometheusDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	startTime := time.Now()
+
+	path := "/api/v1/query"
+	params := url.Values{"query": {strings.TrimSpace(query)}}
+
+	// Селектор PromQL тоже может начинаться с {, но JSON-ом не является
+	var rangeQuery prometheusRangeQuery
+	if err := json.Unmarshal([]byte(query), &rangeQuery); err == nil && rangeQuery.Query != "" {
+		var err error
+		params, err = rangeQuery.params(time.Now())
+		if err != nil {
+			return &models.QueryResponse{Error: err.Error()}, nil
+		}
+		path = "/api/v1/query_range"
+	}
+
+	var data prometheusData
+	if err := d.get(ctx, path, params, &data); err != nil {
+		return &models.QueryResponse{
+			Error: fmt.Sprintf("ошибка выполнения запроса: %v", err),
+		}, nil
+	}
+
+	response, err := prometheusDataToResponse(data)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+	response.ExecutionTime = time.Since(startTime).Milliseconds()
+	return response, nil
+}
+
+func (q prometheusRangeQuery) params(now time.Time) (url.Values, error) {
+	params := url.Values{"query": {q.Query}}
+
+	start, end := q.Start, q.End
+	var window time.Duration
+	if q.Range != "" {
+		r, err := time.ParseDuration(q.Range)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("неверный range %q", q.Range)
+		}
+		window = r
+		end = strconv.FormatInt(now.Unix(), 10)
+		start = strconv.FormatInt(now.Add(-r).Unix(), 10)
+	}
+	if start == "" || end == "" {
+		return nil, fmt.Errorf("для запроса за период укажите range или start и end")
+	}
+
+	step := q.Step
+	if step == "" {
+		if window == 0 {
+			return nil, fmt.Errorf("для запроса с start и end укажите step")
+		}
+		// Около 250 точек на ряд, но не чаще раза в секунду
+		s := window / 250
+		if s < time.Second {
+			s = time.Second
+		}
+		step = strconv.FormatInt(int64(s/time.Second), 10) + "s"
+	}
+
+	params.Set("start", start)
+	params.Set("end", end)
+	params.Set("step", step)
+	return params, nil
+}
+
+// prometheusDataToResponse превращает vector, matrix, scalar и string в строки
+// таблицы. Столбцы - все метки рядов, затем timestamp и value
+func prometheusDataToResponse(data prometheusData) (*models.QueryResponse, error) {
+	rows := make([]map[string]interface{}, 0)
+	columns := newColumnSet()
+
+	switch data.ResultType {
+	case "vector", "matrix":
+		var series []prometheusSeries
+		if err := json.Unmarshal(data.Result, &series); err != nil {
+			return nil, fmt.Errorf("ошибка парсинга результата: %w", err)
+		}
+
+		for _, s := range series {
+			labels := make(map[string]interface{}, len(s.Metric))
+			for name, value := range s.Metric {
+				labels[name] = value
+			}
+			columns.addKeys(labels)
+
+			samples := s.Values
+			if data.ResultType == "vector" {
+				samples = [][]interface{}{s.Value}
+			}
+			for _, sample := range samples {
+				row := make(map[string]interface{}, len(labels)+2)
+				for name, value := range labels {
+					row[name] = value
+				}
+				addPrometheusSample(row, sample)
+				rows = append(rows, row)
+			}
+		}
+	case "scalar", "string":
+		var sample []interface{}
+		if err := json.Unmarshal(data.Result, &sample); err != nil {
+			return nil, fmt.Errorf("ошибка парсинга результата: %w", err)
+		}
+		row := make(map[string]interface{}, 2)
+		addPrometheusSample(row, sample)
+		rows = append(rows, row)
+	default:
+		return nil, fmt.Errorf("неизвестный тип результата %q", data.ResultType)
+	}
+
+	columns.add("timestamp")
+	columns.add("value")
+
+	return &models.QueryResponse{
+		Columns:  columns.list(),
+		Rows:     rows,
+		RowCount: len(rows),
+	}, nil
+}
+
+// addPrometheusSample добавляет пару [unix-время, "значение"]. Значение
+// становится числом, кроме NaN и Inf: их нельзя передать в JSON
+func addPrometheusSample(row map[string]interface{}, sample []interface{}) {
+	if len(sample) != 2 {
+		return
+	}
+
+	if ts, ok := sample[0].(float64); ok {
+		sec, frac := math.Modf(ts)
+		row["timestamp"] = time.Unix(int64(sec), int64(frac*1e9)).UTC().Format("2006-01-02T15:04:05.000Z07:00")
+	}
+
+	text, _ := sample[1].(string)
+	if value, err := strconv.ParseFloat(text, 64); err == nil && !math.IsNaN(value) && !math.IsInf(value, 0) {
+		row["value"] = value
+	} else {
+		row["value"] = text
+	}
+}
+
+// BrowseData показывает текущие значения рядов метрики
+func (d *PrometheusDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	result, err := d.ExecuteQuery(ctx, fmt.Sprintf("{__name__=%q}", table))
+	if err != nil || result.Error != "" {
+		return result, err
+	}
+
+	if offset >= len(result.Rows) {
+		result.Rows = result.Rows[:0]
+	} else {
+		result.Rows = result.Rows[offset:]
+	}
+	if len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+	}
+	result.RowCount = len(result.Rows)
+	return result, nil
+}
+
+func (d *PrometheusDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
+	return fmt.Errorf("Prometheus не поддерживает базы данных")
+}
+
+func (d *PrometheusDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
+	return []models.DatabaseInfo{}, nil
+}
+
+func (d *PrometheusDriver) UpdateDatabase(ctx context.Context, oldName, newName string, options map[string]interface{}) error {
+	return fmt.Errorf("Prometheus не поддерживает базы данных")
+}
+
+func (d *PrometheusDriver) DeleteDatabase(ctx context.Context, name string) error {
+	return fmt.Errorf("Prometheus не поддерживает базы данных")
+}
+
+func (d *PrometheusDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return fmt.Errorf("метрики Prometheus создаются при записи данных")
+}
+
+// ListTables возвращает имена метрик
+func (d *PrometheusDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.baseURL == "" {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	var names []string
+	if err := d.get(ctx, "/api/v1/label/__name__/values", url.Values{}, &names); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка метрик: %w", err)
+	}
+
+	tables := make([]models.TableInfo, 0, len(names))
+	for _, name := range names {
+		tables = append(tables, models.TableInfo{Name: name})
+	}
+	return tables, nil
+}
+
+func (d *PrometheusDriver) DeleteTable(ctx context.Context, name string) error {
+	return fmt.Errorf("Prometheus доступен только для чтения")
+}
+
+func (d *PrometheusDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
+	return fmt.Errorf("Prometheus доступен только для чтения")
+}
+
+func (d *PrometheusDriver) CreateUser(ctx context.Context, username, password, database string, permissions []string) error {
+	return fmt.Errorf("Prometheus не поддерживает управление пользователями")
+}
+
+func (d *PrometheusDriver) ListUsers(ctx context.Context) ([]models.UserInfo, error) {
+	return nil, fmt.Errorf("Prometheus не поддерживает управление пользователями")
+}
+
+func (d *PrometheusDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
+	return fmt.Errorf("Prometheus не поддерживает управление пользователями")
+}
+
+func (d *PrometheusDriver) DeleteUser(ctx context.Context, username string) error {
+	return fmt.Errorf("Prometheus не поддерживает управление пользователями")
+}
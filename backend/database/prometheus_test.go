@@ -0,0 +1,62 @@
+package database
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestPrometheusDataToResponse(t *testing.T) {
+	var matrix prometheusData
+	json.Unmarshal([]byte(`{"resultType":"matrix","result":[
+		{"metric":{"__name__":"up","job":"api"},"values":[[1700000000,"1"],[1700000015.5,"0"]]},
+		{"metric":{"instance":"db:9100"},"values":[[1700000000,"NaN"]]}
+	]}`), &matrix)
+
+	resp, err := prometheusDataToResponse(matrix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"__name__", "job", "instance", "timestamp", "value"}; !reflect.DeepEqual(resp.Columns, want) {
+		t.Errorf("columns = %v, want %v", resp.Columns, want)
+	}
+	if resp.RowCount != 3 {
+		t.Fatalf("RowCount = %d, want 3", resp.RowCount)
+	}
+	if row := resp.Rows[1]; row["job"] != "api" || row["value"] != float64(0) || row["timestamp"] != "2023-11-14T22:13:35.500Z" {
+		t.Errorf("row 1 = %v", row)
+	}
+	if row := resp.Rows[2]; row["value"] != "NaN" || row["instance"] != "db:9100" {
+		t.Errorf("row 2 = %v", row)
+	}
+
+	var scalar prometheusData
+	json.Unmarshal([]byte(`{"resultType":"scalar","result":[1700000000,"42"]}`), &scalar)
+	resp, err = prometheusDataToResponse(scalar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.RowCount != 1 || resp.Rows[0]["value"] != float64(42) {
+		t.Errorf("scalar rows = %v", resp.Rows)
+	}
+}
+
+func TestPrometheusRangeQueryParams(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	params, err := prometheusRangeQuery{Query: "up", Range: "1h"}.params(now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params.Get("start") != "1699996400" || params.Get("end") != "1700000000" || params.Get("step") != "14s" {
+		t.Errorf("params = %v", params)
+	}
+
+	if _, err := (prometheusRangeQuery{Query: "up", Start: "1699996400", End: "1700000000"}).params(now); err == nil {
+		t.Error("expected error without step")
+	}
+	if _, err := (prometheusRangeQuery{Query: "up", Range: "-1h"}).params(now); err == nil {
+		t.Error("expected error for negative range")
+	}
+}
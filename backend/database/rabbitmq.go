@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"time"
 )
 
 type RabbitMQDriver struct {
@@ -18,8 +17,14 @@ type RabbitMQDriver struct {
 }
 
 func NewRabbitMQDriver() *RabbitMQDriver {
-	return &RabbitMQDriver{
-		client: &http.Client{Timeout: 30 * time.Second},
+	return &RabbitMQDriver{}
+}
+
+func (d *RabbitMQDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		SupportsCreateDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
 	}
 }
 
@@ -31,6 +36,12 @@ func (d *RabbitMQDriver) Connect(ctx context.Context, conn models.Connection) er
 	d.baseURL = fmt.Sprintf("%s://%s:%s", scheme, conn.Host, conn.Port)
 	d.conn = conn
 
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+	d.client = client
+
 	if err := d.Ping(ctx); err != nil {
 		return fmt.Errorf("ошибка подключения к RabbitMQ: %w", err)
 	}
@@ -63,7 +74,7 @@ func (d *RabbitMQDriver) Ping(ctx context.Context) error {
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return err
 	}
@@ -131,7 +142,7 @@ func (d *RabbitMQDriver) ListDatabases(ctx context.Context) ([]models.DatabaseIn
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +261,7 @@ func (d *RabbitMQDriver) ListTables(ctx context.Context) ([]models.TableInfo, er
 		req.SetBasicAuth(d.conn.Username, d.conn.Password)
 	}
 
-	resp, err := d.client.Do(req)
+	resp, err := doIdempotent(d.client, req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// connectionErrorMarkers - фрагменты текста ошибок обрыва соединения для
+// драйверов, которые не оборачивают сетевую ошибку (%w), а пишут ее текстом
+var connectionErrorMarkers = []string{
+	"connection reset",
+	"broken pipe",
+	"connection refused",
+	"use of closed network connection",
+	"conn closed",
+	"connection closed",
+	"connection is closed",
+	"unexpected eof",
+	"server closed",
+	"terminating connection",
+	"no connection",
+	"подключение не установлено",
+}
+
+// IsConnectionError сообщает, что запрос не выполнился из-за потери
+// соединения с сервером, а не из-за самого запроса: после такой ошибки
+// имеет смысл переподключиться. Отмена и таймаут контекста - не обрыв
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	if message == "eof" || strings.HasSuffix(message, ": eof") {
+		return true
+	}
+	for _, marker := range connectionErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConnectionFailure проверяет результат запроса на обрыв соединения.
+// SQL-драйверы (runPostgresQuery, runSQLQuery, ClickHouse) и MongoDB
+// возвращают ошибку выполнения в result.Error, а не как error, поэтому
+// проверяется и текст ошибки ответа
+func IsConnectionFailure(result *models.QueryResponse, err error) bool {
+	if err != nil {
+		return IsConnectionError(err)
+	}
+	return result != nil && result.Error != "" && IsConnectionError(errors.New(result.Error))
+}
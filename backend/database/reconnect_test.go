@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	connectionErrors := []error{
+		io.EOF,
+		fmt.Errorf("ошибка выполнения запроса: %w", io.ErrUnexpectedEOF),
+		fmt.Errorf("ошибка выполнения запроса: %w", syscall.ECONNRESET),
+		&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED},
+		errors.New("connection(localhost:27017[-3]) incomplete read of message header: EOF"),
+		errors.New("write tcp 127.0.0.1:5432: broken pipe"),
+		errors.New("conn closed"),
+		errors.New("подключение не установлено"),
+	}
+	for _, err := range connectionErrors {
+		if !IsConnectionError(err) {
+			t.Errorf("IsConnectionError(%q) = false, want true", err)
+		}
+	}
+
+	queryErrors := []error{
+		nil,
+		errors.New(`ERROR: syntax error at or near "SELEC" (SQLSTATE 42601)`),
+		errors.New(`ERROR: relation "users" does not exist (SQLSTATE 42P01)`),
+		fmt.Errorf("ошибка выполнения запроса: %w", context.DeadlineExceeded),
+		context.Canceled,
+	}
+	for _, err := range queryErrors {
+		if IsConnectionError(err) {
+			t.Errorf("IsConnectionError(%v) = true, want false", err)
+		}
+	}
+}
+
+// TestIsConnectionFailurePostgres проверяет настоящие ошибки pgx, которые
+// runPostgresQuery возвращает в result.Error: сервер недоступен и сервер
+// закрыл соединение
+func TestIsConnectionFailurePostgres(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	refused, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refusedAddr := refused.Addr().String()
+	refused.Close()
+
+	closing, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closing.Close()
+	go func() {
+		for {
+			conn, err := closing.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	for _, addr := range []string{refusedAddr, closing.Addr().String()} {
+		pool, err := pgxpool.New(ctx, "postgres://user:pass@"+addr+"/db?sslmode=disable&connect_timeout=5")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := runPostgresQuery(ctx, pool, "SELECT 1")
+		pool.Close()
+		if !IsConnectionFailure(result, err) {
+			t.Errorf("%s: IsConnectionFailure(%+v, %v) = false, want true", addr, result, err)
+		}
+	}
+
+	queryError := &models.QueryResponse{Error: `ERROR: relation "missing" does not exist (SQLSTATE 42P01)`}
+	if IsConnectionFailure(queryError, nil) {
+		t.Errorf("IsConnectionFailure(%q) = true, want false", queryError.Error)
+	}
+	if IsConnectionFailure(&models.QueryResponse{}, nil) {
+		t.Error("IsConnectionFailure(empty result) = true, want false")
+	}
+}
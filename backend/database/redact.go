@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database-manager/models"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue заменяет значения скрытых столбцов в результате
+const RedactedValue = "***"
+
+// ColumnRedactor скрывает значения столбцов по правилам подключения
+// (redactColumns). Правило - glob по имени столбца без учета регистра
+// (password, *_token), а в /.../ - регулярное выражение (/^(ssn|inn)$/)
+type ColumnRedactor struct {
+	globs   []string
+	regexps []*regexp.Regexp
+}
+
+// NewColumnRedactor разбирает правила; nil - правил нет
+func NewColumnRedactor(rules []string) (*ColumnRedactor, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	redactor := &ColumnRedactor{}
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+		if len(rule) > 2 && strings.HasPrefix(rule, "/") && strings.HasSuffix(rule, "/") {
+			re, err := regexp.Compile("(?i)" + rule[1:len(rule)-1])
+			if err != nil {
+				return nil, fmt.Errorf("неверное регулярное выражение в redactColumns %q: %w", rule, err)
+			}
+			redactor.regexps = append(redactor.regexps, re)
+			continue
+		}
+		if _, err := path.Match(rule, ""); err != nil {
+			return nil, fmt.Errorf("неверный шаблон в redactColumns %q: %w", rule, err)
+		}
+		redactor.globs = append(redactor.globs, strings.ToLower(rule))
+	}
+	return redactor, nil
+}
+
+// Matches сообщает, скрывается ли столбец
+func (r *ColumnRedactor) Matches(column string) bool {
+	lower := strings.ToLower(column)
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(glob, lower); ok {
+			return true
+		}
+	}
+	for _, re := range r.regexps {
+		if re.MatchString(column) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply заменяет значения подходящих столбцов на RedactedValue; NULL
+// остается NULL, чтобы было видно отсутствие значения. Правила применяются
+// к столбцам верхнего уровня: вложенные поля документов MongoDB и
+// Elasticsearch скрываются вместе с родительским полем. В графе (Graph)
+// правила применяются к именам свойств узлов и связей
+func (r *ColumnRedactor) Apply(resp *models.QueryResponse) {
+	if r == nil || resp == nil {
+		return
+	}
+	if resp.Graph != nil {
+		for _, node := range resp.Graph.Nodes {
+			r.redactProperties(node.Properties)
+		}
+		for _, rel := range resp.Graph.Relationships {
+			r.redactProperties(rel.Properties)
+		}
+	}
+	if len(resp.Rows) == 0 {
+		return
+	}
+
+	columns := resp.Columns
+	if len(columns) == 0 {
+		columns = rowKeys(resp.Rows)
+	}
+	var redacted []string
+	for _, column := range columns {
+		if r.Matches(column) {
+			redacted = append(redacted, column)
+		}
+	}
+	if len(redacted) == 0 {
+		return
+	}
+
+	for _, row := range resp.Rows {
+		for _, column := range redacted {
+			if value, ok := row[column]; ok && value != nil {
+				row[column] = RedactedValue
+			}
+		}
+	}
+}
+
+func (r *ColumnRedactor) redactProperties(properties map[string]interface{}) {
+	for key, value := range properties {
+		if value != nil && r.Matches(key) {
+			properties[key] = RedactedValue
+		}
+	}
+}
+
+// MaskQueryLiterals заменяет строковые и числовые литералы запроса на ?,
+// чтобы значения (в том числе скрытых столбцов) не попадали в журналы.
+// Идентификаторы в двойных кавычках и обратных апострофах не меняются
+func MaskQueryLiterals(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			// '' внутри строки - экранированная кавычка
+			j := i + 1
+			for j < len(query) {
+				if query[j] == '\'' {
+					if j+1 < len(query) && query[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			b.WriteByte('?')
+			i = j + 1
+		case c == '"' || c == '`':
+			j := strings.IndexByte(query[i+1:], c)
+			if j < 0 {
+				b.WriteString(query[i:])
+				return b.String()
+			}
+			b.WriteString(query[i : i+j+2])
+			i += j + 2
+		case c >= '0' && c <= '9' && (i == 0 || !isIdentifierByte(query[i-1])):
+			j := i
+			for j < len(query) && (query[j] >= '0' && query[j] <= '9' || query[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isIdentifierByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func rowKeys(rows []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, row := range rows {
+		for key := range row {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
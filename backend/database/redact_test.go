@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database-manager/models"
+	"testing"
+)
+
+func TestColumnRedactorApply(t *testing.T) {
+	redactor, err := NewColumnRedactor([]string{"password", "*_token", "/^(ssn|inn)$/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := &models.QueryResponse{
+		Columns: []string{"id", "Password", "api_token", "SSN", "ssn_checked"},
+		Rows: []map[string]interface{}{
+			{"id": 1, "Password": "secret", "api_token": "abc", "SSN": "123-45-6789", "ssn_checked": true},
+			{"id": 2, "Password": nil, "api_token": "def", "SSN": "987-65-4321", "ssn_checked": false},
+		},
+	}
+	redactor.Apply(resp)
+
+	row := resp.Rows[0]
+	if row["Password"] != RedactedValue || row["api_token"] != RedactedValue || row["SSN"] != RedactedValue {
+		t.Errorf("row 0 not redacted: %v", row)
+	}
+	if row["id"] != 1 || row["ssn_checked"] != true {
+		t.Errorf("row 0 redacted too much: %v", row)
+	}
+	if resp.Rows[1]["Password"] != nil {
+		t.Errorf("NULL must stay NULL, got %v", resp.Rows[1]["Password"])
+	}
+
+	// Без списка столбцов (документные БД) столбцы берутся из строк
+	docs := &models.QueryResponse{Rows: []map[string]interface{}{{"_id": "x", "password": "p"}}}
+	redactor.Apply(docs)
+	if docs.Rows[0]["password"] != RedactedValue {
+		t.Errorf("document not redacted: %v", docs.Rows[0])
+	}
+
+	// В графе скрываются свойства узлов и связей
+	graph := &models.QueryResponse{Graph: &models.GraphResult{
+		Nodes:         []models.GraphNode{{ID: "n1", Properties: map[string]interface{}{"name": "a", "password": "p"}}},
+		Relationships: []models.GraphRelationship{{ID: "r1", Properties: map[string]interface{}{"auth_token": "t"}}},
+	}}
+	redactor.Apply(graph)
+	if graph.Graph.Nodes[0].Properties["password"] != RedactedValue || graph.Graph.Nodes[0].Properties["name"] != "a" {
+		t.Errorf("node properties: %v", graph.Graph.Nodes[0].Properties)
+	}
+	if graph.Graph.Relationships[0].Properties["auth_token"] != RedactedValue {
+		t.Errorf("relationship properties: %v", graph.Graph.Relationships[0].Properties)
+	}
+}
+
+func TestNewColumnRedactorInvalid(t *testing.T) {
+	if _, err := NewColumnRedactor([]string{"/(/"}); err == nil {
+		t.Error("expected error for invalid regexp")
+	}
+	if _, err := NewColumnRedactor([]string{"[a"}); err == nil {
+		t.Error("expected error for invalid glob")
+	}
+	if redactor, err := NewColumnRedactor(nil); err != nil || redactor != nil {
+		t.Errorf("empty rules: %v, %v", redactor, err)
+	}
+	var none *ColumnRedactor
+	none.Apply(&models.QueryResponse{Rows: []map[string]interface{}{{"password": "p"}}})
+}
+
+func TestMaskQueryLiterals(t *testing.T) {
+	cases := map[string]string{
+		"SELECT * FROM users WHERE password = 'secret' AND id = 42":  "SELECT * FROM users WHERE password = ? AND id = ?",
+		"SELECT 'it''s', 3.14 FROM t1":                               "SELECT ?, ? FROM t1",
+		`SELECT "col 1", ` + "`x2`" + ` FROM "table 2" WHERE v > 10`: `SELECT "col 1", ` + "`x2`" + ` FROM "table 2" WHERE v > ?`,
+		"SELECT $1, col_2 FROM t LIMIT 5":                            "SELECT $1, col_2 FROM t LIMIT ?",
+		"SELECT 'unterminated":                                       "SELECT ?",
+	}
+	for query, want := range cases {
+		if got := MaskQueryLiterals(query); got != want {
+			t.Errorf("MaskQueryLiterals(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package database
 
 import (
 	"context"
-	"crypto/tls"
 	"database-manager/models"
 	"fmt"
 	"strconv"
@@ -21,6 +20,13 @@ func NewRedisDriver() *RedisDriver {
 	return &RedisDriver{}
 }
 
+func (d *RedisDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:       "redis",
+		SupportsDeleteTable: true,
+	}
+}
+
 func (d *RedisDriver) Connect(ctx context.Context, conn models.Connection) error {
 	dbNum := 0
 	if conn.Database != "" {
@@ -36,9 +42,11 @@ func (d *RedisDriver) Connect(ctx context.Context, conn models.Connection) error
 	}
 
 	if conn.SSL {
-		opts.TLSConfig = &tls.Config{
-			InsecureSkipVerify: false,
+		tlsConfig, err := connTLSConfig(conn)
+		if err != nil {
+			return err
 		}
+		opts.TLSConfig = tlsConfig
 	}
 
 	client := redis.NewClient(opts)
@@ -73,11 +81,106 @@ func (d *RedisDriver) Ping(ctx context.Context) error {
 	return d.client.Ping(ctx).Err()
 }
 
+// redisCommander - общее у *redis.Client (пул) и *redis.Conn (выделенное
+// соединение сессии): Cmdable не включает Do
+type redisCommander interface {
+	redis.Cmdable
+	Do(ctx context.Context, args ...interface{}) *redis.Cmd
+}
+
 func (d *RedisDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
 	if d.client == nil {
 		return nil, fmt.Errorf("подключение не установлено")
 	}
 
+	return executeRedisQuery(ctx, d.client, query), nil
+}
+
+// OpenSession закрепляет за сессией одно соединение, чтобы SELECT db,
+// MULTI/EXEC и WATCH действовали на последующие команды
+func (d *RedisDriver) OpenSession(ctx context.Context) (QuerySession, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	conn := d.client.Conn()
+	if err := conn.Ping(ctx).Err(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ошибка открытия сессии: %w", err)
+	}
+	return &redisSession{conn: conn, db: d.client.Options().DB}, nil
+}
+
+type redisSession struct {
+	conn *redis.Conn
+	db   int
+	// queued - команды, поставленные в очередь после MULTI (nil вне транзакции)
+	queued []string
+}
+
+// ExecuteQuery внутри MULTI отправляет команды как есть: сервер отвечает
+// QUEUED, а результаты всех команд приходят в ответе EXEC
+func (s *redisSession) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	parts := strings.Fields(query)
+	if len(parts) == 0 || (s.queued == nil && !strings.EqualFold(parts[0], "MULTI")) {
+		return executeRedisQuery(ctx, s.conn, query), nil
+	}
+
+	startTime := time.Now()
+	command := strings.ToUpper(parts[0])
+	result, err := s.conn.Do(ctx, redisArgs(parts)...).Result()
+
+	switch command {
+	case "MULTI":
+		if err == nil {
+			s.queued = make([]string, 0)
+		}
+	case "EXEC":
+		queued := s.queued
+		s.queued = nil
+		if err != nil {
+			return &models.QueryResponse{Error: err.Error()}, nil
+		}
+		results, _ := result.([]interface{})
+		errs := make([]error, len(results))
+		for i, item := range results {
+			if itemErr, ok := item.(error); ok {
+				results[i], errs[i] = nil, itemErr
+			}
+		}
+		return redisTransactionResponse(queued, results, errs, startTime), nil
+	case "DISCARD":
+		s.queued = nil
+	default:
+		if err == nil {
+			s.queued = append(s.queued, strings.Join(parts, " "))
+		}
+	}
+
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+	return &models.QueryResponse{
+		Columns:       []string{"key", "value", "type"},
+		Rows:          []map[string]interface{}{{"key": "result", "value": result, "type": "string"}},
+		RowCount:      1,
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
+// Close возвращает соединение в пул клиента, поэтому сначала сбрасывает
+// состояние сессии: незавершенную транзакцию, WATCH и выбранную базу
+func (s *redisSession) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.conn.Do(ctx, "DISCARD")
+	s.conn.Do(ctx, "UNWATCH")
+	s.conn.Select(ctx, s.db)
+	return s.conn.Close()
+}
+
+func executeRedisQuery(ctx context.Context, client redisCommander, query string) *models.QueryResponse {
 	startTime := time.Now()
 
 	query = strings.TrimSpace(query)
@@ -85,7 +188,7 @@ func (d *RedisDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 	if len(parts) == 0 {
 		return &models.QueryResponse{
 			Error: "пустой запрос",
-		}, nil
+		}
 	}
 
 	command := strings.ToUpper(parts[0])
@@ -100,27 +203,23 @@ func (d *RedisDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 	switch command {
 	case "GET", "HGET", "LINDEX", "SMEMBERS", "ZRANGE":
 		if len(args) == 0 {
-			return &models.QueryResponse{Error: fmt.Sprintf("команда %s требует аргументы", command)}, nil
+			return &models.QueryResponse{Error: fmt.Sprintf("команда %s требует аргументы", command)}
 		}
-		result, err = d.executeReadCommand(ctx, command, args)
+		result, err = executeReadCommand(ctx, client, command, args)
 	case "KEYS", "SCAN":
-		result, err = d.executeKeysCommand(ctx, command, args)
+		result, err = executeKeysCommand(ctx, client, command, args)
 	case "INFO":
-		result, err = d.executeInfoCommand(ctx)
+		result, err = executeInfoCommand(ctx, client)
 	case "DBSIZE":
-		result, err = d.client.DBSize(ctx).Result()
+		result, err = client.DBSize(ctx).Result()
 	default:
-		args := make([]interface{}, len(parts))
-		for i, part := range parts {
-			args[i] = part
-		}
-		result, err = d.client.Do(ctx, args...).Result()
+		result, err = client.Do(ctx, redisArgs(parts)...).Result()
 	}
 
 	if err != nil {
 		return &models.QueryResponse{
 			Error: err.Error(),
-		}, nil
+		}
 	}
 
 	columns := []string{"key", "value", "type"}
@@ -182,46 +281,172 @@ func (d *RedisDriver) ExecuteQuery(ctx context.Context, query string) (*models.Q
 		Rows:          rowsData,
 		RowCount:      len(rowsData),
 		ExecutionTime: executionTime,
+	}
+}
+
+// ExecuteTransaction выполняет команды атомарно в MULTI/EXEC через
+// TxPipeline и возвращает результат каждой команды в исходном порядке
+func (d *RedisDriver) ExecuteTransaction(ctx context.Context, commands []string) (*models.QueryResponse, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	startTime := time.Now()
+
+	pipe := d.client.TxPipeline()
+	cmds := make([]*redis.Cmd, 0, len(commands))
+	for i, command := range commands {
+		parts := strings.Fields(command)
+		if len(parts) == 0 {
+			return &models.QueryResponse{Error: fmt.Sprintf("команда %d пуста", i+1)}, nil
+		}
+		cmds = append(cmds, pipe.Do(ctx, redisArgs(parts)...))
+	}
+
+	// Ошибка Exec дублирует ошибку первой неудачной команды; ошибки
+	// возвращаются построчно, а остальные команды транзакции применяются
+	pipe.Exec(ctx)
+
+	results := make([]interface{}, len(cmds))
+	errs := make([]error, len(cmds))
+	for i, cmd := range cmds {
+		results[i], errs[i] = cmd.Result()
+	}
+
+	return redisTransactionResponse(commands, results, errs, startTime), nil
+}
+
+func redisTransactionResponse(commands []string, results []interface{}, errs []error, startTime time.Time) *models.QueryResponse {
+	rowsData := make([]map[string]interface{}, 0, len(results))
+	for i, result := range results {
+		row := map[string]interface{}{
+			"index":  i + 1,
+			"result": result,
+			"error":  "",
+		}
+		if i < len(commands) {
+			row["command"] = commands[i]
+		}
+		if errs[i] != nil && errs[i] != redis.Nil {
+			row["error"] = errs[i].Error()
+		}
+		rowsData = append(rowsData, row)
+	}
+
+	return &models.QueryResponse{
+		Columns:       []string{"index", "command", "result", "error"},
+		Rows:          rowsData,
+		RowCount:      len(rowsData),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+	}
+}
+
+func redisArgs(parts []string) []interface{} {
+	args := make([]interface{}, len(parts))
+	for i, part := range parts {
+		args[i] = part
+	}
+	return args
+}
+
+func (d *RedisDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.client == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	startTime := time.Now()
+
+	// table трактуется как шаблон MATCH для SCAN
+	pattern := table
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	keys := make([]string, 0, limit)
+	skipped := 0
+	iter := d.client.Scan(ctx, 0, pattern, int64(limit+offset)).Iterator()
+	for iter.Next(ctx) {
+		if skipped < offset {
+			skipped++
+			continue
+		}
+		keys = append(keys, iter.Val())
+		if len(keys) >= limit {
+			break
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	rowsData := make([]map[string]interface{}, 0, len(keys))
+	for _, key := range keys {
+		keyType, err := d.client.Type(ctx, key).Result()
+		if err != nil {
+			continue
+		}
+
+		value := ""
+		if keyType == "string" {
+			value, _ = d.client.Get(ctx, key).Result()
+		}
+
+		rowsData = append(rowsData, map[string]interface{}{
+			"key":   key,
+			"value": value,
+			"type":  keyType,
+		})
+	}
+
+	executionTime := time.Since(startTime).Milliseconds()
+
+	return &models.QueryResponse{
+		Columns:       []string{"key", "value", "type"},
+		Rows:          rowsData,
+		RowCount:      len(rowsData),
+		ExecutionTime: executionTime,
 	}, nil
 }
 
-func (d *RedisDriver) executeReadCommand(ctx context.Context, command string, args []interface{}) (interface{}, error) {
+func executeReadCommand(ctx context.Context, client redisCommander, command string, args []interface{}) (interface{}, error) {
 	switch command {
 	case "GET":
-		return d.client.Get(ctx, args[0].(string)).Result()
+		return client.Get(ctx, args[0].(string)).Result()
 	case "HGET":
 		if len(args) < 2 {
 			return nil, fmt.Errorf("HGET требует key и field")
 		}
-		return d.client.HGet(ctx, args[0].(string), args[1].(string)).Result()
+		return client.HGet(ctx, args[0].(string), args[1].(string)).Result()
 	case "LINDEX":
 		if len(args) < 2 {
 			return nil, fmt.Errorf("LINDEX требует key и index")
 		}
 		index, _ := strconv.Atoi(args[1].(string))
-		return d.client.LIndex(ctx, args[0].(string), int64(index)).Result()
+		return client.LIndex(ctx, args[0].(string), int64(index)).Result()
 	case "SMEMBERS":
-		return d.client.SMembers(ctx, args[0].(string)).Result()
+		return client.SMembers(ctx, args[0].(string)).Result()
 	case "ZRANGE":
 		if len(args) < 3 {
 			return nil, fmt.Errorf("ZRANGE требует key, start и stop")
 		}
 		start, _ := strconv.Atoi(args[1].(string))
 		stop, _ := strconv.Atoi(args[2].(string))
-		return d.client.ZRange(ctx, args[0].(string), int64(start), int64(stop)).Result()
+		return client.ZRange(ctx, args[0].(string), int64(start), int64(stop)).Result()
 	}
 	return nil, fmt.Errorf("неподдерживаемая команда: %s", command)
 }
 
-func (d *RedisDriver) executeKeysCommand(ctx context.Context, command string, args []interface{}) (interface{}, error) {
+func executeKeysCommand(ctx context.Context, client redisCommander, command string, args []interface{}) (interface{}, error) {
 	if command == "KEYS" && len(args) > 0 {
-		return d.client.Keys(ctx, args[0].(string)).Result()
+		return client.Keys(ctx, args[0].(string)).Result()
 	}
-	return d.client.Keys(ctx, "*").Result()
+	return client.Keys(ctx, "*").Result()
 }
 
-func (d *RedisDriver) executeInfoCommand(ctx context.Context) (interface{}, error) {
-	info, err := d.client.Info(ctx).Result()
+func executeInfoCommand(ctx context.Context, client redisCommander) (interface{}, error) {
+	info, err := client.Info(ctx).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -266,8 +491,9 @@ func (d *RedisDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo,
 		size, err := client.DBSize(ctx).Result()
 		if err == nil {
 			databases = append(databases, models.DatabaseInfo{
-				Name: fmt.Sprintf("db%d", i),
-				Size: fmt.Sprintf("%d ключей", size),
+				Name:        fmt.Sprintf("db%d", i),
+				Size:        fmt.Sprintf("%d ключей", size),
+				ObjectCount: &size,
 			})
 		}
 	}
@@ -340,6 +566,74 @@ func (d *RedisDriver) DeleteTable(ctx context.Context, name string) error {
 	return d.client.Del(ctx, name).Err()
 }
 
+// TruncateTable для Redis удаляет ключи по шаблону (SCAN MATCH + DEL);
+// шаблон "*" очищает всю текущую базу через FLUSHDB
+func (d *RedisDriver) TruncateTable(ctx context.Context, name string) error {
+	if d.client == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if name == "*" {
+		return d.client.FlushDB(ctx).Err()
+	}
+
+	_, err := d.deleteKeys(ctx, name)
+	return err
+}
+
+// DeleteRows удаляет ключи по шаблону filter (синтаксис MATCH); table не
+// используется. Пустой filter очищает текущую базу целиком
+func (d *RedisDriver) DeleteRows(ctx context.Context, table, filter string, args []interface{}) (int64, error) {
+	if d.client == nil {
+		return 0, fmt.Errorf("подключение не установлено")
+	}
+	if len(args) > 0 {
+		return 0, fmt.Errorf("Redis не поддерживает параметры фильтра: укажите шаблон ключей в filter")
+	}
+
+	if filter == "" {
+		count, err := d.client.DBSize(ctx).Result()
+		if err != nil {
+			return 0, err
+		}
+		if err := d.client.FlushDB(ctx).Err(); err != nil {
+			return 0, err
+		}
+		return count, nil
+	}
+
+	return d.deleteKeys(ctx, filter)
+}
+
+// deleteKeys удаляет ключи по шаблону пачками по 1000 и возвращает число удаленных
+func (d *RedisDriver) deleteKeys(ctx context.Context, pattern string) (int64, error) {
+	var deleted int64
+	iter := d.client.Scan(ctx, 0, pattern, 1000).Iterator()
+	batch := make([]string, 0, 1000)
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			n, err := d.client.Del(ctx, batch...).Result()
+			if err != nil {
+				return deleted, fmt.Errorf("ошибка удаления ключей: %w", err)
+			}
+			deleted += n
+			batch = batch[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("ошибка поиска ключей: %w", err)
+	}
+	if len(batch) > 0 {
+		n, err := d.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return deleted, fmt.Errorf("ошибка удаления ключей: %w", err)
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
 func (d *RedisDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
 	return fmt.Errorf("Redis не поддерживает переименование ключей напрямую. Используйте команду RENAME")
 }
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"database-manager/models"
+	"sort"
+	"strings"
+)
+
+// DiffSchemas сравнивает схемы двух баз: таблица -> столбцы (как их
+// возвращает DescribeTable). Типы сравниваются без учета регистра. Таблицы
+// и столбцы только в одной базе и несовпадения типов и NULL возвращаются
+// в порядке имен таблиц и столбцов источника
+func DiffSchemas(source, target map[string][]models.TableColumn) *models.SchemaDiffResponse {
+	diff := &models.SchemaDiffResponse{
+		TablesOnlyInSource: []string{},
+		TablesOnlyInTarget: []string{},
+		Tables:             []models.TableSchemaDiff{},
+	}
+
+	for _, name := range sortedTableNames(source) {
+		targetColumns, ok := target[name]
+		if !ok {
+			diff.TablesOnlyInSource = append(diff.TablesOnlyInSource, name)
+			continue
+		}
+		tableDiff := diffTableColumns(name, source[name], targetColumns)
+		if len(tableDiff.ColumnsOnlyInSource) == 0 && len(tableDiff.ColumnsOnlyInTarget) == 0 && len(tableDiff.Mismatches) == 0 {
+			diff.IdenticalTables++
+			continue
+		}
+		diff.Tables = append(diff.Tables, tableDiff)
+	}
+	for _, name := range sortedTableNames(target) {
+		if _, ok := source[name]; !ok {
+			diff.TablesOnlyInTarget = append(diff.TablesOnlyInTarget, name)
+		}
+	}
+	return diff
+}
+
+func diffTableColumns(name string, source, target []models.TableColumn) models.TableSchemaDiff {
+	tableDiff := models.TableSchemaDiff{
+		Name:                name,
+		ColumnsOnlyInSource: []models.TableColumn{},
+		ColumnsOnlyInTarget: []models.TableColumn{},
+		Mismatches:          []models.ColumnMismatch{},
+	}
+
+	targetByName := make(map[string]models.TableColumn, len(target))
+	for _, col := range target {
+		targetByName[col.Name] = col
+	}
+	sourceNames := make(map[string]bool, len(source))
+	for _, col := range source {
+		sourceNames[col.Name] = true
+		targetCol, ok := targetByName[col.Name]
+		if !ok {
+			tableDiff.ColumnsOnlyInSource = append(tableDiff.ColumnsOnlyInSource, col)
+			continue
+		}
+		if !strings.EqualFold(col.Type, targetCol.Type) || col.Nullable != targetCol.Nullable {
+			tableDiff.Mismatches = append(tableDiff.Mismatches, models.ColumnMismatch{
+				Column:         col.Name,
+				SourceType:     col.Type,
+				TargetType:     targetCol.Type,
+				SourceNullable: col.Nullable,
+				TargetNullable: targetCol.Nullable,
+			})
+		}
+	}
+	for _, col := range target {
+		if !sourceNames[col.Name] {
+			tableDiff.ColumnsOnlyInTarget = append(tableDiff.ColumnsOnlyInTarget, col)
+		}
+	}
+	return tableDiff
+}
+
+func sortedTableNames(tables map[string][]models.TableColumn) []string {
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	source := map[string][]models.TableColumn{
+		"users": {
+			{Name: "id", Type: "integer"},
+			{Name: "email", Type: "text", Nullable: false},
+			{Name: "age", Type: "integer", Nullable: true},
+			{Name: "legacy", Type: "text", Nullable: true},
+		},
+		"orders":   {{Name: "id", Type: "bigint"}},
+		"sessions": {{Name: "id", Type: "uuid"}},
+	}
+	target := map[string][]models.TableColumn{
+		"users": {
+			{Name: "id", Type: "INTEGER"},
+			{Name: "email", Type: "text", Nullable: true},
+			{Name: "age", Type: "bigint", Nullable: true},
+			{Name: "phone", Type: "text", Nullable: true},
+		},
+		"orders":   {{Name: "id", Type: "bigint"}},
+		"payments": {{Name: "id", Type: "uuid"}},
+	}
+
+	diff := DiffSchemas(source, target)
+	if !reflect.DeepEqual(diff.TablesOnlyInSource, []string{"sessions"}) || !reflect.DeepEqual(diff.TablesOnlyInTarget, []string{"payments"}) {
+		t.Errorf("TablesOnlyInSource = %v, TablesOnlyInTarget = %v", diff.TablesOnlyInSource, diff.TablesOnlyInTarget)
+	}
+	if diff.IdenticalTables != 1 || len(diff.Tables) != 1 {
+		t.Fatalf("IdenticalTables = %d, Tables = %+v", diff.IdenticalTables, diff.Tables)
+	}
+
+	users := diff.Tables[0]
+	if users.Name != "users" {
+		t.Errorf("Name = %s", users.Name)
+	}
+	if len(users.ColumnsOnlyInSource) != 1 || users.ColumnsOnlyInSource[0].Name != "legacy" {
+		t.Errorf("ColumnsOnlyInSource = %+v", users.ColumnsOnlyInSource)
+	}
+	if len(users.ColumnsOnlyInTarget) != 1 || users.ColumnsOnlyInTarget[0].Name != "phone" {
+		t.Errorf("ColumnsOnlyInTarget = %+v", users.ColumnsOnlyInTarget)
+	}
+	want := []models.ColumnMismatch{
+		{Column: "email", SourceType: "text", TargetType: "text", SourceNullable: false, TargetNullable: true},
+		{Column: "age", SourceType: "integer", TargetType: "bigint", SourceNullable: true, TargetNullable: true},
+	}
+	if !reflect.DeepEqual(users.Mismatches, want) {
+		t.Errorf("Mismatches = %+v, want %+v", users.Mismatches, want)
+	}
+}
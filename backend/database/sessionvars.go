@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// postgresSessionVars - переменные сессии PostgreSQL, которые можно задать
+// для отдельного запроса. Список закрыт: параметры вроде role,
+// session_authorization или session_replication_role меняют права
+var postgresSessionVars = map[string]bool{
+	"statement_timeout":                   true,
+	"lock_timeout":                        true,
+	"idle_in_transaction_session_timeout": true,
+	"search_path":                         true,
+	"work_mem":                            true,
+	"timezone":                            true,
+	"datestyle":                           true,
+	"intervalstyle":                       true,
+	"extra_float_digits":                  true,
+	"application_name":                    true,
+	"transaction_read_only":               true,
+	"enable_seqscan":                      true,
+	"enable_indexscan":                    true,
+	"enable_bitmapscan":                   true,
+	"enable_hashjoin":                     true,
+	"enable_mergejoin":                    true,
+	"enable_nestloop":                     true,
+	"random_page_cost":                    true,
+	"jit":                                 true,
+}
+
+// validateSessionVars проверяет имена переменных по списку allowed (без
+// учета регистра) и возвращает их отсортированными
+func validateSessionVars(vars map[string]string, allowed map[string]bool) ([]string, error) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !allowed[strings.ToLower(name)] {
+			return nil, fmt.Errorf("переменную сессии %q задавать нельзя, допустимо: %s", name, strings.Join(sortedAllowed(allowed), ", "))
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func sortedAllowed(allowed map[string]bool) []string {
+	names := make([]string, 0, len(allowed))
+	for name := range allowed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
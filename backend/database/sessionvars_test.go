@@ -0,0 +1,26 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateSessionVars(t *testing.T) {
+	names, err := validateSessionVars(map[string]string{
+		"work_mem":          "64MB",
+		"Statement_Timeout": "5s",
+		"search_path":       "reports, public",
+	}, postgresSessionVars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Statement_Timeout", "search_path", "work_mem"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+
+	for _, name := range []string{"role", "session_authorization", "session_replication_role", ""} {
+		if _, err := validateSessionVars(map[string]string{name: "x"}, postgresSessionVars); err == nil {
+			t.Errorf("%q accepted", name)
+		}
+	}
+}
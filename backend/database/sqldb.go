@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sqlQuerier - общее у *sql.DB, *sql.Conn и *sql.Tx
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rowKeywords - запросы, которые возвращают строки; остальные (DML, DDL,
+// блоки PL/SQL) выполняются через Exec
+var rowKeywords = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"EXPLAIN":  true,
+	"VALUES":   true,
+	"PRAGMA":   true,
+}
+
+// runSQLQuery выполняет запрос драйвера database/sql и собирает ответ так же,
+// как runPostgresQuery: для изменений без RETURNING - число измененных строк
+func runSQLQuery(ctx context.Context, q sqlQuerier, query string, args ...interface{}) (*models.QueryResponse, error) {
+	startTime := time.Now()
+
+	if !rowKeywords[firstKeyword(query)] && !(isWriteStatement(query) && hasReturningClause(query)) {
+		result, err := q.ExecContext(ctx, query, args...)
+		if err != nil {
+			return &models.QueryResponse{
+				Error: err.Error(),
+			}, nil
+		}
+
+		affected, _ := result.RowsAffected()
+		return &models.QueryResponse{
+			Columns:       []string{},
+			Rows:          []map[string]interface{}{},
+			RowsAffected:  affected,
+			ExecutionTime: time.Since(startTime).Milliseconds(),
+		}, nil
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	maxRows := maxRowsFromContext(ctx)
+	truncated := false
+	rowsData := make([]map[string]interface{}, 0)
+	for rows.Next() {
+		// Следующая строка есть, а предел достигнут - дальше не читаем
+		if maxRows > 0 && len(rowsData) >= maxRows {
+			truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			continue
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			// Текстовые значения некоторые драйверы отдают как []byte
+			if b, ok := values[i].([]byte); ok {
+				row[col] = string(b)
+			} else {
+				row[col] = values[i]
+			}
+		}
+		rowsData = append(rowsData, row)
+	}
+	if err := rows.Err(); err != nil {
+		return &models.QueryResponse{
+			Error: err.Error(),
+		}, nil
+	}
+
+	return &models.QueryResponse{
+		Columns:       columns,
+		Rows:          rowsData,
+		RowCount:      len(rowsData),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+		Truncated:     truncated,
+	}, nil
+}
+
+// sqlColumnDefinition строит определение столбца для Oracle и SQLite.
+// DEFAULT идет перед NOT NULL: в Oracle другой порядок не допускается
+func sqlColumnDefinition(col models.TableColumn) (string, error) {
+	colDef := fmt.Sprintf("%s %s", col.Name, col.Type)
+	if col.Default != "" {
+		colDef += " DEFAULT " + col.Default
+	}
+	if !col.Nullable {
+		colDef += " NOT NULL"
+	}
+	if col.Unique && !col.PrimaryKey {
+		colDef += " UNIQUE"
+	}
+	if col.References != "" {
+		refTable, refColumn, err := parseColumnReference(col.References)
+		if err != nil {
+			return "", fmt.Errorf("колонка %s: %w", col.Name, err)
+		}
+		colDef += fmt.Sprintf(" REFERENCES %s (%s)", refTable, refColumn)
+	}
+	return colDef, nil
+}
+
+// sqlCreateTable строит CREATE TABLE для Oracle и SQLite: первичный ключ
+// (в том числе составной) и ограничения CHECK задаются на уровне таблицы
+func sqlCreateTable(name string, columns []models.TableColumn, constraints models.TableConstraints) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("необходимо указать хотя бы одну колонку")
+	}
+
+	primaryKey, err := tablePrimaryKey(columns, constraints)
+	if err != nil {
+		return "", err
+	}
+
+	cols := make([]string, 0, len(columns)+len(constraints.Constraints)+1)
+	for _, col := range columns {
+		colDef, err := sqlColumnDefinition(col)
+		if err != nil {
+			return "", err
+		}
+		cols = append(cols, "  "+colDef)
+	}
+	if len(primaryKey) > 0 {
+		cols = append(cols, fmt.Sprintf("  PRIMARY KEY (%s)", strings.Join(primaryKey, ", ")))
+	}
+	for _, check := range constraints.Constraints {
+		cols = append(cols, fmt.Sprintf("  CHECK (%s)", check))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n%s\n)", name, strings.Join(cols, ",\n")), nil
+}
@@ -0,0 +1,479 @@
+package database
+
+import (
+	"context"
+	"database-manager/models"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteDriver открывает файл базы через modernc.org/sqlite (без CGO).
+// Connection.Database - путь к файлу, host, port и учетные данные не используются
+type SQLiteDriver struct {
+	db   *sql.DB
+	conn models.Connection
+}
+
+func NewSQLiteDriver() *SQLiteDriver {
+	return &SQLiteDriver{}
+}
+
+func (d *SQLiteDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:       "sql",
+		SupportsCreateTable: true,
+		SupportsDeleteTable: true,
+		SupportsRename:      true,
+	}
+}
+
+// DefaultSQLiteDataDir - каталог файлов SQLite, если sqliteDataDir в
+// app.json не задан
+const DefaultSQLiteDataDir = "data/sqlite"
+
+var sqliteDataDir atomic.Value
+
+func init() {
+	sqliteDataDir.Store(DefaultSQLiteDataDir)
+}
+
+// SetSQLiteDataDir задает каталог, внутри которого должны лежать файлы
+// баз SQLite; пусто - DefaultSQLiteDataDir
+func SetSQLiteDataDir(dir string) {
+	if dir == "" {
+		dir = DefaultSQLiteDataDir
+	}
+	sqliteDataDir.Store(dir)
+}
+
+func (d *SQLiteDriver) Connect(ctx context.Context, conn models.Connection) error {
+	path, params := conn.Database, ""
+	if conn.DSN != "" {
+		if !strings.HasPrefix(conn.DSN, "file:") {
+			return fmt.Errorf("строка подключения SQLite должна начинаться с file:")
+		}
+		path, params, _ = strings.Cut(strings.TrimPrefix(conn.DSN, "file:"), "?")
+		unescaped, err := url.PathUnescape(path)
+		if err != nil {
+			return fmt.Errorf("неверный путь в строке подключения SQLite: %w", err)
+		}
+		path = unescaped
+	}
+	if path == "" {
+		return fmt.Errorf("укажите путь к файлу базы SQLite в поле database")
+	}
+
+	path, err := sqliteFilePath(sqliteDataDir.Load().(string), path)
+	if err != nil {
+		return err
+	}
+	dsn := sqliteDSN(path, conn.ReadOnly)
+	if params != "" {
+		// Параметры из строки подключения дополняют стандартные; режим
+		// только для чтения подключения не переопределяется
+		extra, err := url.ParseQuery(params)
+		if err != nil {
+			return fmt.Errorf("неверные параметры строки подключения SQLite: %w", err)
+		}
+		if conn.ReadOnly {
+			extra.Del("mode")
+		}
+		if len(extra) > 0 {
+			dsn += "&" + extra.Encode()
+		}
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия базы SQLite: %w", err)
+	}
+	// Одно соединение: запись в SQLite все равно последовательная,
+	// а :memory: у каждого соединения была бы своя
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("ошибка открытия базы SQLite: %w", err)
+	}
+
+	d.db = db
+	d.conn = conn
+	return nil
+}
+
+// sqliteFilePath возвращает абсолютный путь к файлу базы внутри каталога
+// dataDir: относительный путь отсчитывается от каталога, абсолютный должен
+// вести внутрь него, ".." запрещено. Иначе подключение открыло бы любой файл
+// сервера
+func sqliteFilePath(dataDir, path string) (string, error) {
+	if path == ":memory:" {
+		return path, nil
+	}
+	for _, part := range strings.Split(filepath.ToSlash(path), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("путь к файлу SQLite не может содержать ..")
+		}
+	}
+
+	base, err := filepath.Abs(dataDir)
+	if err != nil {
+		return "", fmt.Errorf("неверный каталог файлов SQLite %q: %w", dataDir, err)
+	}
+	full := path
+	if !filepath.IsAbs(full) {
+		full = filepath.Join(base, full)
+	}
+	full = filepath.Clean(full)
+	if rel, err := filepath.Rel(base, full); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("файл SQLite %s вне каталога %s", path, base)
+	}
+	return full, nil
+}
+
+// sqliteForbiddenStatement возвращает ошибку, если запрос содержит ATTACH
+// или VACUUM INTO: они открывают или создают произвольный файл сервера в
+// обход каталога файлов SQLite. Проверяется каждая команда запроса; строки,
+// идентификаторы в кавычках и комментарии пропускаются
+func sqliteForbiddenStatement(query string) error {
+	var words []string
+	check := func() error {
+		if len(words) == 0 {
+			return nil
+		}
+		switch words[0] {
+		case "ATTACH":
+			return fmt.Errorf("ATTACH недоступен: каждая база SQLite - отдельное подключение")
+		case "VACUUM":
+			for _, word := range words[1:] {
+				if word == "INTO" {
+					return fmt.Errorf("VACUUM INTO недоступен: запись в произвольный файл запрещена")
+				}
+			}
+		}
+		words = words[:0]
+		return nil
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(query[i+1:], closing)
+			if end < 0 {
+				return check()
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return check()
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return check()
+			}
+			i += end + 3
+		case c == ';':
+			if err := check(); err != nil {
+				return err
+			}
+		case c == '_' || (c|0x20 >= 'a' && c|0x20 <= 'z'):
+			start := i
+			for i+1 < len(query) && (query[i+1] == '_' || (query[i+1]|0x20 >= 'a' && query[i+1]|0x20 <= 'z') || (query[i+1] >= '0' && query[i+1] <= '9')) {
+				i++
+			}
+			words = append(words, strings.ToUpper(query[start:i+1]))
+		}
+	}
+	return check()
+}
+
+// sqliteDSN строит URI файла с включенными внешними ключами; подключение
+// только для чтения открывает файл в режиме ro
+func sqliteDSN(path string, readOnly bool) string {
+	if path == ":memory:" {
+		return "file::memory:?_pragma=foreign_keys(1)"
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	if readOnly {
+		params.Set("mode", "ro")
+	}
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + params.Encode()
+}
+
+func (d *SQLiteDriver) Disconnect(ctx context.Context) error {
+	if d.db != nil {
+		err := d.db.Close()
+		d.db = nil
+		return err
+	}
+	return nil
+}
+
+func (d *SQLiteDriver) IsConnected(ctx context.Context) bool {
+	if d.db == nil {
+		return false
+	}
+	return d.db.PingContext(ctx) == nil
+}
+
+func (d *SQLiteDriver) Ping(ctx context.Context) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+	return d.db.PingContext(ctx)
+}
+
+func (d *SQLiteDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+	if err := sqliteForbiddenStatement(query); err != nil {
+		return nil, err
+	}
+
+	return runSQLQuery(ctx, d.db, query)
+}
+
+func (d *SQLiteDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := fmt.Sprintf("SELECT * FROM %s LIMIT %d OFFSET %d", quoteSQLiteIdentifier(table), limit, offset)
+	return d.ExecuteQuery(ctx, query)
+}
+
+// База SQLite - файл подключения, поэтому операции над базами недоступны
+var (
+	errSQLiteCreateDatabase = errors.New("в SQLite база - это файл: создайте новое подключение с путем к файлу")
+	errSQLiteRenameDatabase = errors.New("SQLite не поддерживает переименование баз данных")
+	errSQLiteDropDatabase   = errors.New("SQLite не поддерживает удаление баз данных: удалите файл базы")
+)
+
+func (d *SQLiteDriver) CreateDatabase(ctx context.Context, name string, options map[string]interface{}) error {
+	return errSQLiteCreateDatabase
+}
+
+// ListDatabases возвращает базы соединения (main, temp и присоединенные
+// через ATTACH) с числом таблиц
+func (d *SQLiteDriver) ListDatabases(ctx context.Context) ([]models.DatabaseInfo, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	rows, err := d.db.QueryContext(ctx, "SELECT name, file FROM pragma_database_list ORDER BY seq")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка баз данных: %w", err)
+	}
+
+	databases := make([]models.DatabaseInfo, 0)
+	for rows.Next() {
+		var db models.DatabaseInfo
+		var file sql.NullString
+		if err := rows.Scan(&db.Name, &file); err != nil {
+			continue
+		}
+		databases = append(databases, db)
+	}
+	rows.Close()
+
+	for i := range databases {
+		var count int64
+		query := fmt.Sprintf("SELECT count(*) FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'", quoteSQLiteIdentifier(databases[i].Name))
+		if err := d.db.QueryRowContext(ctx, query).Scan(&count); err == nil {
+			databases[i].ObjectCount = &count
+		}
+	}
+
+	return databases, nil
+}
+
+func (d *SQLiteDriver) UpdateDatabase(ctx context.Context, oldName, newName string, options map[string]interface{}) error {
+	return errSQLiteRenameDatabase
+}
+
+func (d *SQLiteDriver) DeleteDatabase(ctx context.Context, name string) error {
+	return errSQLiteDropDatabase
+}
+
+func (d *SQLiteDriver) PlanCreateDatabase(name string, options map[string]interface{}) ([]string, error) {
+	return nil, errSQLiteCreateDatabase
+}
+
+func (d *SQLiteDriver) PlanUpdateDatabase(oldName, newName string, options map[string]interface{}) ([]string, error) {
+	return nil, errSQLiteRenameDatabase
+}
+
+func (d *SQLiteDriver) PlanDeleteDatabase(name string) ([]string, error) {
+	return nil, errSQLiteDropDatabase
+}
+
+func (d *SQLiteDriver) CreateTable(ctx context.Context, name string, columns []models.TableColumn) error {
+	return d.CreateTableWithConstraints(ctx, name, columns, models.TableConstraints{})
+}
+
+func (d *SQLiteDriver) CreateTableWithConstraints(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	query, err := sqlCreateTable(name, columns, constraints)
+	if err != nil {
+		return err
+	}
+
+	if _, err := d.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ошибка создания таблицы: %w", err)
+	}
+	return nil
+}
+
+func (d *SQLiteDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.db == nil {
+		return nil, fmt.Errorf("подключение не установлено")
+	}
+
+	query := "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name"
+	rows, err := d.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
+	}
+	defer rows.Close()
+
+	tables := make([]models.TableInfo, 0)
+	for rows.Next() {
+		table := models.TableInfo{Database: "main"}
+		if err := rows.Scan(&table.Name); err != nil {
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+func (d *SQLiteDriver) DeleteTable(ctx context.Context, name string) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	if _, err := d.db.ExecContext(ctx, sqliteDropTable(name)); err != nil {
+		return fmt.Errorf("ошибка удаления таблицы: %w", err)
+	}
+	return nil
+}
+
+func sqliteDropTable(name string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", name)
+}
+
+// UpdateTable выполняет изменения одной транзакцией, как в PostgreSQL.
+// Смену типа колонки SQLite не поддерживает
+func (d *SQLiteDriver) UpdateTable(ctx context.Context, oldName, newName string, columns []models.TableColumn) error {
+	if d.db == nil {
+		return fmt.Errorf("подключение не установлено")
+	}
+
+	changes, err := planTableChanges(oldName, newName, columns, "ALTER TABLE %s RENAME TO %s", sqliteAlterColumn)
+	if err != nil {
+		return err
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	if changes.rename != "" {
+		if _, err := tx.ExecContext(ctx, changes.rename); err != nil {
+			return fmt.Errorf("ошибка переименования таблицы: %w", err)
+		}
+	}
+
+	for i, col := range columns {
+		if _, err := tx.ExecContext(ctx, changes.columns[i]); err != nil {
+			return fmt.Errorf("ошибка изменения колонки %s: %w", col.Name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *SQLiteDriver) PlanCreateTable(ctx context.Context, name string, columns []models.TableColumn, constraints models.TableConstraints) ([]string, error) {
+	query, err := sqlCreateTable(name, columns, constraints)
+	if err != nil {
+		return nil, err
+	}
+	return []string{query}, nil
+}
+
+func (d *SQLiteDriver) PlanUpdateTable(oldName, newName string, columns []models.TableColumn) ([]string, error) {
+	changes, err := planTableChanges(oldName, newName, columns, "ALTER TABLE %s RENAME TO %s", sqliteAlterColumn)
+	if err != nil {
+		return nil, err
+	}
+	return changes.statements(), nil
+}
+
+func (d *SQLiteDriver) PlanDeleteTable(name string) ([]string, error) {
+	return []string{sqliteDropTable(name)}, nil
+}
+
+func sqliteAlterColumn(table string, col models.TableColumn) (string, error) {
+	switch columnOperation(col) {
+	case models.ColumnAdd:
+		colDef, err := sqlColumnDefinition(col)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, colDef), nil
+	case models.ColumnDrop:
+		return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, col.Name), nil
+	case models.ColumnAlter:
+		return "", fmt.Errorf("SQLite не поддерживает изменение типа колонки %s: пересоздайте таблицу", col.Name)
+	case models.ColumnRename:
+		if col.NewName == "" {
+			return "", fmt.Errorf("для переименования колонки %s укажите newName", col.Name)
+		}
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table, col.Name, col.NewName), nil
+	}
+	return "", fmt.Errorf("неизвестная операция %q для колонки %s", col.Operation, col.Name)
+}
+
+func quoteSQLiteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (d *SQLiteDriver) CreateUser(ctx context.Context, username, password, database string, permissions []string) error {
+	return fmt.Errorf("SQLite не поддерживает пользователей")
+}
+
+func (d *SQLiteDriver) ListUsers(ctx context.Context) ([]models.UserInfo, error) {
+	return nil, fmt.Errorf("SQLite не поддерживает пользователей")
+}
+
+func (d *SQLiteDriver) UpdateUser(ctx context.Context, username, password string, permissions []string) error {
+	return fmt.Errorf("SQLite не поддерживает пользователей")
+}
+
+func (d *SQLiteDriver) DeleteUser(ctx context.Context, username string) error {
+	return fmt.Errorf("SQLite не поддерживает пользователей")
+}
@@ -0,0 +1,93 @@
+package database
+
+import (
+	"database-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteDSN(t *testing.T) {
+	tests := []struct {
+		path     string
+		readOnly bool
+		want     string
+	}{
+		{"data/app.db", false, "file:data/app.db?_pragma=foreign_keys%281%29&_pragma=busy_timeout%285000%29"},
+		{"/var/lib/my db.sqlite", true, "file:/var/lib/my%20db.sqlite?_pragma=foreign_keys%281%29&_pragma=busy_timeout%285000%29&mode=ro"},
+		{":memory:", false, "file::memory:?_pragma=foreign_keys(1)"},
+	}
+
+	for _, tt := range tests {
+		if got := sqliteDSN(tt.path, tt.readOnly); got != tt.want {
+			t.Errorf("sqliteDSN(%q, %v) = %q, want %q", tt.path, tt.readOnly, got, tt.want)
+		}
+	}
+}
+
+func TestSQLiteAlterColumn(t *testing.T) {
+	got, err := sqliteAlterColumn("users", models.TableColumn{Name: "age", Type: "INTEGER", Default: "0"})
+	if err != nil || got != "ALTER TABLE users ADD COLUMN age INTEGER DEFAULT 0 NOT NULL" {
+		t.Errorf("add: got %q, %v", got, err)
+	}
+
+	got, err = sqliteAlterColumn("users", models.TableColumn{Name: "age", NewName: "years", Operation: models.ColumnRename})
+	if err != nil || got != "ALTER TABLE users RENAME COLUMN age TO years" {
+		t.Errorf("rename: got %q, %v", got, err)
+	}
+
+	if _, err := sqliteAlterColumn("users", models.TableColumn{Name: "age", Type: "TEXT", Operation: models.ColumnAlter}); err == nil {
+		t.Error("expected error for alter: SQLite cannot change column type")
+	}
+}
+
+func TestSQLiteFilePath(t *testing.T) {
+	base, err := filepath.Abs("data/sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"app.db", filepath.Join(base, "app.db"), false},
+		{"team/app.db", filepath.Join(base, "team", "app.db"), false},
+		{filepath.Join(base, "app.db"), filepath.Join(base, "app.db"), false},
+		{":memory:", ":memory:", false},
+		{"../app.db", "", true},
+		{"team/../../app.db", "", true},
+		{"/etc/passwd", "", true},
+		{base, "", true},
+		{base + "-other/app.db", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := sqliteFilePath("data/sqlite", tt.path)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("sqliteFilePath(%q) = %q, %v; want %q, error %v", tt.path, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestSQLiteForbiddenStatement(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr bool
+	}{
+		{"SELECT * FROM users", false},
+		{"SELECT 'ATTACH' AS word; VACUUM", false},
+		{`SELECT "vacuum into" FROM t -- ATTACH`, false},
+		{"ATTACH DATABASE '/etc/passwd' AS p", true},
+		{"  /* comment */ attach '/tmp/x.db' AS x", true},
+		{"SELECT 1; ATTACH '/tmp/x.db' AS x", true},
+		{"VACUUM INTO '/tmp/copy.db'", true},
+		{"VACUUM main INTO '/tmp/copy.db'", true},
+	}
+
+	for _, tt := range tests {
+		if err := sqliteForbiddenStatement(tt.query); (err != nil) != tt.wantErr {
+			t.Errorf("sqliteForbiddenStatement(%q) = %v, wantErr %v", tt.query, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package database
+
+import (
+	"database-manager/models"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var writeStatementKeywords = map[string]bool{
+	"INSERT": true,
+	"UPDATE": true,
+	"DELETE": true,
+	"MERGE":  true,
+	"UPSERT": true,
+}
+
+// readStatementKeywords - запросы, которые только читают данные. EXPLAIN
+// не входит: EXPLAIN ANALYZE выполняет запрос
+var readStatementKeywords = map[string]bool{
+	"SELECT":   true,
+	"SHOW":     true,
+	"DESCRIBE": true,
+	"DESC":     true,
+}
+
+// IsReadStatement определяет SQL-запрос на чтение по первому ключевому слову.
+// SELECT ... INTO создает таблицу или пишет в файл, а CTE в PostgreSQL может
+// изменять данные (WITH d AS (DELETE ...) SELECT ...), поэтому такие запросы
+// чтением не считаются. Несколько операторов через ; (SELECT 1; DELETE ...)
+// тоже не чтение: драйверы выполняют их все
+func IsReadStatement(query string) bool {
+	words := statementWords(query)
+	if multipleStatements(words) {
+		return false
+	}
+	switch firstKeyword(query) {
+	case "SELECT":
+		return !containsWord(words, "INTO")
+	case "WITH":
+		for _, word := range words {
+			if word == "INTO" || writeStatementKeywords[word] {
+				return false
+			}
+		}
+		return true
+	}
+	return readStatementKeywords[firstKeyword(query)]
+}
+
+// multipleStatements - после ; есть еще слова, то есть операторов больше одного
+func multipleStatements(words []string) bool {
+	separated := false
+	for _, word := range words {
+		if word == ";" {
+			separated = true
+		} else if separated {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWord(words []string, keyword string) bool {
+	for _, word := range words {
+		if word == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// statementWords возвращает слова запроса в верхнем регистре и разделители
+// операторов ";", пропуская строки, идентификаторы в кавычках и комментарии
+func statementWords(query string) []string {
+	var words []string
+	for i := 0; i < len(query); {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end == -1 {
+				return words
+			}
+			i += end + 2
+		case strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end == -1 {
+				return words
+			}
+			i += end + 1
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i:], "*/")
+			if end == -1 {
+				return words
+			}
+			i += end + 2
+		case c == ';':
+			words = append(words, ";")
+			i++
+		case c == '_' || unicode.IsLetter(firstRune(query[i:])):
+			end := strings.IndexFunc(query[i:], func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+			})
+			if end == -1 {
+				end = len(query) - i
+			}
+			words = append(words, strings.ToUpper(query[i:i+end]))
+			i += end
+		default:
+			i++
+		}
+	}
+	return words
+}
+
+// isWriteStatement определяет, изменяет ли запрос данные (INSERT, UPDATE, DELETE...),
+// по первому ключевому слову после пробелов и комментариев
+func isWriteStatement(query string) bool {
+	return writeStatementKeywords[firstKeyword(query)]
+}
+
+// hasReturningClause - запрос на изменение возвращает строки (RETURNING)
+func hasReturningClause(query string) bool {
+	for _, word := range strings.FieldsFunc(strings.ToUpper(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '_'
+	}) {
+		if word == "RETURNING" {
+			return true
+		}
+	}
+	return false
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func firstKeyword(query string) string {
+	s := query
+	for {
+		s = strings.TrimLeftFunc(s, func(r rune) bool {
+			return unicode.IsSpace(r) || r == '('
+		})
+		switch {
+		case strings.HasPrefix(s, "--"):
+			idx := strings.Index(s, "\n")
+			if idx == -1 {
+				return ""
+			}
+			s = s[idx+1:]
+		case strings.HasPrefix(s, "/*"):
+			idx := strings.Index(s, "*/")
+			if idx == -1 {
+				return ""
+			}
+			s = s[idx+2:]
+		default:
+			end := strings.IndexFunc(s, func(r rune) bool {
+				return !unicode.IsLetter(r)
+			})
+			if end == -1 {
+				end = len(s)
+			}
+			return strings.ToUpper(s[:end])
+		}
+	}
+}
+
+// tablePrimaryKey возвращает столбцы первичного ключа: из constraints.PrimaryKey
+// или, если он не задан, столбцы с флагом PrimaryKey. Ключ из primaryKey
+// должен ссылаться на существующие столбцы
+func tablePrimaryKey(columns []models.TableColumn, constraints models.TableConstraints) ([]string, error) {
+	if len(constraints.PrimaryKey) == 0 {
+		var keys []string
+		for _, col := range columns {
+			if col.PrimaryKey {
+				keys = append(keys, col.Name)
+			}
+		}
+		return keys, nil
+	}
+
+	names := make(map[string]bool, len(columns))
+	for _, col := range columns {
+		if col.PrimaryKey {
+			return nil, fmt.Errorf("первичный ключ задан и в колонке %s, и в primaryKey: используйте что-то одно", col.Name)
+		}
+		names[col.Name] = true
+	}
+
+	seen := make(map[string]bool, len(constraints.PrimaryKey))
+	for _, key := range constraints.PrimaryKey {
+		if !names[key] {
+			return nil, fmt.Errorf("колонка первичного ключа %s не найдена среди колонок таблицы", key)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("колонка %s указана в primaryKey дважды", key)
+		}
+		seen[key] = true
+	}
+	return constraints.PrimaryKey, nil
+}
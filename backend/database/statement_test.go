@@ -0,0 +1,114 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestIsWriteStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM users", false},
+		{"insert into users (name) values ('a')", true},
+		{"  UPDATE users SET name = 'b'", true},
+		{"DELETE FROM users WHERE id = 1", true},
+		{"-- комментарий\nDELETE FROM users", true},
+		{"/* a */ /* b */ update users set x = 1", true},
+		{"MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE", true},
+		{"WITH x AS (SELECT 1) SELECT * FROM x", false},
+		{"(SELECT 1)", false},
+		{"CREATE TABLE t (id int)", false},
+		{"-- только комментарий", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isWriteStatement(tt.query); got != tt.want {
+			t.Errorf("isWriteStatement(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestIsReadStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM users", true},
+		{"  -- отчет\nselect count(*) from orders", true},
+		{"SHOW TABLES", true},
+		{"(SELECT 1)", true},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", false},
+		{"with ins as (insert into t values (1) returning id) select id from ins", false},
+		{"WITH u AS (UPDATE t SET x = 1 RETURNING *) SELECT count(*) FROM u", false},
+		{"WITH x AS (SELECT 1) SELECT * INTO copy FROM x", false},
+		{"WITH x AS (SELECT 'delete' AS \"update\") SELECT * FROM x -- insert", true},
+		{"SELECT * INTO backup FROM users", false},
+		{"select id into outfile '/tmp/u' from users", false},
+		{"SELECT * FROM users WHERE note = 'into'", true},
+		{"SELECT updated_at, into_count FROM t", true},
+		{"SELECT 'x' … FROM таблица", true},
+		{"SELECT 1; DELETE FROM users", false},
+		{"select 1;delete from users", false},
+		{"SHOW TABLES; DROP TABLE users", false},
+		{"WITH x AS (SELECT 1) SELECT * FROM x; UPDATE t SET a = 1", false},
+		{"SELECT 1;", true},
+		{"SELECT 1; -- конец", true},
+		{"SELECT ';' AS sep, \"a;b\" FROM t /* ; DELETE */", true},
+		{"EXPLAIN ANALYZE DELETE FROM t", false},
+		{"INSERT INTO t VALUES (1)", false},
+		{"HGETALL user:1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsReadStatement(tt.query); got != tt.want {
+			t.Errorf("IsReadStatement(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHasReturningClause(t *testing.T) {
+	if !hasReturningClause("INSERT INTO t (a) VALUES (1) RETURNING id") {
+		t.Error("RETURNING не найден")
+	}
+	if hasReturningClause("UPDATE t SET returning_count = 1") {
+		t.Error("имя столбца не должно считаться RETURNING")
+	}
+}
+
+func TestTablePrimaryKey(t *testing.T) {
+	columns := []models.TableColumn{{Name: "tenant"}, {Name: "id"}, {Name: "name"}}
+
+	keys, err := tablePrimaryKey(columns, models.TableConstraints{PrimaryKey: []string{"tenant", "id"}})
+	if err != nil || !reflect.DeepEqual(keys, []string{"tenant", "id"}) {
+		t.Errorf("composite key: got %v, %v", keys, err)
+	}
+
+	flagged := []models.TableColumn{{Name: "id", PrimaryKey: true}, {Name: "name"}}
+	keys, err = tablePrimaryKey(flagged, models.TableConstraints{})
+	if err != nil || !reflect.DeepEqual(keys, []string{"id"}) {
+		t.Errorf("flagged key: got %v, %v", keys, err)
+	}
+
+	keys, err = tablePrimaryKey(columns, models.TableConstraints{})
+	if err != nil || len(keys) != 0 {
+		t.Errorf("no key: got %v, %v", keys, err)
+	}
+
+	invalid := []models.TableConstraints{
+		{PrimaryKey: []string{"missing"}},
+		{PrimaryKey: []string{"id", "id"}},
+	}
+	for _, c := range invalid {
+		if _, err := tablePrimaryKey(columns, c); err == nil {
+			t.Errorf("tablePrimaryKey(%v): expected error", c.PrimaryKey)
+		}
+	}
+	if _, err := tablePrimaryKey(flagged, models.TableConstraints{PrimaryKey: []string{"id"}}); err == nil {
+		t.Error("expected error when key is set both on column and in primaryKey")
+	}
+}
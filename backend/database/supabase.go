@@ -3,10 +3,19 @@ package database
 import (
 	"context"
 	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
 )
 
 type SupabaseDriver struct {
 	*PostgreSQLDriver
+	rest *postgrestClient
 }
 
 func NewSupabaseDriver() *SupabaseDriver {
@@ -15,7 +24,237 @@ func NewSupabaseDriver() *SupabaseDriver {
 	}
 }
 
+// Connect по умолчанию подключается к Postgres напрямую. Если задан RestURL,
+// драйвер работает через PostgREST (/rest/v1) - для проектов, где наружу
+// открыт только REST API
 func (d *SupabaseDriver) Connect(ctx context.Context, conn models.Connection) error {
-	return d.PostgreSQLDriver.Connect(ctx, conn)
+	if conn.RestURL == "" {
+		return d.PostgreSQLDriver.Connect(ctx, conn)
+	}
+
+	if conn.Password == "" {
+		return fmt.Errorf("ключ API (anon или service_role) не указан в поле password")
+	}
+
+	client, err := newHTTPClient(conn)
+	if err != nil {
+		return err
+	}
+
+	rest := &postgrestClient{
+		client:  client,
+		baseURL: strings.TrimRight(conn.RestURL, "/") + "/rest/v1",
+		apiKey:  conn.Password,
+	}
+
+	var spec map[string]interface{}
+	if err := rest.get(ctx, "/", &spec); err != nil {
+		return fmt.Errorf("ошибка подключения к Supabase REST API: %w", err)
+	}
+
+	d.rest = rest
+	d.conn = conn
+	return nil
+}
+
+func (d *SupabaseDriver) Disconnect(ctx context.Context) error {
+	if d.rest != nil {
+		d.rest = nil
+		return nil
+	}
+	return d.PostgreSQLDriver.Disconnect(ctx)
+}
+
+func (d *SupabaseDriver) IsConnected(ctx context.Context) bool {
+	if d.rest != nil {
+		return d.Ping(ctx) == nil
+	}
+	return d.PostgreSQLDriver.IsConnected(ctx)
+}
+
+func (d *SupabaseDriver) Ping(ctx context.Context) error {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.Ping(ctx)
+	}
+
+	var spec map[string]interface{}
+	return d.rest.get(ctx, "/", &spec)
+}
+
+// Capabilities в режиме PostgREST ограничены чтением таблиц
+func (d *SupabaseDriver) Capabilities() models.DriverCapabilities {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.Capabilities()
+	}
+	return postgrestCapabilities()
+}
+
+func postgrestCapabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		QueryLanguage:      "postgrest",
+		SupportsDataBrowse: true,
+	}
+}
+
+// ExecuteQuery в режиме PostgREST принимает путь запроса к таблице
+// с фильтрами в синтаксисе PostgREST, например:
+// users?select=id,email&age=gte.18&order=created_at.desc&limit=50
+func (d *SupabaseDriver) ExecuteQuery(ctx context.Context, query string) (*models.QueryResponse, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.ExecuteQuery(ctx, query)
+	}
+
+	startTime := time.Now()
+
+	path, err := postgrestPath(query)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}, nil
+	}
+
+	return d.rest.selectRows(ctx, path, startTime), nil
+}
+
+// postgrestPath строит путь запроса к /rest/v1 из "таблица?фильтры". Имя
+// таблицы - один сегмент пути: / и .. (в том числе закодированные) отклоняются,
+// чтобы запрос с ключом проекта не ушел за пределы /rest/v1
+func postgrestPath(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return "", fmt.Errorf("запрос пуст: укажите таблицу и фильтры, например users?select=*&id=eq.1")
+	}
+
+	table, filters, _ := strings.Cut(strings.TrimPrefix(query, "/"), "?")
+	name, err := url.PathUnescape(table)
+	if err != nil {
+		return "", fmt.Errorf("некорректное имя таблицы %q: %w", table, err)
+	}
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, "/\\") {
+		return "", fmt.Errorf("некорректное имя таблицы %q: укажите одну таблицу без / и ..", table)
+	}
+
+	path := "/" + url.PathEscape(name)
+	if filters != "" {
+		path += "?" + filters
+	}
+	return path, nil
+}
+
+func (d *SupabaseDriver) ExecuteQueryArgs(ctx context.Context, query string, args []interface{}) (*models.QueryResponse, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.ExecuteQueryArgs(ctx, query, args)
+	}
+	return nil, fmt.Errorf("параметры запроса не поддерживаются в режиме PostgREST: передайте фильтры в самом запросе")
+}
+
+func (d *SupabaseDriver) ExecuteQueryWithSessionVars(ctx context.Context, query string, args []interface{}, vars map[string]string) (*models.QueryResponse, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.ExecuteQueryWithSessionVars(ctx, query, args, vars)
+	}
+	return nil, fmt.Errorf("переменные сессии не поддерживаются в режиме PostgREST")
+}
+
+// OpenSession: у PostgREST нет состояния между запросами, поэтому
+// в этом режиме сессия просто выполняет ExecuteQuery
+func (d *SupabaseDriver) OpenSession(ctx context.Context) (QuerySession, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.OpenSession(ctx)
+	}
+	return statelessSession{d}, nil
+}
+
+func (d *SupabaseDriver) BrowseData(ctx context.Context, table string, limit, offset int) (*models.QueryResponse, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.BrowseData(ctx, table, limit, offset)
+	}
+
+	params := url.Values{}
+	params.Set("select", "*")
+	params.Set("limit", fmt.Sprintf("%d", limit))
+	params.Set("offset", fmt.Sprintf("%d", offset))
+
+	return d.rest.selectRows(ctx, "/"+url.PathEscape(table)+"?"+params.Encode(), time.Now()), nil
+}
+
+// ListTables в режиме PostgREST берёт таблицы и представления из OpenAPI
+// описания, которое PostgREST отдаёт на корневом пути
+func (d *SupabaseDriver) ListTables(ctx context.Context) ([]models.TableInfo, error) {
+	if d.rest == nil {
+		return d.PostgreSQLDriver.ListTables(ctx)
+	}
+
+	var spec struct {
+		Definitions map[string]interface{} `json:"definitions"`
+	}
+	if err := d.rest.get(ctx, "/", &spec); err != nil {
+		return nil, fmt.Errorf("ошибка получения списка таблиц: %w", err)
+	}
+
+	names := make([]string, 0, len(spec.Definitions))
+	for name := range spec.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tables := make([]models.TableInfo, 0, len(names))
+	for _, name := range names {
+		tables = append(tables, models.TableInfo{
+			Name:     name,
+			Database: d.conn.Database,
+			Size:     "N/A",
+		})
+	}
+
+	return tables, nil
 }
 
+type postgrestClient struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func (c *postgrestClient) get(ctx context.Context, path string, target interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	req.Header.Set("apikey", c.apiKey)
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("ошибка выполнения запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("статус %d, ответ: %s", resp.StatusCode, string(body))
+	}
+
+	if err := json.Unmarshal(body, target); err != nil {
+		return fmt.Errorf("ошибка парсинга ответа: %w", err)
+	}
+	return nil
+}
+
+func (c *postgrestClient) selectRows(ctx context.Context, path string, startTime time.Time) *models.QueryResponse {
+	var rows []map[string]interface{}
+	if err := c.get(ctx, path, &rows); err != nil {
+		return &models.QueryResponse{Error: fmt.Sprintf("ошибка выполнения запроса: %v", err)}
+	}
+
+	columns := newColumnSet()
+	for _, row := range rows {
+		columns.addKeys(row)
+	}
+
+	return &models.QueryResponse{
+		Columns:       columns.list(),
+		Rows:          rows,
+		RowCount:      len(rows),
+		ExecutionTime: time.Since(startTime).Milliseconds(),
+	}
+}
@@ -0,0 +1,34 @@
+package database
+
+import "testing"
+
+func TestPostgrestPath(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{"users?select=id,email&age=gte.18", "/users?select=id,email&age=gte.18", false},
+		{"/users", "/users", false},
+		{"  orders?limit=10 ", "/orders?limit=10", false},
+		{"user%20events?select=*", "/user%20events?select=*", false},
+		{"", "", true},
+		{"?select=*", "", true},
+		{"../auth/v1/admin/users", "", true},
+		{"..?select=*", "", true},
+		{"%2e%2e%2fauth%2fv1%2fusers", "", true},
+		{"users/../../auth/v1/users", "", true},
+		{`users\..\auth`, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := postgrestPath(tt.query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("postgrestPath(%q) error = %v, wantErr %v", tt.query, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("postgrestPath(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"database-manager/models"
+	"strings"
+)
+
+// FilterUsers применяет к полному списку пользователей поиск по подстроке
+// имени без учета регистра и пагинацию. Используется для драйверов без
+// UserSearcher
+func FilterUsers(users []models.UserInfo, filter models.UserFilter) *models.UserList {
+	search := strings.ToLower(filter.Search)
+	matched := make([]models.UserInfo, 0, len(users))
+	for _, user := range users {
+		if strings.Contains(strings.ToLower(user.Username), search) {
+			matched = append(matched, user)
+		}
+	}
+
+	list := &models.UserList{Users: []models.UserInfo{}, Total: len(matched)}
+	if filter.Offset >= len(matched) {
+		return list
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	list.Users = matched
+	return list
+}
+
+// splitGrants разделяет permissions на привилегии и роли. Привилегия -
+// значение, первое слово которого (до пробела или скобки со столбцами) есть
+// в privileges без учета регистра: "SELECT", "ALTER UPDATE", "SELECT(id)".
+// Остальные значения - имена ролей
+func splitGrants(permissions, privileges []string) (privs, roles []string) {
+	for _, permission := range permissions {
+		permission = strings.TrimSpace(permission)
+		if permission == "" {
+			continue
+		}
+		word := permission
+		if i := strings.IndexAny(word, " ("); i >= 0 {
+			word = word[:i]
+		}
+		isPrivilege := false
+		for _, privilege := range privileges {
+			if strings.EqualFold(word, privilege) {
+				isPrivilege = true
+				break
+			}
+		}
+		if isPrivilege {
+			privs = append(privs, permission)
+		} else {
+			roles = append(roles, permission)
+		}
+	}
+	return privs, roles
+}
+
+// privilegeGrantables - привилегии в виде элементов списка ListGrantableRoles
+func privilegeGrantables(privileges []string) []models.GrantableRole {
+	result := make([]models.GrantableRole, len(privileges))
+	for i, privilege := range privileges {
+		result[i] = models.GrantableRole{Name: privilege, Kind: models.GrantKindPrivilege}
+	}
+	return result
+}
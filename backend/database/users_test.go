@@ -0,0 +1,56 @@
+package database
+
+import (
+	"database-manager/models"
+	"reflect"
+	"testing"
+)
+
+func TestFilterUsers(t *testing.T) {
+	users := []models.UserInfo{
+		{Username: "admin"},
+		{Username: "app_reader"},
+		{Username: "App_Writer"},
+		{Username: "backup"},
+	}
+
+	tests := []struct {
+		filter models.UserFilter
+		want   []string
+		total  int
+	}{
+		{models.UserFilter{}, []string{"admin", "app_reader", "App_Writer", "backup"}, 4},
+		{models.UserFilter{Search: "APP"}, []string{"app_reader", "App_Writer"}, 2},
+		{models.UserFilter{Limit: 2, Offset: 1}, []string{"app_reader", "App_Writer"}, 4},
+		{models.UserFilter{Search: "app", Limit: 1}, []string{"app_reader"}, 2},
+		{models.UserFilter{Offset: 10}, []string{}, 4},
+		{models.UserFilter{Search: "root"}, []string{}, 0},
+	}
+
+	for _, tt := range tests {
+		list := FilterUsers(users, tt.filter)
+		if list.Total != tt.total {
+			t.Errorf("FilterUsers(%+v): total = %d, want %d", tt.filter, list.Total, tt.total)
+		}
+		got := make([]string, len(list.Users))
+		for i, user := range list.Users {
+			got[i] = user.Username
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("FilterUsers(%+v) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestSplitGrants(t *testing.T) {
+	privs, roles := splitGrants(
+		[]string{"select", "ALTER UPDATE", "SELECT(id, name)", "analysts", " ", "Readers"},
+		[]string{"SELECT", "ALTER"},
+	)
+	if want := []string{"select", "ALTER UPDATE", "SELECT(id, name)"}; !reflect.DeepEqual(privs, want) {
+		t.Errorf("privileges = %v, want %v", privs, want)
+	}
+	if want := []string{"analysts", "Readers"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("roles = %v, want %v", roles, want)
+	}
+}
@@ -18,6 +18,14 @@ func NewZookeeperDriver() *ZookeeperDriver {
 	return &ZookeeperDriver{}
 }
 
+func (d *ZookeeperDriver) Capabilities() models.DriverCapabilities {
+	return models.DriverCapabilities{
+		SupportsCreateDatabase: true,
+		SupportsCreateTable:    true,
+		SupportsDeleteTable:    true,
+	}
+}
+
 func (d *ZookeeperDriver) Connect(ctx context.Context, conn models.Connection) error {
 	servers := []string{fmt.Sprintf("%s:%s", conn.Host, conn.Port)}
 	
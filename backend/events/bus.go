@@ -0,0 +1,55 @@
+package events
+
+import (
+	"database-manager/models"
+	"sync"
+)
+
+const subscriberBuffer = 64
+
+// Bus - внутрипроцессная шина событий. Медленный подписчик не блокирует
+// публикацию: если его буфер заполнен, событие для него отбрасывается.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers map[chan models.Event]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[chan models.Event]struct{}),
+	}
+}
+
+// Subscribe возвращает канал событий и функцию отписки, которую
+// нужно вызвать, когда события больше не нужны
+func (b *Bus) Subscribe() (<-chan models.Event, func()) {
+	ch := make(chan models.Event, subscriberBuffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *Bus) Publish(event models.Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
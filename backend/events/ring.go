@@ -0,0 +1,46 @@
+package events
+
+import "sync"
+
+// Ring - потокобезопасный кольцевой буфер последних size элементов:
+// новый элемент вытесняет самый старый
+type Ring[T any] struct {
+	mu    sync.Mutex
+	items []T
+	next  int
+	full  bool
+}
+
+func NewRing[T any](size int) *Ring[T] {
+	if size < 1 {
+		size = 1
+	}
+	return &Ring[T]{items: make([]T, size)}
+}
+
+func (r *Ring[T]) Add(item T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items[r.next] = item
+	r.next = (r.next + 1) % len(r.items)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// Items возвращает копию элементов, от новых к старым
+func (r *Ring[T]) Items() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.items)
+	}
+	result := make([]T, 0, count)
+	for i := 1; i <= count; i++ {
+		result = append(result, r.items[(r.next-i+len(r.items))%len(r.items)])
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+)
+
+func ListActivityHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	monitor, release, ok := getActivityMonitor(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	activity, err := monitor.ListActivity(ctx)
+	if err != nil {
+		activityError(w, r, connectionID, "list-activity", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(activity)
+}
+
+func KillQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.KillQueryRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.PID <= 0 {
+		http.Error(w, "connectionId и pid обязательны", http.StatusBadRequest)
+		return
+	}
+
+	monitor, release, ok := getActivityMonitor(w, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := monitor.TerminateBackend(ctx, req.PID); err != nil {
+		activityError(w, r, req.ConnectionID, "kill-query", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"pid":     req.PID,
+	})
+}
+
+// activityError отвечает 403, если пользователю подключения не хватает прав
+// (роль clusterMonitor в MongoDB и т.п.), иначе 500
+func activityError(w http.ResponseWriter, r *http.Request, connectionID, operation string, err error) {
+	if errors.Is(err, database.ErrPermissionDenied) {
+		recordError(r, connectionID, operation, err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	driverError(w, r, connectionID, operation, err)
+}
+
+func getActivityMonitor(w http.ResponseWriter, connectionID string) (database.ActivityMonitor, func(), bool) {
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	monitor, ok := driver.(database.ActivityMonitor)
+	if !ok {
+		http.Error(w, "Просмотр активности не поддерживается для этого типа БД", http.StatusBadRequest)
+		release()
+		return nil, nil, false
+	}
+
+	return monitor, release, true
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// errAlreadyReviewed - запрос уже одобрен или отклонен
+var errAlreadyReviewed = errors.New("запрос уже рассмотрен")
+
+// errApprovalRequired - текст отказа для путей, где запрос нельзя поставить
+// в очередь подтверждения
+const errApprovalRequired = "Изменения в этом подключении требуют подтверждения администратора: отправьте запрос через /api/query"
+
+// approvalRequired сообщает, что запросы на изменение к подключению должны
+// ждать подтверждения: у подключения включен requireApproval, а
+// пользователь не администратор
+func approvalRequired(r *http.Request, connectionID string) bool {
+	conn, err := config.GetConnectionByID(connectionID)
+	if err != nil || !conn.RequireApproval {
+		return false
+	}
+	user, err := config.GetUserByID(r.Header.Get("UserID"))
+	return err != nil || !user.IsAdmin()
+}
+
+// checkWritable отвечает 403, если подключение только для чтения или
+// изменения в нем требуют подтверждения. false - ответ уже отправлен
+func checkWritable(w http.ResponseWriter, r *http.Request, connectionID string) bool {
+	if isReadOnlyConnection(connectionID) {
+		http.Error(w, "Подключение доступно только для чтения", http.StatusForbidden)
+		return false
+	}
+	if approvalRequired(r, connectionID) {
+		http.Error(w, errApprovalRequired, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// queueForApproval сохраняет запрос на изменение как ожидающий
+// подтверждения и отвечает 202 с его записью
+func queueForApproval(w http.ResponseWriter, r *http.Request, req models.QueryRequest) {
+	pending := models.PendingQuery{
+		ID:           uuid.New().String(),
+		ConnectionID: req.ConnectionID,
+		Query:        req.Query,
+		Params:       req.Params,
+		Username:     r.Header.Get("Username"),
+		Status:       models.ApprovalPending,
+		CreatedAt:    time.Now(),
+	}
+	if err := config.AddApproval(pending); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(pending)
+}
+
+// ListApprovalsHandler возвращает запросы на подтверждение: администратору -
+// все, остальным - свои. Параметры connectionId и status фильтруют список
+func ListApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	isAdmin := false
+	if user, err := config.GetUserByID(r.Header.Get("UserID")); err == nil {
+		isAdmin = user.IsAdmin()
+	}
+	username := r.Header.Get("Username")
+	connectionID := r.URL.Query().Get("connectionId")
+	status := models.ApprovalStatus(r.URL.Query().Get("status"))
+
+	result := []models.PendingQuery{}
+	for _, pending := range config.GetApprovals() {
+		if !isAdmin && pending.Username != username {
+			continue
+		}
+		if connectionID != "" && pending.ConnectionID != connectionID {
+			continue
+		}
+		if status != "" && pending.Status != status {
+			continue
+		}
+		result = append(result, pending)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ApproveQueryHandler одобряет запрос /api/query/approve/{id} и выполняет
+// его так же, как /api/query; возвращает результат выполнения
+func ApproveQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pending, ok := reviewPendingQuery(w, r, "/api/query/approve/", models.ApprovalApproved)
+	if !ok {
+		return
+	}
+
+	var result *models.QueryResponse
+	driver, release, err := connManager.AcquireDriver(pending.ConnectionID)
+	if err == nil {
+		defer release()
+		var target queryTarget
+		if target, err = newQueryTarget(pending.ConnectionID, driver); err == nil {
+			extendWriteDeadline(w, target.timeout)
+			result = runTargetQuery(r.Context(), target, pending.Query, pending.Params)
+			publishEvent(r, models.EventQuery, pending.ConnectionID, pending.Query)
+			config.RecordConnectionQuery(pending.ConnectionID)
+		}
+	}
+	if err != nil {
+		result = &models.QueryResponse{Error: err.Error()}
+	}
+	if result.Error != "" {
+		recordError(r, pending.ConnectionID, "approve", result.Error)
+	}
+
+	_, err = config.UpdateApproval(pending.ID, func(q *models.PendingQuery) error {
+		q.Status = models.ApprovalExecuted
+		if result.Error != "" {
+			q.Status = models.ApprovalFailed
+		}
+		q.RowsAffected = result.RowsAffected
+		q.Error = result.Error
+		return nil
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// RejectQueryHandler отклоняет запрос /api/query/reject/{id}
+func RejectQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pending, ok := reviewPendingQuery(w, r, "/api/query/reject/", models.ApprovalRejected)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pending)
+}
+
+// reviewPendingQuery переводит ожидающий запрос в status от имени текущего
+// администратора. Уже рассмотренный запрос - 409. false - ответ уже отправлен
+func reviewPendingQuery(w http.ResponseWriter, r *http.Request, prefix string, status models.ApprovalStatus) (*models.PendingQuery, bool) {
+	id := strings.TrimPrefix(r.URL.Path, prefix)
+	if id == "" {
+		http.Error(w, "ID запроса не указан", http.StatusBadRequest)
+		return nil, false
+	}
+
+	found := false
+	for _, pending := range config.GetApprovals() {
+		if pending.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("Запрос на подтверждение с ID %s не найден", id), http.StatusNotFound)
+		return nil, false
+	}
+
+	pending, err := config.UpdateApproval(id, func(q *models.PendingQuery) error {
+		if q.Status != models.ApprovalPending {
+			return fmt.Errorf("%w: %s", errAlreadyReviewed, q.Status)
+		}
+		now := time.Now()
+		q.Status = status
+		q.ReviewedBy = r.Header.Get("Username")
+		q.ReviewedAt = &now
+		return nil
+	})
+	if errors.Is(err, errAlreadyReviewed) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return nil, false
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	return pending, true
+}
+
+// rejectUnapprovedWrite возвращает ответ с ошибкой для запроса на изменение,
+// который нельзя выполнить без подтверждения; nil - запрос можно выполнять
+func rejectUnapprovedWrite(requireApproval bool, query string) *models.QueryResponse {
+	if requireApproval && !database.IsReadStatement(query) {
+		return &models.QueryResponse{Error: errApprovalRequired}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMutatingEndpointsRequireApproval(t *testing.T) {
+	dir := t.TempDir()
+	config.ConnectionsFile = filepath.Join(dir, "connections.json")
+	config.UsersFile = filepath.Join(dir, "users.json")
+	if err := config.SaveConnections([]models.Connection{{ID: "prod", Type: models.PostgreSQL, RequireApproval: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveUsers([]models.User{{ID: "u1", Username: "dev", Role: "user"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		handler http.HandlerFunc
+		method  string
+		url     string
+		body    string
+	}{
+		{CreateTableHandler, http.MethodPost, "/api/tables", `{"connectionId": "prod", "name": "t"}`},
+		{UpdateTableHandler, http.MethodPut, "/api/tables", `{"connectionId": "prod", "oldName": "t", "newName": "t2"}`},
+		{DeleteTableHandler, http.MethodDelete, "/api/tables?connectionId=prod&name=t&confirm=t", ""},
+		{CreateDatabaseHandler, http.MethodPost, "/api/databases", `{"connectionId": "prod", "name": "d"}`},
+		{UpdateDatabaseHandler, http.MethodPut, "/api/databases", `{"connectionId": "prod", "oldName": "d", "newName": "d2"}`},
+		{DeleteDatabaseHandler, http.MethodDelete, "/api/databases?connectionId=prod&name=d&confirm=d", ""},
+		{CreateUserHandler, http.MethodPost, "/api/users", `{"connectionId": "prod", "username": "x", "password": "p"}`},
+		{UpdateUserHandler, http.MethodPut, "/api/users", `{"connectionId": "prod", "username": "x", "password": "p"}`},
+		{DeleteUserHandler, http.MethodDelete, "/api/users?connectionId=prod&username=x", ""},
+		{RotatePasswordHandler, http.MethodPost, "/api/users/rotate-password", `{"connectionId": "prod", "username": "x"}`},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+		req.Header.Set("UserID", "u1")
+		rec := httptest.NewRecorder()
+		tt.handler(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.url, rec.Code, http.StatusForbidden)
+		}
+	}
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"database-manager/config"
+	"database-manager/middleware"
 	"database-manager/models"
 	"database-manager/utils"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
 	"time"
 
@@ -18,8 +21,7 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
@@ -76,20 +78,46 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	user, err := config.GetUserByUsername(req.Username)
-	if err != nil {
-		http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
-		return
-	}
-
-	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
-		return
+	var user *models.User
+	// С LDAP локальный пароль проверяется только у root: он остается
+	// доступен, даже если сервер LDAP недоступен или настроен неверно
+	if ldapCfg := config.GetAppConfig().LDAP; ldapCfg != nil && ldapCfg.Enabled && req.Username != models.RootUsername {
+		email, err := authenticateLDAP(ldapCfg, req.Username, req.Password)
+		if errors.Is(err, errInvalidCredentials) {
+			http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Printf("Ошибка входа через LDAP: %v", err)
+			http.Error(w, "Сервер LDAP недоступен", http.StatusBadGateway)
+			return
+		}
+
+		user, err = ldapUser(ldapCfg, req.Username, email)
+		if errors.Is(err, errLDAPUserConflict) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Ошибка сохранения пользователя", http.StatusInternalServerError)
+			return
+		}
+	} else {
+		var err error
+		user, err = config.GetUserByUsername(req.Username)
+		if err != nil {
+			http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
+			return
+		}
+
+		if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
+			http.Error(w, "Неверное имя пользователя или пароль", http.StatusUnauthorized)
+			return
+		}
 	}
 
 	token, err := utils.GenerateToken(*user)
@@ -108,3 +136,18 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// CSRFTokenHandler выдает CSRF-токен текущей сессии для заголовка X-CSRF-Token
+func CSRFTokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := models.CSRFTokenResponse{
+		Token:   utils.CSRFToken(middleware.SessionToken(r)),
+		Enabled: config.GetAppConfig().CSRFProtection,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"sync"
+)
+
+const (
+	// maxBatchQueries - предел числа запросов в одном пакете
+	maxBatchQueries = 100
+	// maxBatchConcurrency - предел одновременно выполняемых запросов пакета;
+	// сверх него действует еще и предел maxConcurrentQueries подключения
+	maxBatchConcurrency = 8
+)
+
+// ExecuteBatchQueryHandler выполняет набор независимых запросов к одному
+// подключению и возвращает массив QueryResponse в порядке запросов. Ошибка
+// одного запроса попадает в его error и не прерывает остальные; у каждого
+// запроса свой таймаут
+func ExecuteBatchQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.BatchQueryRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || len(req.Queries) == 0 {
+		http.Error(w, "connectionId и queries обязательны", http.StatusBadRequest)
+		return
+	}
+	if len(req.Queries) > maxBatchQueries {
+		http.Error(w, fmt.Sprintf("Слишком много запросов в пакете: %d, допустимо не больше %d", len(req.Queries), maxBatchQueries), http.StatusBadRequest)
+		return
+	}
+	if req.Concurrency < 0 || req.Concurrency > maxBatchConcurrency {
+		http.Error(w, fmt.Sprintf("concurrency должен быть от 1 до %d", maxBatchConcurrency), http.StatusBadRequest)
+		return
+	}
+	concurrency := req.Concurrency
+	if concurrency == 0 {
+		concurrency = 1
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	target, err := newQueryTarget(req.ConnectionID, driver)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Одновременно идут не больше concurrency запросов, каждый - не дольше таймаута
+	rounds := (len(req.Queries) + concurrency - 1) / concurrency
+	extendWriteDeadline(w, time.Duration(rounds)*target.timeout)
+
+	requireApproval := approvalRequired(r, req.ConnectionID)
+	results := make([]*models.QueryResponse, len(req.Queries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, query := range req.Queries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, query models.BatchQuery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if rejected := rejectUnapprovedWrite(requireApproval, query.Query); rejected != nil {
+				results[i] = rejected
+				return
+			}
+			results[i] = runTargetQuery(r.Context(), target, query.Query, query.Params)
+			if results[i].Error != "" {
+				recordError(r, req.ConnectionID, "batch", results[i].Error)
+			}
+			recordSlowQuery(r, req.ConnectionID, query.Query, results[i], target.redactor)
+			publishEvent(r, models.EventQuery, req.ConnectionID, query.Query)
+			config.RecordConnectionQuery(req.ConnectionID)
+		}(i, query)
+	}
+	wg.Wait()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// decodeJSONBody разбирает JSON из тела запроса, ограничивая его размер
+// параметром maxBodyBytes конфигурации. При ошибке отвечает клиенту сам.
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, config.GetAppConfig().BodyLimit())
+
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, "Слишком большое тело запроса", http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+		return false
+	}
+
+	return true
+}
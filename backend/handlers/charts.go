@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ListChartsHandler возвращает графики текущего пользователя; параметр
+// queryId оставляет графики одного сохраненного запроса
+func ListChartsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	queryID := r.URL.Query().Get("queryId")
+	result := []models.Chart{}
+	for _, chart := range config.GetChartsByUser(r.Header.Get("UserID")) {
+		if queryID == "" || chart.QueryID == queryID {
+			result = append(result, chart)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func GetChartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/charts/")
+	chart, err := config.GetChartByID(r.Header.Get("UserID"), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chart)
+}
+
+func CreateChartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ChartRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := validateChartRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	chart := models.Chart{
+		ID:        uuid.New().String(),
+		UserID:    r.Header.Get("UserID"),
+		CreatedAt: now,
+	}
+	applyChartRequest(&chart, req, now)
+
+	if err := config.AddChart(chart); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(chart)
+}
+
+func UpdateChartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/charts/")
+	existing, err := config.GetChartByID(r.Header.Get("UserID"), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var req models.ChartRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := validateChartRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	applyChartRequest(existing, req, time.Now())
+	if err := config.UpdateChart(*existing); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(existing)
+}
+
+func DeleteChartHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/charts/")
+	if err := config.DeleteChart(r.Header.Get("UserID"), id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+func applyChartRequest(chart *models.Chart, req models.ChartRequest, now time.Time) {
+	chart.Name = strings.TrimSpace(req.Name)
+	chart.QueryID = req.QueryID
+	chart.ConnectionID = req.ConnectionID
+	chart.Query = req.Query
+	chart.ChartType = req.ChartType
+	chart.XColumn = req.XColumn
+	chart.YColumns = req.YColumns
+	chart.UpdatedAt = now
+}
+
+func validateChartRequest(req models.ChartRequest) error {
+	if req.QueryID == "" {
+		return fmt.Errorf("queryId обязателен")
+	}
+	if !models.ChartTypes[req.ChartType] {
+		return fmt.Errorf("неверный chartType %q, допустимо: line, bar, area, pie, scatter", req.ChartType)
+	}
+	if req.XColumn == "" {
+		return fmt.Errorf("xColumn обязателен")
+	}
+	if len(req.YColumns) == 0 {
+		return fmt.Errorf("yColumns должен содержать хотя бы один столбец")
+	}
+	for _, column := range req.YColumns {
+		if column == "" {
+			return fmt.Errorf("yColumns не может содержать пустое имя столбца")
+		}
+	}
+	if req.ChartType == "pie" && len(req.YColumns) != 1 {
+		return fmt.Errorf("для круговой диаграммы нужен ровно один столбец в yColumns")
+	}
+	if req.ConnectionID != "" {
+		if _, err := config.GetConnectionByID(req.ConnectionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/database"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+func ClusterHealthHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	checker, ok := driver.(database.ClusterHealthChecker)
+	if !ok {
+		http.Error(w, "Состояние кластера не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	health, err := checker.ClusterHealth(ctx)
+	if err != nil {
+		recordError(r, connectionID, "cluster-health", err.Error())
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(health)
+}
+
+// CockroachInfoHandler отдает сведения о кластере CockroachDB; с параметром
+// table дополнительно возвращает SHOW RANGES FROM TABLE
+func CockroachInfoHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	inspector, ok := driver.(database.CockroachInspector)
+	if !ok {
+		http.Error(w, "Сведения о кластере доступны только для CockroachDB", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	info, err := inspector.ClusterInfo(ctx)
+	if err != nil {
+		driverError(w, r, connectionID, "cluster-info", err)
+		return
+	}
+
+	if table := r.URL.Query().Get("table"); table != "" {
+		ranges, err := inspector.TableRanges(ctx, table)
+		if err != nil {
+			driverError(w, r, connectionID, "cluster-info", err)
+			return
+		}
+		info.Ranges = ranges
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(info)
+}
@@ -5,8 +5,12 @@ import (
 	"database-manager/config"
 	"database-manager/database"
 	"database-manager/models"
+	"database-manager/utils"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -31,9 +35,10 @@ func GetConnectionsHandler(w http.ResponseWriter, r *http.Request) {
 	result := make([]models.Connection, len(connections))
 	copy(result, connections)
 	
+	statuses := connManager.ConnectionStatuses()
 	for i := range result {
-		result[i].Password = ""
-		result[i].Connected = connManager.IsConnected(result[i].ID)
+		result[i].HideSecrets()
+		result[i].Connected = statuses[result[i].ID]
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -58,7 +63,7 @@ func GetConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	// Если параметра нет, пароль скрываем для безопасности
 	includePassword := r.URL.Query().Get("edit") == "true"
 	if !includePassword {
-		conn.Password = ""
+		conn.HideSecrets()
 	}
 	
 	conn.Connected = connManager.IsConnected(id)
@@ -67,6 +72,26 @@ func GetConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(conn)
 }
 
+// connectionFieldErrors проверяет параметры подключения, включая правила
+// redactColumns, которые разбирает пакет database
+func connectionFieldErrors(conn *models.Connection) []models.FieldError {
+	fields := conn.Validate()
+	if _, err := database.NewColumnRedactor(conn.RedactColumns); err != nil {
+		fields = append(fields, models.FieldError{Field: "redactColumns", Message: err.Error()})
+	}
+	return fields
+}
+
+// writeValidationError отвечает 400 с ошибками по полям
+func writeValidationError(w http.ResponseWriter, fields []models.FieldError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(models.ValidationErrorResponse{
+		Error:  models.FieldErrorsSummary(fields),
+		Fields: fields,
+	})
+}
+
 func CreateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
@@ -74,14 +99,17 @@ func CreateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var conn models.Connection
-	if err := json.NewDecoder(r.Body).Decode(&conn); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &conn) {
 		return
 	}
 
-	// Проверяем, что пароль передан
-	if conn.Password == "" {
-		http.Error(w, "Пароль обязателен для создания подключения", http.StatusBadRequest)
+	fields := connectionFieldErrors(&conn)
+	// Проверяем, что пароль передан (при DSN пароль может быть в строке подключения)
+	if conn.Password == "" && conn.DSN == "" && !conn.Type.IsFileBased() {
+		fields = append(fields, models.FieldError{Field: "password", Message: "пароль обязателен для создания подключения"})
+	}
+	if len(fields) > 0 {
+		writeValidationError(w, fields)
 		return
 	}
 
@@ -89,6 +117,9 @@ func CreateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	conn.Connected = false
 	conn.CreatedAt = time.Now()
 	conn.UpdatedAt = time.Now()
+	conn.LastConnectedAt = nil
+	conn.LastQueryAt = nil
+	conn.QueryCount = 0
 
 	// Сохраняем пароль для использования
 	savedPassword := conn.Password
@@ -105,7 +136,7 @@ func CreateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, saveErr.Error(), http.StatusInternalServerError)
 			return
 		}
-		conn.Password = ""
+		conn.HideSecrets()
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -126,7 +157,7 @@ func CreateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	conn.Password = ""
+	conn.HideSecrets()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(conn)
@@ -149,14 +180,17 @@ func UpdateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var conn models.Connection
-	if err := json.NewDecoder(r.Body).Decode(&conn); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &conn) {
 		return
 	}
 
 	conn.ID = id
 	conn.CreatedAt = existingConn.CreatedAt
 	conn.UpdatedAt = time.Now()
+	// Статистику использования ведет сервер, из запроса ее не берем
+	conn.LastConnectedAt = existingConn.LastConnectedAt
+	conn.LastQueryAt = existingConn.LastQueryAt
+	conn.QueryCount = existingConn.QueryCount
 	
 	// Сохраняем значения из существующего подключения, если новые не указаны
 	// Используем значения из запроса, если они переданы, иначе берем из существующего
@@ -183,13 +217,30 @@ func UpdateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	if conn.Password == "" {
 		conn.Password = existingConn.Password
 	}
-	// SSL сохраняем как есть из запроса (false тоже валидное значение)
+	// Ключ mTLS в PEM клиенту не отдается - пустой означает "не менять"
+	if conn.ClientKey == "" && conn.ClientCert != "" {
+		conn.ClientKey = existingConn.ClientKey
+	}
+	// Клиент получает DSN со скрытым паролем - если он не изменился, оставляем исходный
+	if conn.DSN != "" && conn.DSN == models.RedactDSN(existingConn.DSN) {
+		conn.DSN = existingConn.DSN
+	}
+	// Проверяем уже объединенные значения: незаполненные поля взяты из существующего подключения
+	if fields := connectionFieldErrors(&conn); len(fields) > 0 {
+		writeValidationError(w, fields)
+		return
+	}
+	// SSL и SkipTLSVerify сохраняем как есть из запроса (false тоже валидное значение)
 
 	// Если подключение активно, отключаем его перед обновлением
 	if connManager.IsConnected(id) {
-		connManager.Disconnect(id)
+		if err := connManager.Disconnect(id); errors.Is(err, database.ErrDriverBusy) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
 		conn.Connected = false
 	}
+	queryCache.invalidate(id)
 
 	// Пробуем подключиться для проверки новых параметров
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
@@ -202,7 +253,7 @@ func UpdateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
-		conn.Password = ""
+		conn.HideSecrets()
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"connection": conn,
@@ -221,7 +272,7 @@ func UpdateConnectionHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	conn.Password = ""
+	conn.HideSecrets()
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(conn)
 }
@@ -235,16 +286,116 @@ func DeleteConnectionHandler(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
 	id := strings.TrimPrefix(path, "/api/connections/")
 	
+	if status, err := deleteConnection(id); err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// deleteConnection отключает подключение, если оно активно, и удаляет его.
+// Возвращает HTTP-статус ошибки: 409 - через подключение еще выполняются
+// запросы, 404 - подключения нет
+func deleteConnection(id string) (int, error) {
 	if connManager.IsConnected(id) {
-		connManager.Disconnect(id)
+		if err := connManager.Disconnect(id); errors.Is(err, database.ErrDriverBusy) {
+			return http.StatusConflict, err
+		}
 	}
 
 	if err := config.DeleteConnection(id); err != nil {
+		return http.StatusNotFound, err
+	}
+	return http.StatusNoContent, nil
+}
+
+// BulkDeleteConnectionsHandler удаляет несколько подключений так же, как
+// DeleteConnectionHandler, и возвращает результат по каждому ID; ошибка
+// одного подключения не прерывает удаление остальных
+func BulkDeleteConnectionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.BulkDeleteConnectionsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+	if len(req.IDs) == 0 {
+		http.Error(w, "ids не указаны", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]models.ConnectionOperationResult, 0, len(req.IDs))
+	seen := make(map[string]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		result := models.ConnectionOperationResult{ID: id, Success: true}
+		if _, err := deleteConnection(id); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
+	})
+}
+
+// CloneConnectionHandler копирует подключение со всеми полями, включая
+// сохраненный пароль, под новым ID и именем с суффиксом " (copy)"
+func CloneConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/connections/")
+	id = strings.TrimSuffix(id, "/clone")
+
+	source, err := config.GetConnectionByID(id)
+	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
+	clone := *source
+	if source.Pool != nil {
+		pool := *source.Pool
+		clone.Pool = &pool
+	}
+
+	usedNames := make(map[string]bool)
+	for _, conn := range config.GetConnections() {
+		usedNames[conn.Name] = true
+	}
+
+	clone.ID = uuid.New().String()
+	clone.Name = uniqueConnectionName(source.Name+" (copy)", usedNames)
+	clone.Connected = false
+	clone.CreatedAt = time.Now()
+	clone.UpdatedAt = time.Now()
+	clone.LastConnectedAt = nil
+	clone.LastQueryAt = nil
+	clone.QueryCount = 0
+
+	if err := config.AddConnection(clone); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clone.HideSecrets()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(clone)
 }
 
 func ConnectHandler(w http.ResponseWriter, r *http.Request) {
@@ -267,7 +418,7 @@ func ConnectHandler(w http.ResponseWriter, r *http.Request) {
 	connCopy := *conn
 	
 	// Проверяем, что пароль присутствует
-	if connCopy.Password == "" {
+	if connCopy.Password == "" && connCopy.DSN == "" && !connCopy.Type.IsFileBased() {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(map[string]interface{}{
@@ -294,8 +445,11 @@ func ConnectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Обновляем статус подключения, сохраняя пароль
+	connectedAt := time.Now()
 	connCopy.Connected = true
+	connCopy.LastConnectedAt = &connectedAt
 	config.UpdateConnection(id, connCopy)
+	publishEvent(r, models.EventConnect, id, connCopy.Name)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -315,7 +469,11 @@ func DisconnectHandler(w http.ResponseWriter, r *http.Request) {
 	id = strings.TrimSuffix(id, "/disconnect")
 
 	if err := connManager.Disconnect(id); err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		status := http.StatusNotFound
+		if errors.Is(err, database.ErrDriverBusy) {
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -327,6 +485,7 @@ func DisconnectHandler(w http.ResponseWriter, r *http.Request) {
 		conn.Password = savedPassword
 		config.UpdateConnection(id, *conn)
 	}
+	publishEvent(r, models.EventDisconnect, id, "")
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -353,3 +512,400 @@ func ConnectionStatusHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// ConnectionCapabilitiesHandler возвращает набор операций, которые
+// поддерживает подключение, чтобы клиент скрыл недоступные действия
+func ConnectionCapabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/connections/")
+	id = strings.TrimSuffix(id, "/capabilities")
+
+	conn, err := config.GetConnectionByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	caps, ok := database.ConnectionCapabilities(*conn)
+	if !ok {
+		http.Error(w, fmt.Sprintf("Неподдерживаемый тип БД: %q", conn.Type), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(caps)
+}
+
+// DatabaseTypesHandler возвращает поддерживаемые типы БД с портом по
+// умолчанию, обязательными полями и возможностями драйвера для формы
+// подключения
+func DatabaseTypesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(database.DatabaseTypes())
+}
+
+// ConnectionsStatusHandler возвращает состояние всех подключений одним
+// ответом: {"<id>": true, ...}. Подключения проверяются параллельно
+func ConnectionsStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	statuses := connManager.ConnectionStatuses()
+	connections := config.GetConnections()
+	result := make(map[string]bool, len(connections))
+	for _, conn := range connections {
+		result[conn.ID] = statuses[conn.ID]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// RestoreReportHandler показывает, какие подключения удалось восстановить
+// при запуске сервера и по какой причине остальные остались отключенными
+func RestoreReportHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(connManager.RestoreReport())
+}
+
+// PingConnectionHandler измеряет время ответа БД на Ping
+func PingConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := r.URL.Path
+	id := strings.TrimPrefix(path, "/api/connections/")
+	id = strings.TrimSuffix(id, "/ping")
+
+	response := models.PingResponse{ID: id}
+
+	driver, release, err := connManager.AcquireDriver(id)
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		defer release()
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		startTime := time.Now()
+		err := driver.Ping(ctx)
+		response.LatencyMs = time.Since(startTime).Milliseconds()
+		if err != nil {
+			response.Error = err.Error()
+		} else {
+			response.Connected = true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// UseDatabaseHandler переключает живое подключение на другую базу.
+// С persist: true выбор сохраняется в connections.json и
+// применяется при следующих подключениях
+func UseDatabaseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/connections/")
+	id = strings.TrimSuffix(id, "/use")
+
+	var req models.UseDatabaseRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Database == "" {
+		http.Error(w, "database обязателен", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	_, ok := driver.(database.DatabaseSwitcher)
+	release()
+	if !ok {
+		http.Error(w, "Переключение базы не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := config.GetConnectionByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	// Живой драйвер не меняется: менеджер подключает новый к базе
+	// req.Database и подменяет им прежний
+	if err := connManager.UseDatabase(ctx, *conn, req.Database); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if req.Persist {
+		updated := *conn
+		updated.Database = req.Database
+		if err := config.UpdateConnection(id, updated); err != nil {
+			http.Error(w, "Ошибка сохранения подключения", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":   true,
+		"id":        id,
+		"database":  req.Database,
+		"persisted": req.Persist,
+	})
+}
+
+const (
+	// connectionsExportVersion 2 - ключ из парольной фразы через scrypt с
+	// солью из encryption; версия 1 (SHA-256 фразы) только импортируется
+	connectionsExportVersion = 2
+	maxImportSize            = 10 << 20
+)
+
+// Парольная фраза для шифрования паролей передается заголовком,
+// чтобы не попадать в логи прокси вместе с URL
+const exportPassphraseHeader = "X-Export-Passphrase"
+
+func ExportConnectionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Без парольной фразы пароли и DSN пришлось бы скрыть, и файл нельзя
+	// было бы импортировать обратно
+	passphrase := r.Header.Get(exportPassphraseHeader)
+	if passphrase == "" {
+		http.Error(w, "Укажите парольную фразу в заголовке "+exportPassphraseHeader+": пароли и DSN экспортируются только зашифрованными", http.StatusBadRequest)
+		return
+	}
+
+	salt, err := utils.NewSalt()
+	if err != nil {
+		http.Error(w, "Ошибка шифрования пароля", http.StatusInternalServerError)
+		return
+	}
+	key, err := utils.DeriveKey(passphrase, salt, utils.ScryptN, utils.ScryptR, utils.ScryptP)
+	if err != nil {
+		http.Error(w, "Ошибка шифрования пароля", http.StatusInternalServerError)
+		return
+	}
+
+	connections := config.GetConnections()
+	export := models.ConnectionsExport{
+		Version:    connectionsExportVersion,
+		ExportedAt: time.Now(),
+		Encryption: &models.ExportEncryption{
+			KDF:  utils.KDFScrypt,
+			Salt: base64.StdEncoding.EncodeToString(salt),
+			N:    utils.ScryptN,
+			R:    utils.ScryptR,
+			P:    utils.ScryptP,
+		},
+		Connections: make([]models.ExportedConnection, 0, len(connections)),
+	}
+
+	for _, conn := range connections {
+		exported := models.ExportedConnection{Connection: conn}
+		exported.HideSecrets()
+		exported.Connected = false
+
+		if conn.Password != "" {
+			encrypted, err := utils.EncryptString(conn.Password, key)
+			if err != nil {
+				http.Error(w, "Ошибка шифрования пароля", http.StatusInternalServerError)
+				return
+			}
+			exported.EncryptedPassword = encrypted
+		}
+
+		// DSN может содержать пароль, поэтому экспортируется так же, как пароль
+		if conn.DSN != "" {
+			encrypted, err := utils.EncryptString(conn.DSN, key)
+			if err != nil {
+				http.Error(w, "Ошибка шифрования DSN", http.StatusInternalServerError)
+				return
+			}
+			exported.EncryptedDSN = encrypted
+		}
+
+		export.Connections = append(export.Connections, exported)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"connections.json\"")
+	json.NewEncoder(w).Encode(export)
+}
+
+func ImportConnectionsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	// Принимаем как файл из multipart-формы (поле file), так и JSON в теле запроса
+	var source io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "Файл не передан", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		source = file
+	}
+
+	var export models.ConnectionsExport
+	if err := json.NewDecoder(source).Decode(&export); err != nil {
+		http.Error(w, "Ошибка парсинга файла импорта", http.StatusBadRequest)
+		return
+	}
+
+	if export.Version < 1 || export.Version > connectionsExportVersion {
+		http.Error(w, fmt.Sprintf("Неподдерживаемая версия файла импорта: %d", export.Version), http.StatusBadRequest)
+		return
+	}
+
+	var key []byte
+	if passphrase := r.Header.Get(exportPassphraseHeader); passphrase != "" {
+		var err error
+		key, err = exportKey(export, passphrase)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	usedNames := make(map[string]bool)
+	for _, conn := range config.GetConnections() {
+		usedNames[conn.Name] = true
+	}
+
+	imported := make([]models.Connection, 0, len(export.Connections))
+	warnings := make([]string, 0)
+	for _, item := range export.Connections {
+		conn := item.Connection
+		if !conn.Type.IsSupported() {
+			warnings = append(warnings, fmt.Sprintf("%s: неподдерживаемый тип БД %q, пропущено", conn.Name, conn.Type))
+			continue
+		}
+
+		conn.ID = uuid.New().String()
+		conn.Connected = false
+		conn.CreatedAt = time.Now()
+		conn.UpdatedAt = time.Now()
+		conn.Name = uniqueConnectionName(conn.Name, usedNames)
+		usedNames[conn.Name] = true
+
+		if item.EncryptedPassword != "" {
+			if key == nil {
+				warnings = append(warnings, fmt.Sprintf("%s: пароль зашифрован, но парольная фраза не передана", conn.Name))
+			} else if password, err := utils.DecryptString(item.EncryptedPassword, key); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", conn.Name, err))
+			} else {
+				conn.Password = password
+			}
+		}
+
+		if item.EncryptedDSN != "" {
+			if key == nil {
+				warnings = append(warnings, fmt.Sprintf("%s: DSN зашифрован, но парольная фраза не передана", conn.Name))
+			} else if dsn, err := utils.DecryptString(item.EncryptedDSN, key); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: %v", conn.Name, err))
+			} else {
+				conn.DSN = dsn
+			}
+		}
+
+		imported = append(imported, conn)
+	}
+
+	if err := config.AddConnections(imported); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for i := range imported {
+		imported[i].HideSecrets()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"imported":    len(imported),
+		"connections": imported,
+		"warnings":    warnings,
+	})
+}
+
+// exportKey получает ключ расшифровки файла импорта: для версии 1 -
+// SHA-256 фразы, для версии 2 - scrypt с параметрами из encryption
+func exportKey(export models.ConnectionsExport, passphrase string) ([]byte, error) {
+	if export.Version == 1 {
+		return utils.LegacyKey(passphrase), nil
+	}
+
+	enc := export.Encryption
+	if enc == nil || enc.KDF != utils.KDFScrypt {
+		return nil, fmt.Errorf("в файле импорта не указаны параметры шифрования (encryption.kdf = %q)", utils.KDFScrypt)
+	}
+	salt, err := base64.StdEncoding.DecodeString(enc.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("неверная соль в файле импорта")
+	}
+	return utils.DeriveKey(passphrase, salt, enc.N, enc.R, enc.P)
+}
+
+// uniqueConnectionName добавляет к имени суффикс " (N)", если оно уже занято
+func uniqueConnectionName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)", name, i)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+func isReadOnlyConnection(connectionID string) bool {
+	conn, err := config.GetConnectionByID(connectionID)
+	if err != nil {
+		return false
+	}
+	return conn.ReadOnly
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportConnectionsRequiresPassphrase(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/connections/export", nil)
+	rec := httptest.NewRecorder()
+	ExportConnectionsHandler(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("export without passphrase: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestExportImportConnectionsRoundTrip(t *testing.T) {
+	config.ConnectionsFile = filepath.Join(t.TempDir(), "connections.json")
+	if err := config.SaveConnections([]models.Connection{{
+		ID:       "1",
+		Name:     "orders",
+		Type:     models.PostgreSQL,
+		Host:     "db",
+		Password: "s3cret",
+		DSN:      "postgres://app:s3cret@db:5432/orders",
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/connections/export", nil)
+	req.Header.Set(exportPassphraseHeader, "phrase")
+	rec := httptest.NewRecorder()
+	ExportConnectionsHandler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: status %d: %s", rec.Code, rec.Body.String())
+	}
+	body := rec.Body.String()
+	if strings.Contains(body, "s3cret") {
+		t.Fatal("export contains the plaintext password")
+	}
+	var export models.ConnectionsExport
+	if err := json.Unmarshal([]byte(body), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Version != connectionsExportVersion || export.Encryption == nil || export.Encryption.Salt == "" {
+		t.Fatalf("export envelope: version %d, encryption %+v", export.Version, export.Encryption)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/connections/import", strings.NewReader(body))
+	req.Header.Set(exportPassphraseHeader, "phrase")
+	rec = httptest.NewRecorder()
+	ImportConnectionsHandler(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import: status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	conns := config.GetConnections()
+	if len(conns) != 2 {
+		t.Fatalf("got %d connections after import, want 2", len(conns))
+	}
+	if imported := conns[1]; imported.Password != "s3cret" || imported.DSN != "postgres://app:s3cret@db:5432/orders" {
+		t.Errorf("imported connection: password %q, dsn %q", imported.Password, imported.DSN)
+	}
+}
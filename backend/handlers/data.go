@@ -0,0 +1,419 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultBrowseLimit = 100
+	maxBrowseLimit     = 1000
+)
+
+// DataHandler обслуживает /api/data: GET - просмотр строк,
+// POST/PUT/DELETE - запись через database.DataWriter
+func DataHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		BrowseDataHandler(w, r)
+	case http.MethodPost, http.MethodPut:
+		writeRows(w, r)
+	case http.MethodDelete:
+		deleteRow(w, r)
+	default:
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+	}
+}
+
+func BrowseDataHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("table")
+	if connectionID == "" || table == "" {
+		http.Error(w, "connectionId и table обязательны", http.StatusBadRequest)
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	browser, ok := driver.(database.DataBrowser)
+	if !ok {
+		http.Error(w, "Просмотр данных не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := browser.BrowseData(ctx, table, limit, offset)
+	if err == nil {
+		err = redactConnectionResult(connectionID, result)
+	}
+	if err != nil {
+		driverError(w, r, connectionID, "browse", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// FindRowsHandler ищет строки таблицы, содержащие текст q, в столбцах
+// columns (через запятую) или во всех текстовых столбцах
+func FindRowsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("name")
+	text := r.URL.Query().Get("q")
+	if connectionID == "" || table == "" || text == "" {
+		http.Error(w, "connectionId, name и q обязательны", http.StatusBadRequest)
+		return
+	}
+
+	var columns []string
+	if columnsStr := r.URL.Query().Get("columns"); columnsStr != "" {
+		for _, col := range strings.Split(columnsStr, ",") {
+			if col = strings.TrimSpace(col); col != "" {
+				columns = append(columns, col)
+			}
+		}
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	searcher, ok := driver.(database.TableSearcher)
+	if !ok {
+		http.Error(w, "Поиск не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := searcher.SearchTable(ctx, table, text, columns, limit, offset)
+	if err == nil {
+		err = redactConnectionResult(connectionID, result)
+	}
+	if err != nil {
+		driverError(w, r, connectionID, "find", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func writeRows(w http.ResponseWriter, r *http.Request) {
+	var req models.WriteRowsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || (req.Lines == "" && (req.Table == "" || len(req.Rows) == 0)) {
+		http.Error(w, "connectionId, table и rows обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	if writePoints(w, r, req) {
+		return
+	}
+	if req.Lines != "" {
+		http.Error(w, "lines (line protocol) поддерживается только для InfluxDB", http.StatusBadRequest)
+		return
+	}
+
+	writer, release, ok := getDataWriter(w, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	write := writer.InsertRows
+	if r.Method == http.MethodPut {
+		write = writer.UpdateRows
+	}
+
+	result, err := write(ctx, req.Table, req.Rows)
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "write", err)
+		return
+	}
+
+	writeResult(w, result)
+}
+
+// writePoints записывает точки, если драйвер - database.PointWriter
+// (InfluxDB): POST и PUT одинаковы, точка с теми же тегами и временем
+// заменяет прежнюю. false - драйвер точки не пишет, ответ не отправлен
+func writePoints(w http.ResponseWriter, r *http.Request, req models.WriteRowsRequest) bool {
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return true
+	}
+	defer release()
+
+	writer, ok := driver.(database.PointWriter)
+	if !ok {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := writer.WritePoints(ctx, req)
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "write-points", err)
+		return true
+	}
+
+	writeResult(w, result)
+	return true
+}
+
+func deleteRow(w http.ResponseWriter, r *http.Request) {
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("table")
+	id := r.URL.Query().Get("id")
+	if connectionID == "" || table == "" || id == "" {
+		http.Error(w, "connectionId, table и id обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, connectionID) {
+		return
+	}
+
+	writer, release, ok := getDataWriter(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := writer.DeleteRow(ctx, table, id)
+	if err != nil {
+		driverError(w, r, connectionID, "delete-row", err)
+		return
+	}
+
+	writeResult(w, result)
+}
+
+// DeleteRowsHandler удаляет строки, подходящие под фильтр, и возвращает
+// их число. Требует confirm с именем таблицы (для Redis - с шаблоном ключей,
+// при удалении всех ключей - "*")
+func DeleteRowsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.DeleteRowsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+	if req.Filter == "" && !req.All {
+		http.Error(w, "Укажите filter или all: true для удаления всех строк", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	target := req.Table
+	if target == "" {
+		target = req.Filter
+	}
+	if target == "" {
+		// all: true без таблицы и шаблона (Redis FLUSHDB) удаляет все ключи
+		// базы: пустой confirm, который передается по умолчанию, не подходит
+		target = "*"
+	}
+	if !checkDeleteConfirmation(w, r, target) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	deleter, ok := driver.(database.RowDeleter)
+	if !ok {
+		http.Error(w, "Удаление строк по фильтру не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	deleted, err := deleter.DeleteRows(ctx, req.Table, req.Filter, req.Args)
+	publishEvent(r, models.EventQuery, req.ConnectionID, "DELETE "+target+" WHERE "+req.Filter)
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "delete-rows", err)
+		return
+	}
+
+	writeResult(w, &models.WriteResult{RowsAffected: deleted})
+}
+
+// ImportDataHandler вставляет большой набор строк {connectionId, table, rows}.
+// Драйверы с database.BatchInserter (ClickHouse) отправляют строки одним
+// пакетом, остальные - через обычный InsertRows. Возвращает число
+// вставленных строк
+func ImportDataHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.WriteRowsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Table == "" || len(req.Rows) == 0 {
+		http.Error(w, "connectionId, table и rows обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	var insert func(context.Context, string, []map[string]interface{}) (*models.WriteResult, error)
+	if inserter, ok := driver.(database.BatchInserter); ok {
+		insert = inserter.InsertBatch
+	} else if writer, ok := driver.(database.DataWriter); ok {
+		insert = writer.InsertRows
+	} else {
+		http.Error(w, "Импорт данных не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	result, err := insert(ctx, req.Table, req.Rows)
+	publishEvent(r, models.EventQuery, req.ConnectionID, fmt.Sprintf("IMPORT %s (%d строк)", req.Table, len(req.Rows)))
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "import", err)
+		return
+	}
+
+	writeResult(w, result)
+}
+
+// writeResult отвечает 202 Accepted, если запись поставлена в очередь
+// асинхронной задачей, и 200 OK, если она уже применена
+func writeResult(w http.ResponseWriter, result *models.WriteResult) {
+	w.Header().Set("Content-Type", "application/json")
+	if result.Task != nil {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+func getDataWriter(w http.ResponseWriter, connectionID string) (database.DataWriter, func(), bool) {
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	writer, ok := driver.(database.DataWriter)
+	if !ok {
+		http.Error(w, "Запись данных не поддерживается для этого типа БД", http.StatusBadRequest)
+		release()
+		return nil, nil, false
+	}
+
+	return writer, release, true
+}
+
+func parsePagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	limit := defaultBrowseLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "limit должен быть положительным числом", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+	if limit > maxBrowseLimit {
+		limit = maxBrowseLimit
+	}
+
+	offset := 0
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			http.Error(w, "offset должен быть неотрицательным числом", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+
+	return limit, offset, true
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeleteRowsRequiresConfirmForWholeKeyspace(t *testing.T) {
+	config.ConnectionsFile = filepath.Join(t.TempDir(), "connections.json")
+	if _, err := config.LoadConnections(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, url := range []string{"/api/data/bulk", "/api/data/bulk?confirm="} {
+		req := httptest.NewRequest(http.MethodDelete, url, strings.NewReader(`{"connectionId": "redis", "all": true}`))
+		rec := httptest.NewRecorder()
+		DeleteRowsHandler(rec, req)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "confirm") {
+			t.Errorf("%s: status %d, body %q; want 400 asking for confirm", url, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"context"
+	"database-manager/config"
+	"database-manager/database"
 	"database-manager/models"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 )
@@ -15,30 +18,49 @@ func CreateDatabaseHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.CreateDatabaseRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	// DDL меняет схему: на подключениях только для чтения и с requireApproval
+	// выполняется только администратором; предпросмотр разрешен всем
+	if !isDryRun(r) && !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanCreateDatabase(req.Name, req.Options)
+		writeDryRun(w, statements, err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	if err := driver.CreateDatabase(ctx, req.Name, req.Options); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, req.ConnectionID, "create-database", err)
 		return
 	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "CREATE DATABASE "+req.Name)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withExecutedSQL(map[string]interface{}{
 		"success": true,
 		"name":    req.Name,
-	})
+	}, driver, func(planner database.DDLPlanner) ([]string, error) {
+		return planner.PlanCreateDatabase(req.Name, req.Options)
+	}))
 }
 
 func ListDatabasesHandler(w http.ResponseWriter, r *http.Request) {
@@ -53,18 +75,19 @@ func ListDatabasesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	databases, err := driver.ListDatabases(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, connectionID, "list-databases", err)
 		return
 	}
 
@@ -79,24 +102,39 @@ func UpdateDatabaseHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateDatabaseRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if !isDryRun(r) && !checkWritable(w, r, req.ConnectionID) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanUpdateDatabase(req.OldName, req.NewName, req.Options)
+		writeDryRun(w, statements, err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	if err := driver.UpdateDatabase(ctx, req.OldName, req.NewName, req.Options); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, req.ConnectionID, "update-database", err)
 		return
 	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "ALTER DATABASE "+req.OldName)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -119,19 +157,40 @@ func DeleteDatabaseHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	// Для предпросмотра подтверждение не нужно: ничего не удаляется
+	if !isDryRun(r) && !checkDeleteConfirmation(w, r, name) {
+		return
+	}
+
+	if !isDryRun(r) && !checkWritable(w, r, connectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanDeleteDatabase(name)
+		writeDryRun(w, statements, err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	if err := driver.DeleteDatabase(ctx, name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, connectionID, "delete-database", err)
 		return
 	}
+	publishEvent(r, models.EventDDL, connectionID, "DROP DATABASE "+name)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -139,3 +198,17 @@ func DeleteDatabaseHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// checkDeleteConfirmation требует параметр confirm, совпадающий с именем
+// удаляемого объекта, чтобы случайно не удалить не ту базу или таблицу.
+// Проверку можно отключить через skipDeleteConfirmation в app.json
+func checkDeleteConfirmation(w http.ResponseWriter, r *http.Request, name string) bool {
+	if config.GetAppConfig().SkipDeleteConfirmation {
+		return true
+	}
+	if r.URL.Query().Get("confirm") != name {
+		http.Error(w, fmt.Sprintf("Для удаления укажите confirm с именем объекта %q", name), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
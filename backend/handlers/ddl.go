@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"database-manager/database"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// isDryRun - параметр dryRun=true: операция над базой или таблицей не
+// выполняется, в ответе - команды, которые были бы выполнены
+func isDryRun(r *http.Request) bool {
+	return r.URL.Query().Get("dryRun") == "true"
+}
+
+// ddlPlanner возвращает драйвер как DDLPlanner; если драйвер не умеет
+// строить команды заранее, отвечает 400
+func ddlPlanner(w http.ResponseWriter, driver database.DatabaseDriver) (database.DDLPlanner, bool) {
+	planner, ok := driver.(database.DDLPlanner)
+	if !ok {
+		http.Error(w, "Предпросмотр команд (dryRun) поддерживается только для SQL-баз данных", http.StatusBadRequest)
+		return nil, false
+	}
+	return planner, true
+}
+
+// writeDryRun отвечает командами, построенными драйвером; ошибка
+// построения - это ошибка во входных данных, как при выполнении
+func writeDryRun(w http.ResponseWriter, statements []string, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dryRun":     true,
+		"statements": statements,
+	})
+}
+
+// withExecutedSQL добавляет в ответ успешной операции поле "sql" с
+// выполненными командами через ";\n". Команды строятся теми же функциями,
+// что и при выполнении; у драйверов без DDLPlanner поля нет
+func withExecutedSQL(response map[string]interface{}, driver database.DatabaseDriver, plan func(database.DDLPlanner) ([]string, error)) map[string]interface{} {
+	planner, ok := driver.(database.DDLPlanner)
+	if !ok {
+		return response
+	}
+	if statements, err := plan(planner); err == nil && len(statements) > 0 {
+		response["sql"] = strings.Join(statements, ";\n")
+	}
+	return response
+}
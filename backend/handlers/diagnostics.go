@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/events"
+	"database-manager/models"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRecentErrors - сколько последних ошибок драйверов хранится в памяти
+	maxRecentErrors = 200
+	// maxSlowQueries - сколько последних медленных запросов хранится в памяти
+	maxSlowQueries = 200
+	// maxSlowQueryLength - предел длины текста запроса в журнале
+	maxSlowQueryLength = 2000
+)
+
+var (
+	recentErrors = events.NewRing[models.ErrorRecord](maxRecentErrors)
+	slowQueries  = events.NewRing[models.SlowQuery](maxSlowQueries)
+)
+
+// recordError запоминает ошибку драйвера в журнале последних ошибок.
+// operation - короткое имя действия: query, write, describe-table...
+func recordError(r *http.Request, connectionID, operation, message string) {
+	if len(message) > maxEventDetailsLength {
+		message = message[:maxEventDetailsLength] + "..."
+	}
+
+	recentErrors.Add(models.ErrorRecord{
+		Timestamp:    time.Now(),
+		Username:     r.Header.Get("Username"),
+		ConnectionID: connectionID,
+		Operation:    operation,
+		Message:      message,
+	})
+}
+
+// driverError запоминает ошибку драйвера и отвечает 500
+func driverError(w http.ResponseWriter, r *http.Request, connectionID, operation string, err error) {
+	recordError(r, connectionID, operation, err.Error())
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// recordSlowQuery пишет в лог и журнал запрос, выполнявшийся дольше
+// slowQueryThreshold. Ответ из кэша не учитывается. Если у подключения есть
+// правила скрытия столбцов, литералы запроса заменяются на ?
+func recordSlowQuery(r *http.Request, connectionID, query string, result *models.QueryResponse, redactor *database.ColumnRedactor) {
+	threshold, err := config.GetAppConfig().SlowQueryDuration()
+	if err != nil || threshold == 0 || result == nil || result.Cached {
+		return
+	}
+	if result.ExecutionTime <= threshold.Milliseconds() {
+		return
+	}
+
+	if redactor != nil {
+		query = database.MaskQueryLiterals(query)
+	}
+	if len(query) > maxSlowQueryLength {
+		query = query[:maxSlowQueryLength] + "..."
+	}
+
+	log.Printf("WARN медленный запрос: %d мс, подключение %s: %s", result.ExecutionTime, connectionID, query)
+	slowQueries.Add(models.SlowQuery{
+		Timestamp:    time.Now(),
+		Username:     r.Header.Get("Username"),
+		ConnectionID: connectionID,
+		Query:        query,
+		Duration:     result.ExecutionTime,
+	})
+}
+
+// RecentErrorsHandler возвращает последние ошибки драйверов, от новых к
+// старым. Необязательные параметры: connectionId - только ошибки
+// подключения, limit - не больше limit записей
+func RecentErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := parseDiagnosticsLimit(w, r, maxRecentErrors)
+	if !ok {
+		return
+	}
+	connectionID := r.URL.Query().Get("connectionId")
+
+	records := []models.ErrorRecord{}
+	for _, record := range recentErrors.Items() {
+		if len(records) == limit {
+			break
+		}
+		if connectionID != "" && record.ConnectionID != connectionID {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// SlowQueriesHandler возвращает последние медленные запросы, от новых к
+// старым. Параметры те же, что у RecentErrorsHandler
+func SlowQueriesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, ok := parseDiagnosticsLimit(w, r, maxSlowQueries)
+	if !ok {
+		return
+	}
+	connectionID := r.URL.Query().Get("connectionId")
+
+	records := []models.SlowQuery{}
+	for _, record := range slowQueries.Items() {
+		if len(records) == limit {
+			break
+		}
+		if connectionID != "" && record.ConnectionID != connectionID {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// parseDiagnosticsLimit читает параметр limit; false - ответ уже отправлен
+func parseDiagnosticsLimit(w http.ResponseWriter, r *http.Request, max int) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return max, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		http.Error(w, "limit должен быть положительным числом", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
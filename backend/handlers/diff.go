@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// QueryDiffHandler выполняет два запроса (или один запрос на двух
+// подключениях) и возвращает построчные различия результатов по ключевому
+// столбцу. Удобно для сравнения данных между окружениями
+func QueryDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.QueryDiffRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.Right.ConnectionID == "" {
+		req.Right.ConnectionID = req.Left.ConnectionID
+	}
+	if req.Right.Query == "" {
+		req.Right.Query = req.Left.Query
+		req.Right.Params = req.Left.Params
+	}
+	if req.Left.ConnectionID == "" || req.Left.Query == "" || req.Key == "" {
+		http.Error(w, "left.connectionId, left.query и key обязательны", http.StatusBadRequest)
+		return
+	}
+
+	sides := []struct {
+		name string
+		side models.QueryDiffSide
+	}{
+		{"левый", req.Left},
+		{"правый", req.Right},
+	}
+	// Сравниваются только результаты чтения: запрос на изменение выполнился
+	// бы на обеих сторонах, а его результат сравнивать бессмысленно
+	for _, s := range sides {
+		if !database.IsReadStatement(s.side.Query) {
+			http.Error(w, fmt.Sprintf("%s запрос: сравнивать можно только запросы на чтение (SELECT, SHOW)", s.name), http.StatusBadRequest)
+			return
+		}
+	}
+	results := make([]*models.QueryResponse, len(sides))
+	for i, s := range sides {
+		driver, release, err := connManager.AcquireDriver(s.side.ConnectionID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s запрос: %v", s.name, err), http.StatusNotFound)
+			return
+		}
+		defer release()
+
+		target, err := newQueryTarget(s.side.ConnectionID, driver)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if rejected := rejectUnapprovedWrite(approvalRequired(r, s.side.ConnectionID), s.side.Query); rejected != nil {
+			http.Error(w, fmt.Sprintf("%s запрос: %s", s.name, rejected.Error), http.StatusForbidden)
+			return
+		}
+		extendWriteDeadline(w, target.timeout)
+		results[i] = runTargetQuery(r.Context(), target, s.side.Query, s.side.Params)
+		recordSlowQuery(r, s.side.ConnectionID, s.side.Query, results[i], target.redactor)
+		publishEvent(r, models.EventQuery, s.side.ConnectionID, s.side.Query)
+		config.RecordConnectionQuery(s.side.ConnectionID)
+		if results[i].Error != "" {
+			recordError(r, s.side.ConnectionID, "query-diff", results[i].Error)
+			http.Error(w, fmt.Sprintf("%s запрос: %s", s.name, results[i].Error), http.StatusBadRequest)
+			return
+		}
+	}
+
+	diff, err := database.DiffResults(results[0], results[1], req.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// schemaDiffTypes - типы БД, схемы которых сравнивает SchemaDiffHandler
+var schemaDiffTypes = map[models.DatabaseType]bool{
+	models.PostgreSQL:  true,
+	models.CockroachDB: true,
+}
+
+// SchemaDiffHandler сравнивает схемы двух подключений: таблицы и столбцы,
+// которые есть только в одной базе, и столбцы с разными типами. Столбцы
+// берутся из DescribeTable каждой таблицы из ListTables
+func SchemaDiffHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	sourceID := r.URL.Query().Get("sourceId")
+	targetID := r.URL.Query().Get("targetId")
+	if sourceID == "" || targetID == "" {
+		http.Error(w, "sourceId и targetId обязательны", http.StatusBadRequest)
+		return
+	}
+
+	schemas := make([]map[string][]models.TableColumn, 2)
+	for i, connectionID := range []string{sourceID, targetID} {
+		conn, err := config.GetConnectionByID(connectionID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if !schemaDiffTypes[conn.Type] {
+			http.Error(w, fmt.Sprintf("Сравнение схем поддерживается только для PostgreSQL и CockroachDB, подключение %s - %s", conn.Name, conn.Type), http.StatusBadRequest)
+			return
+		}
+
+		driver, release, err := connManager.AcquireDriver(connectionID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		defer release()
+
+		schemas[i], err = describeSchema(r.Context(), driver)
+		if err != nil {
+			recordError(r, connectionID, "schema-diff", err.Error())
+			http.Error(w, fmt.Sprintf("%s: %v", conn.Name, err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	diff := database.DiffSchemas(schemas[0], schemas[1])
+	diff.SourceID = sourceID
+	diff.TargetID = targetID
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// describeSchema возвращает столбцы всех таблиц подключения
+func describeSchema(ctx context.Context, driver database.DatabaseDriver) (map[string][]models.TableColumn, error) {
+	describer, ok := driver.(database.TableDescriber)
+	if !ok {
+		return nil, fmt.Errorf("описание таблиц не поддерживается для этого типа БД")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tables, err := driver.ListTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema := make(map[string][]models.TableColumn, len(tables))
+	for _, table := range tables {
+		columns, err := describer.DescribeTable(ctx, table.Name)
+		if err != nil {
+			return nil, err
+		}
+		schema[table.Name] = columns
+	}
+	return schema, nil
+}
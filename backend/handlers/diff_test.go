@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestQueryDiffRejectsWrites(t *testing.T) {
+	tests := []string{
+		`{"left": {"connectionId": "1", "query": "DELETE FROM users"}, "key": "id"}`,
+		`{"left": {"connectionId": "1", "query": "SELECT * FROM users"}, "right": {"query": "UPDATE users SET name = 'x'"}, "key": "id"}`,
+		`{"left": {"connectionId": "1", "query": "WITH d AS (DELETE FROM users RETURNING *) SELECT * FROM d"}, "key": "id"}`,
+	}
+
+	for _, body := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/query/diff", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		QueryDiffHandler(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("QueryDiffHandler(%s): status %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
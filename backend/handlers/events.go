@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"database-manager/events"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	eventsKeepAliveInterval = 30 * time.Second
+	maxEventDetailsLength   = 500
+)
+
+var eventBus = events.NewBus()
+
+// publishEvent отправляет событие активности от имени текущего пользователя.
+// Текст запроса в поток не попадает: в нем бывают пароли и данные, скрытые
+// правилами подключения
+func publishEvent(r *http.Request, eventType models.EventType, connectionID, details string) {
+	if eventType == models.EventQuery {
+		details = ""
+	}
+	if runes := []rune(details); len(runes) > maxEventDetailsLength {
+		details = string(runes[:maxEventDetailsLength]) + "..."
+	}
+
+	eventBus.Publish(models.Event{
+		Type:         eventType,
+		Timestamp:    time.Now(),
+		Username:     r.Header.Get("Username"),
+		ConnectionID: connectionID,
+		Details:      details,
+	})
+}
+
+// EventsHandler отдает поток событий активности (Server-Sent Events) всех
+// подключений, поэтому доступен только администраторам. EventSource не
+// умеет передавать заголовки, поэтому токен передается параметром token.
+func EventsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Поток живет дольше WriteTimeout сервера
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ch, unsubscribe := eventBus.Subscribe()
+	defer unsubscribe()
+
+	ticker := time.NewTicker(eventsKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event := <-ch:
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
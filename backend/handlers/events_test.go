@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"database-manager/models"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPublishEventDetails(t *testing.T) {
+	long := strings.Repeat("я", maxEventDetailsLength+1)
+	tests := []struct {
+		eventType models.EventType
+		details   string
+		want      string
+	}{
+		{models.EventQuery, "ALTER USER bob WITH PASSWORD 'secret'", ""},
+		{models.EventDDL, "CREATE TABLE orders", "CREATE TABLE orders"},
+		{models.EventDDL, long, strings.Repeat("я", maxEventDetailsLength) + "..."},
+	}
+	ch, unsubscribe := eventBus.Subscribe()
+	defer unsubscribe()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, tt := range tests {
+		publishEvent(r, tt.eventType, "1", tt.details)
+		if event := <-ch; event.Details != tt.want {
+			t.Errorf("publishEvent(%s, %.20q...): details = %.20q..., want %.20q...", tt.eventType, tt.details, event.Details, tt.want)
+		}
+	}
+}
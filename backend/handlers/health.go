@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/utils"
+	"encoding/json"
+	"net/http"
+)
+
+// HealthzHandler - проверка живости процесса, всегда отвечает 200
+func HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// ReadyzHandler - проверка готовности: файлы конфигурации читаются,
+// в ответе число активных подключений. Подключения не пингуются,
+// чтобы проверка оставалась быстрой.
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := config.CheckFiles(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "unavailable",
+			"error":  err.Error(),
+		})
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":            "ok",
+		"activeConnections": connManager.ActiveCount(),
+	})
+}
+
+// VersionHandler возвращает версию, commit и время сборки
+func VersionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(utils.GetBuildInfo())
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+func IndexSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		getIndexSettings(w, r)
+	case http.MethodPatch:
+		updateIndexSettings(w, r)
+	default:
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+	}
+}
+
+func getIndexSettings(w http.ResponseWriter, r *http.Request) {
+	connectionID := r.URL.Query().Get("connectionId")
+	index := r.URL.Query().Get("index")
+	if connectionID == "" || index == "" {
+		http.Error(w, "connectionId и index обязательны", http.StatusBadRequest)
+		return
+	}
+
+	manager, release, ok := getIndexSettingsManager(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	settings, err := manager.GetIndexSettings(ctx, index)
+	if err != nil {
+		driverError(w, r, connectionID, "index-settings", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+func updateIndexSettings(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateIndexSettingsRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Index == "" || len(req.Settings) == 0 {
+		http.Error(w, "connectionId, index и settings обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	manager, release, ok := getIndexSettingsManager(w, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	task, err := manager.UpdateIndexSettings(ctx, req.Index, req.Settings)
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "update-index-settings", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(task)
+}
+
+func getIndexSettingsManager(w http.ResponseWriter, connectionID string) (database.IndexSettingsManager, func(), bool) {
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	manager, ok := driver.(database.IndexSettingsManager)
+	if !ok {
+		http.Error(w, "Настройки индекса не поддерживаются для этого типа БД", http.StatusBadRequest)
+		release()
+		return nil, nil, false
+	}
+
+	return manager, release, true
+}
+
+// IndexesHandler управляет индексами таблиц: GET - список,
+// POST - создание, DELETE - удаление (?connectionId=&table=&name=)
+func IndexesHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listIndexes(w, r)
+	case http.MethodPost:
+		createIndex(w, r)
+	case http.MethodDelete:
+		dropIndex(w, r)
+	default:
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+	}
+}
+
+func listIndexes(w http.ResponseWriter, r *http.Request) {
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("table")
+	if connectionID == "" || table == "" {
+		http.Error(w, "connectionId и table обязательны", http.StatusBadRequest)
+		return
+	}
+
+	manager, release, ok := getIndexManager(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	indexes, err := manager.ListIndexes(ctx, table)
+	if err != nil {
+		driverError(w, r, connectionID, "list-indexes", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(indexes)
+}
+
+func createIndex(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateIndexRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Table == "" || req.Name == "" || len(req.Columns) == 0 {
+		http.Error(w, "connectionId, table, name и columns обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	manager, release, ok := getIndexManager(w, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	if err := manager.CreateIndex(ctx, req); err != nil {
+		driverError(w, r, req.ConnectionID, "create-index", err)
+		return
+	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "CREATE INDEX "+req.Name+" ON "+req.Table)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+func dropIndex(w http.ResponseWriter, r *http.Request) {
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("table")
+	name := r.URL.Query().Get("name")
+	if connectionID == "" || table == "" || name == "" {
+		http.Error(w, "connectionId, table и name обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, connectionID) {
+		return
+	}
+
+	manager, release, ok := getIndexManager(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := manager.DropIndex(ctx, table, name); err != nil {
+		driverError(w, r, connectionID, "drop-index", err)
+		return
+	}
+	publishEvent(r, models.EventDDL, connectionID, "DROP INDEX "+name)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+func getIndexManager(w http.ResponseWriter, connectionID string) (database.IndexManager, func(), bool) {
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	manager, ok := driver.(database.IndexManager)
+	if !ok {
+		http.Error(w, "Управление индексами не поддерживается для этого типа БД", http.StatusBadRequest)
+		release()
+		return nil, nil, false
+	}
+
+	return manager, release, true
+}
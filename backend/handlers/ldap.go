@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"database-manager/config"
+	"database-manager/models"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+)
+
+// errInvalidCredentials - неверное имя или пароль; остальные ошибки LDAP
+// означают недоступность сервера или ошибку конфигурации
+var errInvalidCredentials = errors.New("неверное имя пользователя или пароль")
+
+// errLDAPUserConflict - пользователь с таким именем уже есть и создан не
+// через LDAP
+var errLDAPUserConflict = errors.New("пользователь с таким именем уже существует и не связан с LDAP")
+
+const ldapTimeout = 10 * time.Second
+
+// authenticateLDAP проверяет пароль пользователя в LDAP и возвращает его
+// email (может быть пустым)
+func authenticateLDAP(cfg *config.LDAPConfig, username, password string) (string, error) {
+	// Bind с пустым паролем - анонимный и в LDAP всегда успешен
+	if username == "" || password == "" {
+		return "", errInvalidCredentials
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if u, err := url.Parse(cfg.URL); err == nil {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	conn, err := ldap.DialURL(cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: ldapTimeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return "", fmt.Errorf("ошибка подключения к LDAP: %w", err)
+	}
+	defer conn.Close()
+	conn.SetTimeout(ldapTimeout)
+
+	if cfg.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			return "", fmt.Errorf("ошибка StartTLS: %w", err)
+		}
+	}
+
+	if cfg.BindDN != "" {
+		if err := conn.Bind(cfg.BindDN, cfg.BindPassword); err != nil {
+			return "", fmt.Errorf("ошибка входа учетной записи поиска LDAP: %w", err)
+		}
+	}
+
+	filter := cfg.UserFilter
+	if filter == "" {
+		filter = "(uid=%s)"
+	}
+	search := ldap.NewSearchRequest(
+		cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(ldapTimeout/time.Second), false,
+		strings.ReplaceAll(filter, "%s", ldap.EscapeFilter(username)),
+		[]string{"mail"}, nil,
+	)
+	result, err := conn.Search(search)
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return "", fmt.Errorf("ошибка поиска пользователя в LDAP: %w", err)
+	}
+	// Несколько записей - фильтр неоднозначен, входить под любой из них нельзя
+	if result == nil || len(result.Entries) != 1 {
+		return "", errInvalidCredentials
+	}
+
+	entry := result.Entries[0]
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return "", errInvalidCredentials
+		}
+		return "", fmt.Errorf("ошибка проверки пароля в LDAP: %w", err)
+	}
+
+	return entry.GetAttributeValue("mail"), nil
+}
+
+// ldapUser возвращает локальную запись пользователя, вошедшего через LDAP,
+// и создает ее при первом входе. Локального пользователя с тем же именем
+// LDAP не получает: иначе учетная запись admin в каталоге входила бы под
+// локальным администратором
+func ldapUser(cfg *config.LDAPConfig, username, email string) (*models.User, error) {
+	if user, err := config.GetUserByUsername(username); err == nil {
+		if user.AuthSource != models.AuthSourceLDAP {
+			return nil, errLDAPUserConflict
+		}
+		return user, nil
+	}
+
+	user := models.User{
+		ID:         uuid.New().String(),
+		Username:   username,
+		Email:      email,
+		Role:       cfg.DefaultRole,
+		AuthSource: models.AuthSourceLDAP,
+		CreatedAt:  time.Now(),
+	}
+	if err := config.AddUser(user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestLDAPUser(t *testing.T) {
+	config.UsersFile = filepath.Join(t.TempDir(), "users.json")
+	if err := config.SaveUsers([]models.User{
+		{ID: "1", Username: "admin", Role: models.RoleAdmin},
+		{ID: "2", Username: "alice", Role: "user", AuthSource: models.AuthSourceLDAP},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.LDAPConfig{DefaultRole: "user"}
+
+	if _, err := ldapUser(cfg, "admin", ""); !errors.Is(err, errLDAPUserConflict) {
+		t.Errorf("ldapUser(admin): error = %v, want errLDAPUserConflict", err)
+	}
+
+	user, err := ldapUser(cfg, "alice", "")
+	if err != nil || user.ID != "2" {
+		t.Errorf("ldapUser(alice) = %+v, %v; want existing LDAP user", user, err)
+	}
+
+	user, err = ldapUser(cfg, "bob", "bob@example.com")
+	if err != nil || user.AuthSource != models.AuthSourceLDAP || user.Role != "user" {
+		t.Errorf("ldapUser(bob) = %+v, %v; want new LDAP user", user, err)
+	}
+	if _, err := config.GetUserByUsername("bob"); err != nil {
+		t.Errorf("new LDAP user not saved: %v", err)
+	}
+}
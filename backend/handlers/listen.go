@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/models"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// ListenHandler открывает WebSocket и пересылает клиенту уведомления
+// pg_notify из канала channel. Подписка снимается при закрытии WebSocket.
+func ListenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	channel := r.URL.Query().Get("channel")
+	if connectionID == "" || channel == "" {
+		http.Error(w, "connectionId и channel обязательны", http.StatusBadRequest)
+		return
+	}
+
+	conn, err := config.GetConnectionByID(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if conn.Type != models.PostgreSQL && conn.Type != models.CockroachDB {
+		http.Error(w, "LISTEN поддерживается только для PostgreSQL и CockroachDB", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	listener, ok := driver.(database.NotificationListener)
+	if !ok {
+		http.Error(w, "LISTEN не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	server := websocket.Server{Handshake: checkWebSocketOrigin, Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// Снимаем таймауты http.Server: соединение живет дольше WriteTimeout
+		ws.SetDeadline(time.Time{})
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		// Клиент ничего не отправляет, чтение нужно только чтобы заметить закрытие
+		go func() {
+			var msg string
+			for {
+				if err := websocket.Message.Receive(ws, &msg); err != nil {
+					cancel()
+					return
+				}
+			}
+		}()
+
+		err := listener.Listen(ctx, channel, func(n models.Notification) error {
+			return websocket.JSON.Send(ws, n)
+		})
+		if err != nil && ctx.Err() == nil {
+			websocket.JSON.Send(ws, models.ErrorResponse{Error: err.Error()})
+		}
+	}}
+	server.ServeHTTP(w, r)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+// ListenHandler и TerminalHandler принимают WebSocket только после
+// checkWebSocketOrigin: CORS на WebSocket не действует
+func TestCheckWebSocketOrigin(t *testing.T) {
+	config.AppConfigFile = filepath.Join(t.TempDir(), "config.json")
+	previous := *config.GetAppConfig()
+	t.Cleanup(func() { config.SaveAppConfig(&previous) })
+	if err := config.SaveAppConfig(&config.AppConfig{AllowedOrigins: []string{"https://db.example.com"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://db.example.com", true},
+		{"", true},
+		{"https://evil.example.com", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/api/pg/listen?connectionId=1&channel=events", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if err := checkWebSocketOrigin(nil, r); (err == nil) != tt.allowed {
+			t.Errorf("checkWebSocketOrigin(%q) = %v, allowed %v", tt.origin, err, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"database-manager/config"
+	"database-manager/models"
+	"database-manager/utils"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// oidcStateCookie хранит state, nonce и PKCE verifier между переходом к
+// провайдеру и возвратом на callback
+const oidcStateCookie = "oidc_state"
+
+var (
+	oidcMu       sync.Mutex
+	oidcProvider *oidc.Provider
+	oidcIssuer   string
+)
+
+// getOIDCProvider загружает discovery-документ провайдера один раз на issuer
+func getOIDCProvider(ctx context.Context, issuer string) (*oidc.Provider, error) {
+	oidcMu.Lock()
+	defer oidcMu.Unlock()
+
+	if oidcProvider != nil && oidcIssuer == issuer {
+		return oidcProvider, nil
+	}
+
+	provider, err := oidc.NewProvider(ctx, issuer)
+	if err != nil {
+		return nil, err
+	}
+	oidcProvider, oidcIssuer = provider, issuer
+	return provider, nil
+}
+
+func oidcConfig() (*config.OIDCConfig, bool) {
+	cfg := config.GetAppConfig().OIDC
+	return cfg, cfg != nil && cfg.Enabled
+}
+
+func oauth2Config(cfg *config.OIDCConfig, provider *oidc.Provider) *oauth2.Config {
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	return &oauth2.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       append([]string{oidc.ScopeOpenID}, scopes...),
+	}
+}
+
+// AuthProvidersHandler сообщает клиенту, какие способы входа включены
+func AuthProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	appConfig := config.GetAppConfig()
+	_, oidcEnabled := oidcConfig()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{
+		"ldap": appConfig.LDAP != nil && appConfig.LDAP.Enabled,
+		"oidc": oidcEnabled,
+	})
+}
+
+// OIDCLoginHandler перенаправляет браузер на страницу входа провайдера
+func OIDCLoginHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, ok := oidcConfig()
+	if !ok {
+		http.Error(w, "Вход через OIDC не настроен", http.StatusNotFound)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	provider, err := getOIDCProvider(ctx, cfg.Issuer)
+	if err != nil {
+		log.Printf("Ошибка загрузки конфигурации OIDC-провайдера: %v", err)
+		http.Error(w, "OIDC-провайдер недоступен", http.StatusBadGateway)
+		return
+	}
+
+	state, nonce := randomToken(), randomToken()
+	verifier := oauth2.GenerateVerifier()
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    strings.Join([]string{state, nonce, verifier}, "."),
+		Path:     "/api/auth/oidc",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.URL.Scheme == "https" || r.TLS != nil,
+		// Lax: cookie нужна при возврате с сайта провайдера
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	authURL := oauth2Config(cfg, provider).AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler обменивает код на токены, проверяет ID-токен и
+// перенаправляет на postLoginUrl с нашим JWT во фрагменте #token=
+func OIDCCallbackHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, ok := oidcConfig()
+	if !ok {
+		http.Error(w, "Вход через OIDC не настроен", http.StatusNotFound)
+		return
+	}
+
+	user, err := completeOIDCLogin(r, cfg)
+	// Cookie одноразовая: повторить callback с тем же state нельзя
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/auth/oidc", MaxAge: -1})
+	if err != nil {
+		redirectAfterOIDC(w, r, cfg, "ssoError", err.Error())
+		return
+	}
+
+	token, err := utils.GenerateToken(*user)
+	if err != nil {
+		redirectAfterOIDC(w, r, cfg, "ssoError", "Ошибка генерации токена")
+		return
+	}
+	redirectAfterOIDC(w, r, cfg, "token", token)
+}
+
+func completeOIDCLogin(r *http.Request, cfg *config.OIDCConfig) (*models.User, error) {
+	if errCode := r.URL.Query().Get("error"); errCode != "" {
+		return nil, fmt.Errorf("провайдер отклонил вход: %s", errCode)
+	}
+
+	cookie, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, fmt.Errorf("сессия входа истекла, начните вход заново")
+	}
+	parts := strings.Split(cookie.Value, ".")
+	if len(parts) != 3 || parts[0] != r.URL.Query().Get("state") {
+		return nil, fmt.Errorf("неверный state, начните вход заново")
+	}
+	nonce, verifier := parts[1], parts[2]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	provider, err := getOIDCProvider(ctx, cfg.Issuer)
+	if err != nil {
+		log.Printf("Ошибка загрузки конфигурации OIDC-провайдера: %v", err)
+		return nil, fmt.Errorf("OIDC-провайдер недоступен")
+	}
+
+	oauthToken, err := oauth2Config(cfg, provider).Exchange(ctx, r.URL.Query().Get("code"), oauth2.VerifierOption(verifier))
+	if err != nil {
+		log.Printf("Ошибка обмена кода OIDC: %v", err)
+		return nil, fmt.Errorf("не удалось получить токен провайдера")
+	}
+
+	rawIDToken, ok := oauthToken.Extra("id_token").(string)
+	if !ok {
+		return nil, fmt.Errorf("провайдер не вернул id_token")
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("неверный id_token: %v", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("неверный nonce")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("ошибка чтения claims: %v", err)
+	}
+
+	username, err := oidcUsername(cfg, claims)
+	if err != nil {
+		return nil, err
+	}
+	email, _ := claims["email"].(string)
+	return oidcUser(cfg, idToken.Issuer, idToken.Subject, username, email)
+}
+
+// oidcUsername берет имя из usernameClaim (по умолчанию preferred_username),
+// затем из email и sub. Неподтвержденный email именем быть не может
+func oidcUsername(cfg *config.OIDCConfig, claims map[string]interface{}) (string, error) {
+	names := []string{"preferred_username", "email", "sub"}
+	if cfg.UsernameClaim != "" {
+		names = []string{cfg.UsernameClaim}
+	}
+
+	for _, name := range names {
+		value, _ := claims[name].(string)
+		if value == "" {
+			continue
+		}
+		if name == "email" {
+			if verified, ok := claims["email_verified"].(bool); ok && !verified {
+				return "", fmt.Errorf("email %s не подтвержден у провайдера", value)
+			}
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("в id_token нет имени пользователя (%s)", strings.Join(names, ", "))
+}
+
+// oidcUser возвращает пользователя, связанного с учетной записью
+// провайдера (issuer и sub), или создает нового. Имя из id_token только
+// отображается: preferred_username у провайдера можно сменить, поэтому по
+// нему пользователи не связываются. Занятое имя другой учетной записи не
+// выдается: иначе провайдер мог бы выдать вход под root
+func oidcUser(cfg *config.OIDCConfig, issuer, subject, username, email string) (*models.User, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("в id_token нет sub")
+	}
+	if user, err := config.GetUserByOIDCSubject(issuer, subject); err == nil {
+		return user, nil
+	}
+
+	if user, err := config.GetUserByUsername(username); err == nil {
+		// Пользователи, созданные до связывания по sub, связываются при
+		// первом входе после обновления
+		if user.AuthSource != models.AuthSourceOIDC || user.OIDCSubject != "" {
+			return nil, fmt.Errorf("пользователь %s уже существует и не связан с этой учетной записью OIDC", username)
+		}
+		user.OIDCIssuer, user.OIDCSubject = issuer, subject
+		if err := config.UpdateUser(*user); err != nil {
+			return nil, fmt.Errorf("ошибка сохранения пользователя")
+		}
+		return user, nil
+	}
+
+	user := models.User{
+		ID:          uuid.New().String(),
+		Username:    username,
+		Email:       email,
+		Role:        cfg.DefaultRole,
+		AuthSource:  models.AuthSourceOIDC,
+		OIDCIssuer:  issuer,
+		OIDCSubject: subject,
+		CreatedAt:   time.Now(),
+	}
+	if err := config.AddUser(user); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения пользователя")
+	}
+	return &user, nil
+}
+
+// redirectAfterOIDC возвращает браузер в интерфейс. Токен передается во
+// фрагменте URL: он не уходит на сервер и не попадает в журналы прокси
+func redirectAfterOIDC(w http.ResponseWriter, r *http.Request, cfg *config.OIDCConfig, key, value string) {
+	target := cfg.PostLoginURL
+	if target == "" {
+		target = "/"
+	}
+	http.Redirect(w, r, target+"#"+key+"="+url.QueryEscape(value), http.StatusFound)
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
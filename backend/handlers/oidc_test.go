@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"path/filepath"
+	"testing"
+)
+
+func TestOIDCUser(t *testing.T) {
+	config.UsersFile = filepath.Join(t.TempDir(), "users.json")
+	if err := config.SaveUsers([]models.User{
+		{ID: "1", Username: "root", Role: models.RoleAdmin},
+		{ID: "2", Username: "alice", AuthSource: models.AuthSourceOIDC, OIDCIssuer: "https://idp", OIDCSubject: "sub-alice"},
+		{ID: "3", Username: "legacy", AuthSource: models.AuthSourceOIDC},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.OIDCConfig{DefaultRole: "user"}
+
+	tests := []struct {
+		issuer, subject, username string
+		wantID                    string
+		wantErr                   bool
+	}{
+		// Пользователь находится по sub, даже если preferred_username сменился
+		{"https://idp", "sub-alice", "alice.renamed", "2", false},
+		// Чужой sub с тем же preferred_username не получает вход под alice
+		{"https://idp", "sub-mallory", "alice", "", true},
+		// Тот же sub у другого провайдера - другая учетная запись
+		{"https://other", "sub-alice", "alice", "", true},
+		{"https://idp", "sub-root", "root", "", true},
+		{"https://idp", "", "bob", "", true},
+		// Пользователь без sub связывается при первом входе
+		{"https://idp", "sub-legacy", "legacy", "3", false},
+		{"https://idp", "sub-other", "legacy", "", true},
+	}
+
+	for _, tt := range tests {
+		user, err := oidcUser(cfg, tt.issuer, tt.subject, tt.username, "")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("oidcUser(%s, %s, %s) error = %v, wantErr %v", tt.issuer, tt.subject, tt.username, err, tt.wantErr)
+			continue
+		}
+		if err == nil && user.ID != tt.wantID {
+			t.Errorf("oidcUser(%s, %s, %s) = user %s, want %s", tt.issuer, tt.subject, tt.username, user.ID, tt.wantID)
+		}
+	}
+
+	user, err := oidcUser(cfg, "https://idp", "sub-bob", "bob", "bob@example.com")
+	if err != nil || user.AuthSource != models.AuthSourceOIDC || user.OIDCSubject != "sub-bob" || user.Role != "user" {
+		t.Fatalf("oidcUser(bob) = %+v, %v; want new OIDC user", user, err)
+	}
+	if again, err := oidcUser(cfg, "https://idp", "sub-bob", "bob", ""); err != nil || again.ID != user.ID {
+		t.Errorf("repeated oidcUser(bob) = %+v, %v; want user %s", again, err, user.ID)
+	}
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// literalFunc превращает значение параметра в литерал языка запросов
+type literalFunc func(value interface{}) (string, error)
+
+// prepareQuery подставляет именованные параметры :name. Драйверы,
+// умеющие привязывать параметры, получают запрос с $1, $2... и args;
+// остальным значения подставляются экранированными литералами.
+func prepareQuery(driver database.DatabaseDriver, dbType models.DatabaseType, query string, params map[string]interface{}) (string, []interface{}, error) {
+	if len(params) == 0 {
+		return query, nil, nil
+	}
+
+	backslash := backslashEscapes(dbType)
+	if _, ok := driver.(database.ParameterizedExecutor); ok {
+		return expandNamedParams(query, params, nil, backslash)
+	}
+
+	literal := queryLiteralFunc(dbType)
+	if literal == nil {
+		return "", nil, fmt.Errorf("параметры запроса не поддерживаются для %s", dbType)
+	}
+	return expandNamedParams(query, params, literal, backslash)
+}
+
+// backslashEscapes сообщает, экранирует ли обратный слеш кавычку в строках
+// языка запросов. В PostgreSQL, SQLite и других СУБД со стандартными
+// строками это обычный символ; строки E'...' PostgreSQL expandNamedParams
+// распознает сам
+func backslashEscapes(dbType models.DatabaseType) bool {
+	switch dbType {
+	case models.Cassandra, models.Druid, models.Oracle, models.SQLite,
+		models.PostgreSQL, models.CockroachDB, models.Supabase:
+		return false
+	}
+	return true
+}
+
+func queryLiteralFunc(dbType models.DatabaseType) literalFunc {
+	switch dbType {
+	case models.MongoDB, models.Elasticsearch, models.Meilisearch:
+		return jsonLiteral
+	case models.ClickHouse, models.Couchbase:
+		return func(value interface{}) (string, error) {
+			return sqlLiteral(value, true)
+		}
+	case models.Neo4j:
+		return cypherLiteral
+	case models.InfluxDB:
+		return influxQLLiteral
+	case models.Cassandra, models.Druid, models.Oracle, models.SQLite,
+		models.PostgreSQL, models.CockroachDB, models.Supabase:
+		return func(value interface{}) (string, error) {
+			return sqlLiteral(value, false)
+		}
+	}
+	return nil
+}
+
+// expandNamedParams заменяет :name вне строк, идентификаторов в кавычках
+// и комментариев; приведения вида ::type не трогаются. При literal == nil
+// плейсхолдер становится $n (повторное имя получает тот же номер).
+// backslash - обратный слеш экранирует кавычку во всех строках, а не только
+// в E'...'
+func expandNamedParams(query string, params map[string]interface{}, literal literalFunc, backslash bool) (string, []interface{}, error) {
+	var out strings.Builder
+	var args []interface{}
+	positions := make(map[string]int)
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			escapeString := c == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isParamChar(query[i-2]))
+			end := skipQuoted(query, i, backslash || escapeString)
+			out.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			out.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query)
+			} else {
+				end += i + 4
+			}
+			out.WriteString(query[i:end])
+			i = end
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			out.WriteString("::")
+			i += 2
+		case c == ':' && i+1 < len(query) && isParamStart(query[i+1]) && (i == 0 || !isParamChar(query[i-1])):
+			end := i + 1
+			for end < len(query) && isParamChar(query[end]) {
+				end++
+			}
+			name := query[i+1 : end]
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("параметр :%s не передан", name)
+			}
+
+			if literal != nil {
+				text, err := literal(value)
+				if err != nil {
+					return "", nil, fmt.Errorf("параметр :%s: %w", name, err)
+				}
+				out.WriteString(text)
+			} else {
+				position, seen := positions[name]
+				if !seen {
+					args = append(args, value)
+					position = len(args)
+					positions[name] = position
+				}
+				out.WriteString("$" + strconv.Itoa(position))
+			}
+			i = end
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+
+	return out.String(), args, nil
+}
+
+// skipQuoted возвращает позицию после закрывающей кавычки; удвоенная
+// кавычка, а при backslash и экранирование обратным слешем считаются
+// частью строки
+func skipQuoted(query string, start int, backslash bool) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslash {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isParamChar(c byte) bool {
+	return isParamStart(c) || (c >= '0' && c <= '9')
+}
+
+func jsonLiteral(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// sqlLiteral экранирует значение для SQL-подобных языков. escapeBackslash
+// нужен там, где обратный слеш внутри строки - escape-символ (ClickHouse, N1QL)
+func sqlLiteral(value interface{}, escapeBackslash bool) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "TRUE", nil
+		}
+		return "FALSE", nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case json.Number:
+		if _, err := v.Float64(); err != nil {
+			return "", err
+		}
+		return v.String(), nil
+	case string:
+		if escapeBackslash {
+			v = strings.ReplaceAll(v, `\`, `\\`)
+		}
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'", nil
+	}
+	return "", fmt.Errorf("неподдерживаемый тип значения %T", value)
+}
+
+// cypherLiteral: в Cypher кавычка внутри строки экранируется только
+// обратным слешем, удвоение '' дает две соседние строки
+func cypherLiteral(value interface{}) (string, error) {
+	if value == nil {
+		return "null", nil
+	}
+	return backslashLiteral(value)
+}
+
+// influxQLLiteral: строки InfluxQL экранируются обратным слешем, NULL в
+// языке нет
+func influxQLLiteral(value interface{}) (string, error) {
+	if value == nil {
+		return "", fmt.Errorf("null не поддерживается в InfluxQL")
+	}
+	return backslashLiteral(value)
+}
+
+func backslashLiteral(value interface{}) (string, error) {
+	if v, ok := value.(string); ok {
+		v = strings.ReplaceAll(v, `\`, `\\`)
+		return "'" + strings.ReplaceAll(v, "'", `\'`) + "'", nil
+	}
+	return sqlLiteral(value, true)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"testing"
+)
+
+func TestQueryLiteralFunc(t *testing.T) {
+	tests := []struct {
+		dbType  models.DatabaseType
+		value   interface{}
+		want    string
+		wantErr bool
+	}{
+		{models.SQLite, `it's`, `'it''s'`, false},
+		{models.SQLite, `a\`, `'a\'`, false},
+		{models.ClickHouse, `a\' OR 1=1 --`, `'a\\'' OR 1=1 --'`, false},
+		{models.Couchbase, `a\' OR 1=1 --`, `'a\\'' OR 1=1 --'`, false},
+		{models.Neo4j, `it's`, `'it\'s'`, false},
+		{models.Neo4j, `a\' OR 1=1 //`, `'a\\\' OR 1=1 //'`, false},
+		{models.Neo4j, nil, "null", false},
+		{models.Neo4j, json.Number("42"), "42", false},
+		{models.InfluxDB, `cpu'; DROP MEASUREMENT cpu`, `'cpu\'; DROP MEASUREMENT cpu'`, false},
+		{models.InfluxDB, `a\`, `'a\\'`, false},
+		{models.InfluxDB, true, "TRUE", false},
+		{models.InfluxDB, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := queryLiteralFunc(tt.dbType)(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s literal(%#v) error = %v, wantErr %v", tt.dbType, tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s literal(%#v) = %s, want %s", tt.dbType, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExpandNamedParamsBackslash(t *testing.T) {
+	params := map[string]interface{}{"id": json.Number("7")}
+	tests := []struct {
+		dbType models.DatabaseType
+		query  string
+		want   string
+	}{
+		{models.PostgreSQL, `SELECT 'a\' WHERE id = :id`, `SELECT 'a\' WHERE id = $1`},
+		{models.SQLite, `SELECT 'a\' WHERE id = :id`, `SELECT 'a\' WHERE id = 7`},
+		{models.PostgreSQL, `SELECT E'a\' :id' WHERE id = :id`, `SELECT E'a\' :id' WHERE id = $1`},
+		{models.ClickHouse, `SELECT 'a\' :id' WHERE id = :id`, `SELECT 'a\' :id' WHERE id = 7`},
+		{models.Neo4j, `RETURN 'a\' :id', :id`, `RETURN 'a\' :id', 7`},
+	}
+
+	for _, tt := range tests {
+		literal := queryLiteralFunc(tt.dbType)
+		if tt.dbType == models.PostgreSQL {
+			literal = nil
+		}
+		got, _, err := expandNamedParams(tt.query, params, literal, backslashEscapes(tt.dbType))
+		if err != nil {
+			t.Errorf("%s expandNamedParams(%s): %v", tt.dbType, tt.query, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s expandNamedParams(%s) = %s, want %s", tt.dbType, tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package handlers
+
+import (
+	"database-manager/config"
+	"database-manager/models"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func ListProfilesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	profiles := config.GetProfiles()
+	if profiles == nil {
+		profiles = []models.Profile{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profiles)
+}
+
+func GetProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/profiles/")
+	profile, err := config.GetProfileByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profile)
+}
+
+func CreateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.ProfileRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := validateProfileRequest(req, ""); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Connections == nil {
+		req.Connections = map[string]string{}
+	}
+
+	profile := models.Profile{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(req.Name),
+		Connections: req.Connections,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if err := config.AddProfile(profile); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(profile)
+}
+
+func UpdateProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/profiles/")
+	existing, err := config.GetProfileByID(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	var req models.ProfileRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if err := validateProfileRequest(req, id); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Connections == nil {
+		req.Connections = map[string]string{}
+	}
+
+	existing.Name = strings.TrimSpace(req.Name)
+	existing.Connections = req.Connections
+	existing.UpdatedAt = time.Now()
+
+	if err := config.UpdateProfile(id, *existing); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(existing)
+}
+
+func DeleteProfileHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/profiles/")
+	if err := config.DeleteProfile(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"success": true})
+}
+
+// ActiveProfileHandler возвращает (GET) или переключает (PUT) активный профиль
+// текущего пользователя. Пустой profileId сбрасывает выбор.
+func ActiveProfileHandler(w http.ResponseWriter, r *http.Request) {
+	user, err := config.GetUserByID(r.Header.Get("UserID"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req models.SetActiveProfileRequest
+		if !decodeJSONBody(w, r, &req) {
+			return
+		}
+
+		if req.ProfileID != "" {
+			if _, err := config.GetProfileByID(req.ProfileID); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		}
+
+		user.ActiveProfile = req.ProfileID
+		if err := config.UpdateUser(*user); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var profile *models.Profile
+	if user.ActiveProfile != "" {
+		// Профиль мог быть удален после выбора - в этом случае возвращаем null
+		profile, _ = config.GetProfileByID(user.ActiveProfile)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"profileId": user.ActiveProfile,
+		"profile":   profile,
+	})
+}
+
+// ResolveConnectionHandler сопоставляет логическое имя подключения с
+// физическим подключением активного профиля пользователя. Профиль можно
+// указать явно через параметр profileId.
+func ResolveConnectionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		http.Error(w, "name не указан", http.StatusBadRequest)
+		return
+	}
+
+	profileID := r.URL.Query().Get("profileId")
+	if profileID == "" {
+		user, err := config.GetUserByID(r.Header.Get("UserID"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		profileID = user.ActiveProfile
+	}
+
+	if profileID == "" {
+		http.Error(w, "Активный профиль не выбран", http.StatusBadRequest)
+		return
+	}
+
+	resolved, err := resolveConnection(profileID, name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resolved)
+}
+
+func resolveConnection(profileID, name string) (*models.ResolvedConnection, error) {
+	profile, err := config.GetProfileByID(profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	connectionID, ok := profile.Connections[name]
+	if !ok {
+		return nil, fmt.Errorf("подключение %q не задано в профиле %s", name, profile.Name)
+	}
+
+	if _, err := config.GetConnectionByID(connectionID); err != nil {
+		return nil, err
+	}
+
+	return &models.ResolvedConnection{
+		Name:         name,
+		ProfileID:    profile.ID,
+		ProfileName:  profile.Name,
+		ConnectionID: connectionID,
+		Connected:    connManager.IsConnected(connectionID),
+	}, nil
+}
+
+func validateProfileRequest(req models.ProfileRequest, id string) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return fmt.Errorf("Имя профиля обязательно")
+	}
+
+	for _, profile := range config.GetProfiles() {
+		if profile.ID != id && strings.EqualFold(profile.Name, name) {
+			return fmt.Errorf("Профиль с именем %s уже существует", name)
+		}
+	}
+
+	for logicalName, connectionID := range req.Connections {
+		if strings.TrimSpace(logicalName) == "" {
+			return fmt.Errorf("Логическое имя подключения не может быть пустым")
+		}
+		if _, err := config.GetConnectionByID(connectionID); err != nil {
+			return fmt.Errorf("%s: %w", logicalName, err)
+		}
+	}
+
+	return nil
+}
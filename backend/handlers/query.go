@@ -2,12 +2,24 @@ package handlers
 
 import (
 	"context"
+	"database-manager/config"
+	"database-manager/database"
 	"database-manager/models"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// queryTimeout - предел времени выполнения одного запроса, если у
+// подключения не задан свой queryTimeoutSeconds
+const queryTimeout = 30 * time.Second
+
+// writeDeadlineMargin - запас на запись ответа после истечения таймаута запроса
+const writeDeadlineMargin = 10 * time.Second
+
 func ExecuteQueryHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
@@ -15,27 +27,434 @@ func ExecuteQueryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.QueryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	var dbType models.DatabaseType
+	var cacheTTL time.Duration
+	if conn, err := config.GetConnectionByID(req.ConnectionID); err == nil {
+		dbType = conn.Type
+		cacheTTL, _ = conn.CacheDuration()
+	}
+	maxRows := connectionMaxRows(req.ConnectionID)
+
+	redactor, err := connectionRedactor(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query, args, err := prepareQuery(driver, dbType, req.Query, req.Params.Named)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Позиционные параметры $1, $2... передаются драйверу как есть
+	if len(req.Params.Positional) > 0 {
+		if _, ok := driver.(database.ParameterizedExecutor); !ok {
+			http.Error(w, "Позиционные параметры поддерживаются только для PostgreSQL, CockroachDB и Supabase", http.StatusBadRequest)
+			return
+		}
+		query, args = req.Query, req.Params.Positional
+	}
+
+	// На подключениях с requireApproval запрос на изменение от
+	// не-администратора не выполняется, а ждет подтверждения
+	if !database.IsReadStatement(query) && approvalRequired(r, req.ConnectionID) {
+		queueForApproval(w, r, req)
+		return
+	}
+
+	paged := req.PageSize > 0 || req.PageState != ""
+	_, ok := driver.(database.PagedExecutor)
+	if paged && !ok {
+		http.Error(w, "Постраничное выполнение запросов поддерживается только для Cassandra", http.StatusBadRequest)
+		return
+	}
+
+	graphMode := req.ResultMode == models.ResultModeGraph
+	_, ok = driver.(database.GraphExecutor)
+	if req.ResultMode != "" && !graphMode {
+		http.Error(w, "Неизвестный resultMode, допустимо: graph", http.StatusBadRequest)
+		return
+	}
+	if graphMode && !ok {
+		http.Error(w, "Режим graph поддерживается только для Neo4j", http.StatusBadRequest)
+		return
+	}
+
+	sessionExecutor, ok := driver.(database.SessionVarsExecutor)
+	if len(req.SessionVars) > 0 {
+		if !ok {
+			http.Error(w, "Переменные сессии поддерживаются только для PostgreSQL, CockroachDB и Supabase", http.StatusBadRequest)
+			return
+		}
+		if err := sessionExecutor.ValidateSessionVars(req.SessionVars); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Кэшируются только запросы на чтение; запросы на запись идут мимо кэша.
+	// Ответ с графом или переменными сессии в кэш не попадает: ключ кэша не
+	// учитывает ни режим, ни переменные
+	var cacheKey queryCacheKey
+	useCache := cacheTTL > 0 && !paged && !graphMode && len(req.SessionVars) == 0 && database.IsReadStatement(query)
+	if useCache {
+		cacheKey, useCache = newQueryCacheKey(req.ConnectionID, query, args)
+	}
+	if useCache {
+		if cached, ok := queryCache.get(cacheKey); ok {
+			publishEvent(r, models.EventQuery, req.ConnectionID, req.Query)
+			config.RecordConnectionQuery(req.ConnectionID)
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(cached)
+			return
+		}
+	}
+
+	releaseSlot, ok := acquireQuerySlot(w, r, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer releaseSlot()
+
+	timeout := connectionQueryTimeout(req.ConnectionID)
+	extendWriteDeadline(w, timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	ctx = database.WithMaxRows(ctx, maxRows)
+
+	execute := func(driver database.DatabaseDriver) (*models.QueryResponse, error) {
+		if paged {
+			return driver.(database.PagedExecutor).ExecuteQueryPage(ctx, query, req.PageSize, req.PageState)
+		} else if graphMode {
+			return driver.(database.GraphExecutor).ExecuteQueryGraph(ctx, query)
+		} else if len(req.SessionVars) > 0 {
+			return driver.(database.SessionVarsExecutor).ExecuteQueryWithSessionVars(ctx, query, args, req.SessionVars)
+		} else if executor, ok := driver.(database.ParameterizedExecutor); ok && len(args) > 0 {
+			return executor.ExecuteQueryArgs(ctx, query, args)
+		}
+		return driver.ExecuteQuery(ctx, query)
+	}
+	result, err := execute(driver)
+	// При обрыве соединения переподключаемся и повторяем запрос на чтение.
+	// Запрос на изменение не повторяется: он мог выполниться до обрыва
+	if ctx.Err() == nil && database.IsConnectionFailure(result, err) {
+		cause := err
+		if cause == nil {
+			cause = errors.New(result.Error)
+		}
+		if retryDriver, releaseRetry, ok := reconnectDriver(r.Context(), req.ConnectionID, driver, cause); ok {
+			defer releaseRetry()
+			if database.IsReadStatement(query) {
+				result, err = execute(retryDriver)
+			}
+		}
+	}
+	publishEvent(r, models.EventQuery, req.ConnectionID, req.Query)
+	config.RecordConnectionQuery(req.ConnectionID)
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "query", err)
+		return
+	}
+	recordSlowQuery(r, req.ConnectionID, req.Query, result, redactor)
+	database.TruncateRows(result, maxRows)
+	// Скрываем до записи в кэш, чтобы открытые значения не хранились в памяти.
+	// При изменении подключения (и его правил) кэш подключения сбрасывается
+	redactor.Apply(result)
+	if useCache && result.Error == "" {
+		queryCache.set(cacheKey, result, cacheTTL)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// reconnectTimeout - предел времени переподключения после обрыва соединения
+const reconnectTimeout = 15 * time.Second
+
+// reconnectDriver переподключает подключение, у которого запрос через
+// драйвер stale завершился ошибкой соединения cause, и возвращает новый
+// драйвер. false - переподключиться не удалось
+func reconnectDriver(ctx context.Context, connectionID string, stale database.DatabaseDriver, cause error) (database.DatabaseDriver, func(), bool) {
+	conn, err := config.GetConnectionByID(connectionID)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	log.Printf("Потеряно соединение с подключением %s (%v), переподключение", conn.Name, cause)
+	ctx, cancel := context.WithTimeout(ctx, reconnectTimeout)
+	defer cancel()
+	if err := connManager.Reconnect(ctx, *conn, stale); err != nil {
+		log.Printf("Ошибка переподключения к %s: %v", conn.Name, err)
+		return nil, nil, false
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		return nil, nil, false
+	}
+	return driver, release, true
+}
+
+// connectionRedactor возвращает правила скрытия столбцов подключения.
+// Правила проверяются при сохранении; если файл подключений исправлен
+// вручную с ошибкой, результат не отдается вовсе, а не отдается открытым
+func connectionRedactor(connectionID string) (*database.ColumnRedactor, error) {
+	conn, err := config.GetConnectionByID(connectionID)
+	if err != nil {
+		return nil, nil
+	}
+	return database.NewColumnRedactor(conn.RedactColumns)
+}
+
+// connectionMaxRows возвращает предел строк результата: из подключения,
+// иначе общий из app.json; 0 - без предела
+func connectionMaxRows(connectionID string) int {
+	if conn, err := config.GetConnectionByID(connectionID); err == nil && conn.MaxRows > 0 {
+		return conn.MaxRows
+	}
+	return config.GetAppConfig().MaxRows
+}
+
+// connectionQueryTimeout возвращает таймаут запроса: queryTimeoutSeconds
+// подключения, иначе queryTimeout
+func connectionQueryTimeout(connectionID string) time.Duration {
+	if conn, err := config.GetConnectionByID(connectionID); err == nil && conn.QueryTimeoutSeconds > 0 {
+		return time.Duration(conn.QueryTimeoutSeconds) * time.Second
+	}
+	return queryTimeout
+}
+
+// extendWriteDeadline продлевает WriteTimeout сервера на время запроса:
+// иначе таймаут подключения длиннее WriteTimeout обрывал бы ответ
+func extendWriteDeadline(w http.ResponseWriter, timeout time.Duration) {
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout + writeDeadlineMargin))
+}
+
+// redactConnectionResult скрывает столбцы результата по правилам подключения
+func redactConnectionResult(connectionID string, result *models.QueryResponse) error {
+	redactor, err := connectionRedactor(connectionID)
+	if err != nil {
+		return err
+	}
+	redactor.Apply(result)
+	return nil
+}
+
+// queryTarget - подключение и его настройки выполнения запросов
+type queryTarget struct {
+	connectionID string
+	driver       database.DatabaseDriver
+	dbType       models.DatabaseType
+	cacheTTL     time.Duration
+	timeout      time.Duration
+	maxRows      int
+	redactor     *database.ColumnRedactor
+}
+
+// newQueryTarget читает настройки подключения для runTargetQuery
+func newQueryTarget(connectionID string, driver database.DatabaseDriver) (queryTarget, error) {
+	target := queryTarget{
+		connectionID: connectionID,
+		driver:       driver,
+		timeout:      connectionQueryTimeout(connectionID),
+		maxRows:      connectionMaxRows(connectionID),
+	}
+	if conn, err := config.GetConnectionByID(connectionID); err == nil {
+		target.dbType = conn.Type
+		target.cacheTTL, _ = conn.CacheDuration()
+	}
+	redactor, err := connectionRedactor(connectionID)
+	if err != nil {
+		return target, err
+	}
+	target.redactor = redactor
+	return target, nil
+}
+
+// runTargetQuery выполняет запрос так же, как /api/query: с параметрами,
+// кэшем, слотом подключения, таймаутом, пределом строк и скрытием столбцов.
+// Используется пакетом запросов и сравнением результатов. Ошибки
+// возвращаются в поле Error ответа
+func runTargetQuery(ctx context.Context, target queryTarget, rawQuery string, params models.QueryParams) *models.QueryResponse {
+	query, args, err := prepareQuery(target.driver, target.dbType, rawQuery, params.Named)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}
+	}
+
+	executor, parameterized := target.driver.(database.ParameterizedExecutor)
+	if len(params.Positional) > 0 {
+		if !parameterized {
+			return &models.QueryResponse{Error: "Позиционные параметры поддерживаются только для PostgreSQL, CockroachDB и Supabase"}
+		}
+		query, args = rawQuery, params.Positional
+	}
+
+	var cacheKey queryCacheKey
+	useCache := target.cacheTTL > 0 && database.IsReadStatement(query)
+	if useCache {
+		cacheKey, useCache = newQueryCacheKey(target.connectionID, query, args)
+	}
+	if useCache {
+		if cached, ok := queryCache.get(cacheKey); ok {
+			return cached
+		}
+	}
+
+	releaseSlot, err := connManager.AcquireQuerySlot(ctx, target.connectionID)
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}
+	}
+	defer releaseSlot()
 
-	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, target.timeout)
 	defer cancel()
+	ctx = database.WithMaxRows(ctx, target.maxRows)
+
+	var result *models.QueryResponse
+	if parameterized && len(args) > 0 {
+		result, err = executor.ExecuteQueryArgs(ctx, query, args)
+	} else {
+		result, err = target.driver.ExecuteQuery(ctx, query)
+	}
+	if err != nil {
+		return &models.QueryResponse{Error: err.Error()}
+	}
+	database.TruncateRows(result, target.maxRows)
+	target.redactor.Apply(result)
+	if useCache && result.Error == "" {
+		queryCache.set(cacheKey, result, target.cacheTTL)
+	}
+	return result
+}
+
+// acquireQuerySlot занимает слот запроса подключения; при превышении
+// предела отвечает 429. false - ответ уже отправлен
+func acquireQuerySlot(w http.ResponseWriter, r *http.Request, connectionID string) (func(), bool) {
+	releaseSlot, err := connManager.AcquireQuerySlot(r.Context(), connectionID)
+	if err != nil {
+		if errors.Is(err, database.ErrQueryLimit) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		} else {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+		return nil, false
+	}
+	return releaseSlot, true
+}
+
+// ExecuteTransactionHandler выполняет пакет команд одной транзакцией
+func ExecuteTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TransactionRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || len(req.Commands) == 0 {
+		http.Error(w, "connectionId и commands обязательны", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	executor, ok := driver.(database.TransactionExecutor)
+	if !ok {
+		http.Error(w, "Транзакции из набора команд не поддерживаются для этого типа БД", http.StatusBadRequest)
+		return
+	}
 
-	result, err := driver.ExecuteQuery(ctx, req.Query)
+	// Транзакция считается изменением: на подключениях только для чтения и
+	// с requireApproval она не выполняется
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	redactor, err := connectionRedactor(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	releaseSlot, ok := acquireQuerySlot(w, r, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer releaseSlot()
+
+	timeout := connectionQueryTimeout(req.ConnectionID)
+	extendWriteDeadline(w, timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	result, err := executor.ExecuteTransaction(ctx, req.Commands)
+	publishEvent(r, models.EventQuery, req.ConnectionID, strings.Join(req.Commands, "; "))
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "transaction", err)
+		return
+	}
+	redactor.Apply(result)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
 }
 
+// CompileFilterHandler собирает из упрощенного фильтра нативный запрос
+// (bool-запрос Elasticsearch, фильтр MongoDB, filter Meilisearch). Результат
+// можно передать в /api/query без изменений
+func CompileFilterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.CompileFilterRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	compiler, ok := driver.(database.FilterCompiler)
+	if !ok {
+		http.Error(w, "Конструктор фильтров не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	query, err := compiler.CompileFilter(req.Filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query": query,
+	})
+}
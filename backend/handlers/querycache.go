@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"database-manager/models"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// queryResultCache хранит результаты запросов на чтение для подключений
+// с заданным cacheTtl. Записи удаляются по истечении TTL и при изменении
+// подключения
+type queryResultCache struct {
+	mu      sync.Mutex
+	entries map[queryCacheKey]queryCacheEntry
+}
+
+type queryCacheKey struct {
+	connectionID string
+	query        string
+}
+
+type queryCacheEntry struct {
+	result  models.QueryResponse
+	expires time.Time
+}
+
+var queryCache = &queryResultCache{entries: make(map[queryCacheKey]queryCacheEntry)}
+
+// newQueryCacheKey учитывает параметры: один и тот же текст запроса
+// с разными значениями дает разные результаты
+func newQueryCacheKey(connectionID, query string, args []interface{}) (queryCacheKey, bool) {
+	key := queryCacheKey{connectionID: connectionID, query: query}
+	if len(args) > 0 {
+		data, err := json.Marshal(args)
+		if err != nil {
+			return key, false
+		}
+		key.query += "\x00" + string(data)
+	}
+	return key, true
+}
+
+func (c *queryResultCache) get(key queryCacheKey) (*models.QueryResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	result := entry.result
+	result.Cached = true
+	return &result, true
+}
+
+func (c *queryResultCache) set(key queryCacheKey, result *models.QueryResponse, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = queryCacheEntry{result: *result, expires: now.Add(ttl)}
+}
+
+// invalidate удаляет результаты подключения: после изменения настроек
+// (правила redactColumns, база) прежние результаты неактуальны
+func (c *queryResultCache) invalidate(connectionID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.connectionID == connectionID {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/models"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+func SettingsHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		listSettings(w, r)
+	case http.MethodPut:
+		updateSetting(w, r)
+	default:
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+	}
+}
+
+func listSettings(w http.ResponseWriter, r *http.Request) {
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	manager, release, ok := getSettingsManager(w, connectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	settings, err := manager.ListSettings(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+func updateSetting(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateSettingRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Name == "" {
+		http.Error(w, "connectionId и name обязательны", http.StatusBadRequest)
+		return
+	}
+
+	// ALTER SYSTEM меняет параметры всего сервера, а не одной базы, поэтому
+	// это доступно только администратору
+	if user, err := config.GetUserByID(r.Header.Get("UserID")); err != nil || !user.IsAdmin() {
+		http.Error(w, "Изменять параметры сервера может только администратор", http.StatusForbidden)
+		return
+	}
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	manager, release, ok := getSettingsManager(w, req.ConnectionID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := manager.SetSetting(ctx, req.Name, req.Value, req.Scope); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"name":    req.Name,
+		"value":   req.Value,
+	})
+}
+
+func getSettingsManager(w http.ResponseWriter, connectionID string) (database.SettingsManager, func(), bool) {
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	manager, ok := driver.(database.SettingsManager)
+	if !ok {
+		http.Error(w, "Управление настройками не поддерживается для этого типа БД", http.StatusBadRequest)
+		release()
+		return nil, nil, false
+	}
+
+	return manager, release, true
+}
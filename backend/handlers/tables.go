@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"database-manager/database"
 	"database-manager/models"
 	"encoding/json"
 	"net/http"
@@ -15,30 +16,59 @@ func CreateTableHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.CreateTableRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	// DDL меняет схему: на подключениях только для чтения и с requireApproval
+	// выполняется только администратором; предпросмотр разрешен всем
+	if !isDryRun(r) && !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	if err := driver.CreateTable(ctx, req.Name, req.Columns); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanCreateTable(ctx, req.Name, req.Columns, req.TableConstraints)
+		writeDryRun(w, statements, err)
 		return
 	}
 
+	if req.TableConstraints.IsEmpty() {
+		err = driver.CreateTable(ctx, req.Name, req.Columns)
+	} else {
+		creator, ok := driver.(database.ConstrainedTableCreator)
+		if !ok {
+			http.Error(w, "Составной первичный ключ и ограничения CHECK не поддерживаются для этого типа БД", http.StatusBadRequest)
+			return
+		}
+		err = creator.CreateTableWithConstraints(ctx, req.Name, req.Columns, req.TableConstraints)
+	}
+	if err != nil {
+		driverError(w, r, req.ConnectionID, "create-table", err)
+		return
+	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "CREATE TABLE "+req.Name)
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withExecutedSQL(map[string]interface{}{
 		"success": true,
 		"name":    req.Name,
-	})
+	}, driver, func(planner database.DDLPlanner) ([]string, error) {
+		return planner.PlanCreateTable(ctx, req.Name, req.Columns, req.TableConstraints)
+	}))
 }
 
 func ListTablesHandler(w http.ResponseWriter, r *http.Request) {
@@ -53,25 +83,80 @@ func ListTablesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	tables, err := driver.ListTables(ctx)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, connectionID, "list-tables", err)
 		return
 	}
 
+	// exactRows=true заменяет оценку числа строк точным count(*) по каждой
+	// таблице. Это медленно на больших таблицах, поэтому по умолчанию выключено.
+	if r.URL.Query().Get("exactRows") == "true" {
+		if counter, ok := driver.(database.ExactRowCounter); ok {
+			for i := range tables {
+				count, err := counter.CountRows(ctx, tables[i].Name)
+				if err != nil {
+					continue
+				}
+				tables[i].Rows = count
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(tables)
 }
 
+// DescribeTableHandler возвращает столбцы таблицы: GET /api/tables/describe?connectionId=&table=
+func DescribeTableHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	table := r.URL.Query().Get("table")
+	if connectionID == "" || table == "" {
+		http.Error(w, "connectionId и table обязательны", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	describer, ok := driver.(database.TableDescriber)
+	if !ok {
+		http.Error(w, "Описание таблиц не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	columns, err := describer.DescribeTable(ctx, table)
+	if err != nil {
+		driverError(w, r, connectionID, "describe-table", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(columns)
+}
+
 func DeleteTableHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
@@ -86,19 +171,89 @@ func DeleteTableHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	// Для предпросмотра подтверждение не нужно: ничего не удаляется
+	if !isDryRun(r) && !checkDeleteConfirmation(w, r, name) {
+		return
+	}
+
+	if !isDryRun(r) && !checkWritable(w, r, connectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanDeleteTable(name)
+		writeDryRun(w, statements, err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	if err := driver.DeleteTable(ctx, name); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, connectionID, "delete-table", err)
 		return
 	}
+	publishEvent(r, models.EventDDL, connectionID, "DROP TABLE "+name)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// TruncateTableHandler удаляет все строки таблицы, сохраняя ее структуру
+func TruncateTableHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.TruncateTableRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Name == "" {
+		http.Error(w, "connectionId и name обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	truncater, ok := driver.(database.TableTruncater)
+	if !ok {
+		http.Error(w, "Очистка таблиц не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	if err := truncater.TruncateTable(ctx, req.Name); err != nil {
+		driverError(w, r, req.ConnectionID, "truncate-table", err)
+		return
+	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "TRUNCATE "+req.Name)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -113,29 +268,151 @@ func UpdateTableHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateTableRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	if !isDryRun(r) && !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	if isDryRun(r) {
+		planner, ok := ddlPlanner(w, driver)
+		if !ok {
+			return
+		}
+		statements, err := planner.PlanUpdateTable(req.OldName, req.NewName, req.Columns)
+		writeDryRun(w, statements, err)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
 	if err := driver.UpdateTable(ctx, req.OldName, req.NewName, req.Columns); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		driverError(w, r, req.ConnectionID, "update-table", err)
 		return
 	}
+	publishEvent(r, models.EventDDL, req.ConnectionID, "ALTER TABLE "+req.OldName)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	json.NewEncoder(w).Encode(withExecutedSQL(map[string]interface{}{
 		"success": true,
 		"name":    req.NewName,
+	}, driver, func(planner database.DDLPlanner) ([]string, error) {
+		return planner.PlanUpdateTable(req.OldName, req.NewName, req.Columns)
+	}))
+}
+
+
+func BulkDeleteTablesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.BulkDeleteTablesRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || len(req.Names) == 0 {
+		http.Error(w, "connectionId и names обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !req.Confirm {
+		http.Error(w, "Для удаления таблиц необходимо подтверждение (confirm: true)", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	names := req.Names
+	drop := driver.DeleteTable
+	if dropper, ok := driver.(database.DependencyAwareDropper); ok {
+		deps, err := dropper.TableDependencies(ctx)
+		if err != nil {
+			driverError(w, r, req.ConnectionID, "bulk-delete-tables", err)
+			return
+		}
+		names = orderTablesForDrop(req.Names, deps)
+		drop = func(ctx context.Context, name string) error {
+			return dropper.DropTable(ctx, name, req.Cascade)
+		}
+	}
+
+	results := make([]models.TableOperationResult, 0, len(names))
+	for _, name := range names {
+		result := models.TableOperationResult{Name: name, Success: true}
+		if err := drop(ctx, name); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		} else {
+			publishEvent(r, models.EventDDL, req.ConnectionID, "DROP TABLE "+name)
+		}
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"results": results,
 	})
 }
 
+// orderTablesForDrop упорядочивает таблицы так, чтобы ссылающиеся таблицы
+// удалялись раньше тех, на которые они ссылаются. deps: таблица -> родители.
+func orderTablesForDrop(names []string, deps map[string][]string) []string {
+	requested := make(map[string]bool, len(names))
+	for _, name := range names {
+		requested[name] = true
+	}
+
+	referencedBy := make(map[string][]string)
+	for _, child := range names {
+		for _, parent := range deps[child] {
+			if parent != child && requested[parent] {
+				referencedBy[parent] = append(referencedBy[parent], child)
+			}
+		}
+	}
+
+	ordered := make([]string, 0, len(names))
+	visited := make(map[string]bool, len(names))
+	var visit func(name string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		for _, child := range referencedBy[name] {
+			visit(child)
+		}
+		ordered = append(ordered, name)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+
+	return ordered
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/database"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TaskStatusHandler отдаёт состояние фоновой задачи: GET /api/tasks/{id}?connectionId=
+func TaskStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	taskID := strings.TrimPrefix(r.URL.Path, "/api/tasks/")
+	connectionID := r.URL.Query().Get("connectionId")
+	if taskID == "" || connectionID == "" {
+		http.Error(w, "ID задачи и connectionId обязательны", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	tracker, ok := driver.(database.TaskTracker)
+	if !ok {
+		http.Error(w, "Отслеживание задач не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	status, err := tracker.GetTaskStatus(ctx, taskID)
+	if err != nil {
+		driverError(w, r, connectionID, "task-status", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
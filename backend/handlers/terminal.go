@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"database-manager/config"
+	"database-manager/database"
+	"database-manager/middleware"
+	"database-manager/models"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const terminalQueryTimeout = 60 * time.Second
+
+// checkWebSocketOrigin применяет к WebSocket список allowedOrigins:
+// CORS на WebSocket не действует, браузер отправляет Origin без проверки
+func checkWebSocketOrigin(cfg *websocket.Config, r *http.Request) error {
+	if !middleware.OriginAllowed(r.Header.Get("Origin")) {
+		return fmt.Errorf("Origin %q не разрешен", r.Header.Get("Origin"))
+	}
+	return nil
+}
+
+// TerminalHandler открывает WebSocket-сессию для интерактивных запросов:
+// клиент отправляет {"query": "..."} и получает QueryResponse. Если драйвер
+// поддерживает сессии, все запросы идут через одно соединение и состояние
+// (SET, временные таблицы, SELECT db, MULTI/EXEC) сохраняется между ними.
+func TerminalHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	requireApproval := approvalRequired(r, connectionID)
+
+	server := websocket.Server{Handshake: checkWebSocketOrigin, Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// Снимаем таймауты http.Server: сессия живет дольше WriteTimeout
+		ws.SetDeadline(time.Time{})
+
+		ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+		session, err := database.OpenSession(ctx, driver)
+		cancel()
+		if err != nil {
+			websocket.JSON.Send(ws, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		defer session.Close()
+
+		for {
+			var req models.TerminalRequest
+			if err := websocket.JSON.Receive(ws, &req); err != nil {
+				return
+			}
+
+			if rejected := rejectUnapprovedWrite(requireApproval, req.Query); rejected != nil {
+				if err := websocket.JSON.Send(ws, rejected); err != nil {
+					return
+				}
+				continue
+			}
+
+			connManager.Touch(connectionID)
+			maxRows := connectionMaxRows(connectionID)
+			ctx, cancel := context.WithTimeout(r.Context(), terminalQueryTimeout)
+			result, err := session.ExecuteQuery(database.WithMaxRows(ctx, maxRows), req.Query)
+			cancel()
+			publishEvent(r, models.EventQuery, connectionID, req.Query)
+			config.RecordConnectionQuery(connectionID)
+			if err == nil {
+				database.TruncateRows(result, maxRows)
+				err = redactConnectionResult(connectionID, result)
+			}
+			if err != nil {
+				result = &models.QueryResponse{Error: err.Error()}
+			}
+
+			if err := websocket.JSON.Send(ws, result); err != nil {
+				return
+			}
+		}
+	}}
+	server.ServeHTTP(w, r)
+}
@@ -2,12 +2,18 @@ package handlers
 
 import (
 	"context"
+	"database-manager/config"
+	"database-manager/database"
 	"database-manager/models"
+	"database-manager/utils"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"time"
 )
 
+const rotatedPasswordLength = 32
+
 func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
@@ -15,16 +21,22 @@ func CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.CreateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	// Изменение пользователей БД - та же запись: на подключениях только для
+	// чтения и с requireApproval доступно только администратору
+	if !checkWritable(w, r, req.ConnectionID) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
@@ -53,23 +65,96 @@ func ListUsersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	// Без поиска и пагинации ответ - массив пользователей, как раньше
+	params := r.URL.Query()
+	if !params.Has("search") && !params.Has("limit") && !params.Has("offset") {
+		users, err := driver.ListUsers(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(users)
+		return
+	}
+
+	limit, offset, ok := parsePagination(w, r)
+	if !ok {
+		return
+	}
+	filter := models.UserFilter{
+		Search: strings.TrimSpace(params.Get("search")),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	var list *models.UserList
+	if searcher, ok := driver.(database.UserSearcher); ok {
+		list, err = searcher.SearchUsers(ctx, filter)
+	} else {
+		var users []models.UserInfo
+		if users, err = driver.ListUsers(ctx); err == nil {
+			list = database.FilterUsers(users, filter)
+		}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// ListGrantableRolesHandler возвращает роли и привилегии, которые можно
+// передать в permissions при создании и изменении пользователя
+func ListGrantableRolesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	connectionID := r.URL.Query().Get("connectionId")
+	if connectionID == "" {
+		http.Error(w, "connectionId не указан", http.StatusBadRequest)
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	lister, ok := driver.(database.RoleLister)
+	if !ok {
+		http.Error(w, "Список ролей не поддерживается для этого типа БД", http.StatusBadRequest)
+		return
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	users, err := driver.ListUsers(ctx)
+	roles, err := lister.ListGrantableRoles(ctx)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	json.NewEncoder(w).Encode(roles)
 }
 
 func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -79,16 +164,20 @@ func UpdateUserHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req models.UpdateUserRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Ошибка парсинга запроса", http.StatusBadRequest)
+	if !decodeJSONBody(w, r, &req) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(req.ConnectionID)
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
@@ -119,11 +208,16 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	driver, err := connManager.GetDriver(connectionID)
+	if !checkWritable(w, r, connectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(connectionID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
 		return
 	}
+	defer release()
 
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
@@ -139,3 +233,71 @@ func DeleteUserHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+
+func RotatePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RotatePasswordRequest
+	if !decodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if req.ConnectionID == "" || req.Username == "" {
+		http.Error(w, "connectionId и username обязательны", http.StatusBadRequest)
+		return
+	}
+
+	if !checkWritable(w, r, req.ConnectionID) {
+		return
+	}
+
+	driver, release, err := connManager.AcquireDriver(req.ConnectionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer release()
+
+	newPassword := req.Password
+	if newPassword == "" {
+		newPassword, err = utils.GeneratePassword(rotatedPasswordLength)
+		if err != nil {
+			http.Error(w, "Ошибка генерации пароля", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	// permissions = nil, чтобы драйвер не трогал права пользователя
+	if err := driver.UpdateUser(ctx, req.Username, newPassword, nil); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Если сменили пароль пользователя, под которым работает само подключение,
+	// обновляем сохраненную конфигурацию, иначе следующее подключение не пройдет
+	connectionUpdated := false
+	if conn, err := config.GetConnectionByID(req.ConnectionID); err == nil && conn.Username == req.Username {
+		connCopy := *conn
+		connCopy.Password = newPassword
+		connCopy.UpdatedAt = time.Now()
+		if err := config.UpdateConnection(req.ConnectionID, connCopy); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		connectionUpdated = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":           true,
+		"username":          req.Username,
+		"password":          newPassword,
+		"connectionUpdated": connectionUpdated,
+	})
+}
@@ -8,12 +8,15 @@ import (
 	"database-manager/middleware"
 	"database-manager/models"
 	"database-manager/utils"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -21,6 +24,52 @@ func main() {
 	connManager := database.NewConnectionManager()
 	handlers.InitConnectionManager(connManager)
 
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		log.Printf("Ошибка загрузки конфигурации: %v", err)
+	}
+
+	configureJWT(appConfig)
+
+	if appConfig != nil && appConfig.AuthDisabled {
+		if appConfig.IsProduction() {
+			log.Fatal("authDisabled нельзя включать в production-режиме")
+		}
+		log.Println("ВНИМАНИЕ: авторизация отключена (authDisabled) - все запросы выполняются от имени root без токена. Только для локальной разработки!")
+	}
+
+	if appConfig != nil && appConfig.OIDC != nil && appConfig.OIDC.Enabled {
+		if oidc := appConfig.OIDC; oidc.Issuer == "" || oidc.ClientID == "" || oidc.RedirectURL == "" {
+			log.Fatal("Для входа через OIDC нужны oidc.issuer, oidc.clientId и oidc.redirectUrl")
+		}
+	}
+
+	// Предел запросов задается до восстановления подключений: он применяется
+	// при подключении
+	queryLimit, queueWait := 0, config.DefaultQueryQueueTimeout
+	if appConfig != nil {
+		queryLimit = appConfig.MaxConcurrentQueries
+		if wait, err := appConfig.QueryQueueWait(); err != nil {
+			log.Printf("Используется ожидание слота запроса по умолчанию: %v", err)
+		} else {
+			queueWait = wait
+		}
+	}
+	connManager.SetQueryLimit(queryLimit, queueWait)
+	if appConfig != nil {
+		database.SetHTTPRetryAttempts(appConfig.HTTPRetryAttempts)
+		if timeout, err := appConfig.HTTPRequestTimeout(); err != nil {
+			log.Printf("Используется таймаут HTTP-драйверов по умолчанию: %v", err)
+		} else {
+			database.SetHTTPTimeout(timeout)
+		}
+		connManager.SetEnvPrefix(appConfig.ConnectionEnvPrefix)
+		database.SetSQLiteDataDir(appConfig.SQLiteDataDir)
+		if _, err := appConfig.SlowQueryDuration(); err != nil {
+			log.Printf("Журнал медленных запросов выключен: %v", err)
+		}
+	}
+
 	connections, err := config.LoadConnections()
 	if err != nil {
 		log.Printf("Ошибка загрузки подключений: %v", err)
@@ -36,15 +85,29 @@ func main() {
 		log.Printf("Ошибка загрузки пользователей: %v", err)
 	}
 	
+	_, err = config.LoadProfiles()
+	if err != nil {
+		log.Printf("Ошибка загрузки профилей: %v", err)
+	}
+
+	if _, err := config.LoadApprovals(); err != nil {
+		log.Printf("Ошибка загрузки запросов на подтверждение: %v", err)
+	}
+
+	if _, err := config.LoadCharts(); err != nil {
+		log.Printf("Ошибка загрузки графиков: %v", err)
+	}
+
 	// Создаем тестового пользователя root, если его нет
-	_, err = config.GetUserByUsername("root")
+	existingRoot, err := config.GetUserByUsername(models.RootUsername)
 	if err != nil {
 		hashedPassword, _ := utils.HashPassword("1234567890")
 		rootUser := models.User{
-			ID:           "00000000-0000-0000-0000-000000000001",
-			Username:     "root",
+			ID:           models.RootUserID,
+			Username:     models.RootUsername,
 			PasswordHash: hashedPassword,
 			Email:        "",
+			Role:         models.RoleAdmin,
 			CreatedAt:    time.Now(),
 		}
 		if err := config.AddUser(rootUser); err != nil {
@@ -52,12 +115,26 @@ func main() {
 		} else {
 			log.Println("Создан тестовый пользователь root с паролем 1234567890")
 		}
+	} else if existingRoot.Role == "" {
+		// root, созданный до появления ролей, становится администратором
+		existingRoot.Role = models.RoleAdmin
+		if err := config.UpdateUser(*existingRoot); err != nil {
+			log.Printf("Ошибка назначения роли пользователю root: %v", err)
+		}
 	}
 
 	mux := http.NewServeMux()
 
+	mux.HandleFunc("/healthz", handlers.HealthzHandler)
+	mux.HandleFunc("/readyz", handlers.ReadyzHandler)
+	mux.HandleFunc("/api/version", handlers.VersionHandler)
+
 	mux.HandleFunc("/api/auth/register", handlers.RegisterHandler)
 	mux.HandleFunc("/api/auth/login", handlers.LoginHandler)
+	mux.HandleFunc("/api/auth/providers", handlers.AuthProvidersHandler)
+	mux.HandleFunc("/api/auth/oidc/login", handlers.OIDCLoginHandler)
+	mux.HandleFunc("/api/auth/oidc/callback", handlers.OIDCCallbackHandler)
+	mux.HandleFunc("/api/auth/csrf", middleware.AuthMiddleware(http.HandlerFunc(handlers.CSRFTokenHandler)).ServeHTTP)
 
 	mux.HandleFunc("/api/connections", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -70,9 +147,35 @@ func main() {
 		}
 	})
 
+	mux.HandleFunc("/api/database-types", middleware.AuthMiddleware(http.HandlerFunc(handlers.DatabaseTypesHandler)).ServeHTTP)
+
 	mux.HandleFunc("/api/connections/", func(w http.ResponseWriter, r *http.Request) {
 		path := r.URL.Path
 		
+		if path == "/api/connections/export" {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ExportConnectionsHandler)).ServeHTTP(w, r)
+			return
+		}
+		if path == "/api/connections/status" {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ConnectionsStatusHandler)).ServeHTTP(w, r)
+			return
+		}
+		if path == "/api/connections/restore-report" {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.RestoreReportHandler)).ServeHTTP(w, r)
+			return
+		}
+		if path == "/api/connections/import" {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ImportConnectionsHandler)).ServeHTTP(w, r)
+			return
+		}
+		if path == "/api/connections/delete-bulk" {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.BulkDeleteConnectionsHandler)).ServeHTTP(w, r)
+			return
+		}
+		if strings.HasSuffix(path, "/clone") {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.CloneConnectionHandler)).ServeHTTP(w, r)
+			return
+		}
 		if strings.HasSuffix(path, "/connect") {
 			middleware.AuthMiddleware(http.HandlerFunc(handlers.ConnectHandler)).ServeHTTP(w, r)
 			return
@@ -81,6 +184,18 @@ func main() {
 			middleware.AuthMiddleware(http.HandlerFunc(handlers.DisconnectHandler)).ServeHTTP(w, r)
 			return
 		}
+		if strings.HasSuffix(path, "/ping") {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.PingConnectionHandler)).ServeHTTP(w, r)
+			return
+		}
+		if strings.HasSuffix(path, "/use") {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.UseDatabaseHandler)).ServeHTTP(w, r)
+			return
+		}
+		if strings.HasSuffix(path, "/capabilities") {
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ConnectionCapabilitiesHandler)).ServeHTTP(w, r)
+			return
+		}
 		if strings.HasSuffix(path, "/status") {
 			middleware.AuthMiddleware(http.HandlerFunc(handlers.ConnectionStatusHandler)).ServeHTTP(w, r)
 			return
@@ -105,6 +220,30 @@ func main() {
 	})
 
 	mux.HandleFunc("/api/query", middleware.AuthMiddleware(http.HandlerFunc(handlers.ExecuteQueryHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/transaction", middleware.AuthMiddleware(http.HandlerFunc(handlers.ExecuteTransactionHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/batch", middleware.AuthMiddleware(http.HandlerFunc(handlers.ExecuteBatchQueryHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/diff", middleware.AuthMiddleware(http.HandlerFunc(handlers.QueryDiffHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/pending", middleware.AuthMiddleware(http.HandlerFunc(handlers.ListApprovalsHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/approve/", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.ApproveQueryHandler))).ServeHTTP)
+	mux.HandleFunc("/api/query/reject/", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.RejectQueryHandler))).ServeHTTP)
+	mux.HandleFunc("/api/schema/diff", middleware.AuthMiddleware(http.HandlerFunc(handlers.SchemaDiffHandler)).ServeHTTP)
+	mux.HandleFunc("/api/query/compile", middleware.AuthMiddleware(http.HandlerFunc(handlers.CompileFilterHandler)).ServeHTTP)
+	mux.HandleFunc("/api/terminal", middleware.AuthMiddleware(http.HandlerFunc(handlers.TerminalHandler)).ServeHTTP)
+	mux.HandleFunc("/api/data", middleware.AuthMiddleware(http.HandlerFunc(handlers.DataHandler)).ServeHTTP)
+	mux.HandleFunc("/api/data/bulk", middleware.AuthMiddleware(http.HandlerFunc(handlers.DeleteRowsHandler)).ServeHTTP)
+	mux.HandleFunc("/api/data/import", middleware.AuthMiddleware(http.HandlerFunc(handlers.ImportDataHandler)).ServeHTTP)
+	mux.HandleFunc("/api/admin/activity", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.ListActivityHandler))).ServeHTTP)
+	mux.HandleFunc("/api/admin/kill", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.KillQueryHandler))).ServeHTTP)
+	mux.HandleFunc("/api/diagnostics/errors", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.RecentErrorsHandler))).ServeHTTP)
+	mux.HandleFunc("/api/diagnostics/slow-queries", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.SlowQueriesHandler))).ServeHTTP)
+	mux.HandleFunc("/api/crdb/info", middleware.AuthMiddleware(http.HandlerFunc(handlers.CockroachInfoHandler)).ServeHTTP)
+	mux.HandleFunc("/api/es/health", middleware.AuthMiddleware(http.HandlerFunc(handlers.ClusterHealthHandler)).ServeHTTP)
+	mux.HandleFunc("/api/events", middleware.AuthMiddleware(middleware.AdminMiddleware(http.HandlerFunc(handlers.EventsHandler))).ServeHTTP)
+	mux.HandleFunc("/api/pg/listen", middleware.AuthMiddleware(http.HandlerFunc(handlers.ListenHandler)).ServeHTTP)
+	mux.HandleFunc("/api/tasks/", middleware.AuthMiddleware(http.HandlerFunc(handlers.TaskStatusHandler)).ServeHTTP)
+	mux.HandleFunc("/api/indexes", middleware.AuthMiddleware(http.HandlerFunc(handlers.IndexesHandler)).ServeHTTP)
+	mux.HandleFunc("/api/indexes/settings", middleware.AuthMiddleware(http.HandlerFunc(handlers.IndexSettingsHandler)).ServeHTTP)
+	mux.HandleFunc("/api/settings", middleware.AuthMiddleware(http.HandlerFunc(handlers.SettingsHandler)).ServeHTTP)
 	
 	mux.HandleFunc("/api/databases", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -131,8 +270,13 @@ func main() {
 		}
 	})
 	
+	findLimiter := middleware.NewRateLimiter(30, time.Minute)
+	mux.HandleFunc("/api/tables/find", middleware.AuthMiddleware(findLimiter.Middleware(http.HandlerFunc(handlers.FindRowsHandler))).ServeHTTP)
+	mux.HandleFunc("/api/tables/describe", middleware.AuthMiddleware(http.HandlerFunc(handlers.DescribeTableHandler)).ServeHTTP)
+	mux.HandleFunc("/api/tables/truncate", middleware.AuthMiddleware(http.HandlerFunc(handlers.TruncateTableHandler)).ServeHTTP)
 	mux.HandleFunc("/api/tables/update", middleware.AuthMiddleware(http.HandlerFunc(handlers.UpdateTableHandler)).ServeHTTP)
 	mux.HandleFunc("/api/tables/delete", middleware.AuthMiddleware(http.HandlerFunc(handlers.DeleteTableHandler)).ServeHTTP)
+	mux.HandleFunc("/api/tables/bulk-delete", middleware.AuthMiddleware(http.HandlerFunc(handlers.BulkDeleteTablesHandler)).ServeHTTP)
 	
 	mux.HandleFunc("/api/users", func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
@@ -145,8 +289,73 @@ func main() {
 		}
 	})
 	
+	mux.HandleFunc("/api/users/roles", middleware.AuthMiddleware(http.HandlerFunc(handlers.ListGrantableRolesHandler)).ServeHTTP)
 	mux.HandleFunc("/api/users/update", middleware.AuthMiddleware(http.HandlerFunc(handlers.UpdateUserHandler)).ServeHTTP)
 	mux.HandleFunc("/api/users/delete", middleware.AuthMiddleware(http.HandlerFunc(handlers.DeleteUserHandler)).ServeHTTP)
+	mux.HandleFunc("/api/users/rotate-password", middleware.AuthMiddleware(http.HandlerFunc(handlers.RotatePasswordHandler)).ServeHTTP)
+
+	mux.HandleFunc("/api/profiles", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ListProfilesHandler)).ServeHTTP(w, r)
+		case http.MethodPost:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.CreateProfileHandler)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/profiles/active", middleware.AuthMiddleware(http.HandlerFunc(handlers.ActiveProfileHandler)).ServeHTTP)
+	mux.HandleFunc("/api/profiles/resolve", middleware.AuthMiddleware(http.HandlerFunc(handlers.ResolveConnectionHandler)).ServeHTTP)
+
+	mux.HandleFunc("/api/profiles/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/profiles/")
+		if id == "" {
+			http.Error(w, "ID профиля не указан", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.GetProfileHandler)).ServeHTTP(w, r)
+		case http.MethodPut:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.UpdateProfileHandler)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.DeleteProfileHandler)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/charts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.ListChartsHandler)).ServeHTTP(w, r)
+		case http.MethodPost:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.CreateChartHandler)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/charts/", func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/charts/")
+		if id == "" {
+			http.Error(w, "ID графика не указан", http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.GetChartHandler)).ServeHTTP(w, r)
+		case http.MethodPut:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.UpdateChartHandler)).ServeHTTP(w, r)
+		case http.MethodDelete:
+			middleware.AuthMiddleware(http.HandlerFunc(handlers.DeleteChartHandler)).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		}
+	})
 
 	var htmxDir string
 	// Проверяем, установлен ли пакет (путь /usr/share/database-manager/htmx существует)
@@ -181,11 +390,17 @@ func main() {
 		http.NotFound(w, r)
 	})
 
-	handler := middleware.ProxyMiddleware(middleware.CORSMiddleware(mux))
+	if appConfig == nil || len(appConfig.AllowedOrigins) == 0 {
+		log.Println("ВНИМАНИЕ: allowedOrigins не задан - API доступно из браузера с любого сайта (режим разработки)")
+	}
 
-	appConfig, err := config.LoadAppConfig()
-	if err != nil {
-		log.Printf("Ошибка загрузки конфигурации: %v", err)
+	handler := middleware.CORSMiddleware(middleware.CSRFMiddleware(mux))
+	if appConfig != nil && appConfig.TrustProxyHeaders {
+		trustedProxies, err := middleware.ParseTrustedProxies(appConfig.TrustedProxies)
+		if err != nil {
+			log.Fatalf("Ошибка конфигурации trustedProxies: %v", err)
+		}
+		handler = middleware.ProxyMiddleware(handler, trustedProxies)
 	}
 
 	host := os.Getenv("HOST")
@@ -206,6 +421,28 @@ func main() {
 		}
 	}
 
+	idleTimeout := time.Duration(0)
+	if appConfig != nil {
+		if idleTimeout, err = appConfig.IdleDisconnectAfter(); err != nil {
+			log.Printf("Автоотключение простаивающих подключений выключено: %v", err)
+		}
+	}
+	if idleTimeout > 0 {
+		connManager.StartIdleSweeper(ctx, idleTimeout, func(id string) {
+			conn, err := config.GetConnectionByID(id)
+			if err != nil {
+				return
+			}
+			updated := *conn
+			updated.Connected = false
+			if err := config.UpdateConnection(id, updated); err != nil {
+				log.Printf("Ошибка сохранения состояния подключения %s: %v", id, err)
+				return
+			}
+			log.Printf("Подключение %s отключено после простоя дольше %s", id, idleTimeout)
+		})
+	}
+
 	displayHost := host
 	if displayHost == "0.0.0.0" {
 		displayHost = "localhost"
@@ -219,15 +456,79 @@ func main() {
 	}
 	
 	server := &http.Server{
-		Addr:         host + ":" + port,
-		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              host + ":" + port,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      15 * time.Second,
+		IdleTimeout:       60 * time.Second,
+		MaxHeaderBytes:    1 << 20,
 	}
 	
-	if err := server.ListenAndServe(); err != nil {
+	// Счетчики запросов подключений копятся в памяти и сохраняются
+	// периодически и при остановке сервера
+	shutdownCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go flushConnectionStats(shutdownCtx, connectionStatsFlushInterval)
+	go func() {
+		<-shutdownCtx.Done()
+		timeoutCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(timeoutCtx)
+	}()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Fatal(err)
 	}
+	if err := config.FlushConnectionStats(); err != nil {
+		log.Printf("Ошибка сохранения статистики подключений: %v", err)
+	}
+}
+
+// connectionStatsFlushInterval - как часто счетчики запросов подключений
+// записываются в connections.json
+const connectionStatsFlushInterval = 30 * time.Second
+
+func flushConnectionStats(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := config.FlushConnectionStats(); err != nil {
+				log.Printf("Ошибка сохранения статистики подключений: %v", err)
+			}
+		}
+	}
 }
 
+// configureJWT задает ключи подписи токенов. Без ключа сервер работает со
+// встроенным, а в production-режиме не запускается
+func configureJWT(appConfig *config.AppConfig) {
+	if appConfig == nil {
+		appConfig = &config.AppConfig{}
+	}
+
+	secret, previous, grace, err := appConfig.JWTKeys()
+	if err != nil {
+		log.Fatalf("Ошибка конфигурации JWT: %v", err)
+	}
+
+	if secret == "" {
+		if appConfig.IsProduction() {
+			log.Fatal("Ключ подписи JWT не задан: укажите JWT_SECRET или jwtSecret в app.json")
+		}
+		log.Println("ВНИМАНИЕ: ключ подписи JWT не задан - используется встроенный ключ (режим разработки)")
+		return
+	}
+
+	if err := utils.SetJWTSecrets(secret, previous, grace); err != nil {
+		log.Fatalf("Ошибка конфигурации JWT: %v", err)
+	}
+	if appConfig.IsProduction() && utils.IsDefaultJWTSecret() {
+		log.Fatal("Встроенный ключ подписи JWT нельзя использовать в production-режиме")
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"database-manager/config"
+	"net/http"
+)
+
+// AdminMiddleware пропускает только пользователей с ролью admin.
+// Должен стоять после AuthMiddleware, который выставляет заголовок UserID.
+// Роль читается из конфигурации, а не из токена, чтобы ее снятие
+// действовало сразу.
+func AdminMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := config.GetUserByID(r.Header.Get("UserID"))
+		if err != nil || !user.IsAdmin() {
+			http.Error(w, "Недостаточно прав", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"database-manager/config"
+	"database-manager/models"
 	"database-manager/utils"
 	"net/http"
 	"strings"
@@ -8,7 +10,22 @@ import (
 
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// authDisabled: запросы без проверки токена идут от имени root
+		if config.GetAppConfig().AuthDisabled {
+			r.Header.Set("UserID", models.RootUserID)
+			r.Header.Set("Username", models.RootUsername)
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
+		// Браузер не позволяет задать заголовки для WebSocket и EventSource,
+		// поэтому для них токен можно передать параметром token
+		if authHeader == "" && isStreamingRequest(r) {
+			if token := r.URL.Query().Get("token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
 		if authHeader == "" {
 			http.Error(w, "Отсутствует токен авторизации", http.StatusUnauthorized)
 			return
@@ -34,3 +51,7 @@ func AuthMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func isStreamingRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
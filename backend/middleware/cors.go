@@ -1,22 +1,30 @@
 package middleware
 
 import (
+	"database-manager/config"
 	"net/http"
 	"strings"
 )
 
+// CORSMiddleware разрешает запросы из браузера с Origin из allowedOrigins
+// (app.json). Без списка отражается любой Origin вместе с
+// Allow-Credentials - это удобно при разработке, но позволяет любому сайту
+// обращаться к API от имени пользователя, поэтому в production список нужен
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			origin = "*"
+		allowOrigin, credentials := corsOrigin(r.Header.Get("Origin"), config.GetAppConfig().AllowedOrigins)
+
+		if allowOrigin != "" {
+			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, X-CSRF-Token")
+			if credentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+			w.Header().Set("Access-Control-Max-Age", "86400")
 		}
-		
-		w.Header().Set("Access-Control-Allow-Origin", origin)
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Max-Age", "86400")
+		// Ответ зависит от Origin - кэши не должны отдавать его другим сайтам
+		w.Header().Add("Vary", "Origin")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -27,35 +35,45 @@ func CORSMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func ProxyMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Обрабатываем заголовки прокси для правильного определения схемы и хоста
-		if forwardedProto := r.Header.Get("X-Forwarded-Proto"); forwardedProto != "" {
-			r.URL.Scheme = forwardedProto
-		} else if r.TLS != nil {
-			r.URL.Scheme = "https"
-		} else {
-			r.URL.Scheme = "http"
+// corsOrigin возвращает значение Access-Control-Allow-Origin и можно ли
+// разрешить credentials. Пустая строка - Origin не разрешен, заголовки CORS
+// не отправляются. "*" в списке разрешает любой Origin, но без credentials:
+// браузеры не принимают их вместе
+func corsOrigin(origin string, allowed []string) (string, bool) {
+	if len(allowed) == 0 {
+		if origin == "" {
+			return "*", true
 		}
+		return origin, true
+	}
 
-		if forwardedHost := r.Header.Get("X-Forwarded-Host"); forwardedHost != "" {
-			r.Host = forwardedHost
-			r.URL.Host = forwardedHost
-		}
+	if origin == "" {
+		return "", false
+	}
 
-		if forwardedFor := r.Header.Get("X-Forwarded-For"); forwardedFor != "" {
-			ips := strings.Split(forwardedFor, ",")
-			if len(ips) > 0 {
-				r.RemoteAddr = strings.TrimSpace(ips[0])
-			}
+	wildcard := false
+	for _, candidate := range allowed {
+		if candidate == "*" {
+			wildcard = true
+			continue
 		}
-
-		// Устанавливаем правильный Content-Length если нужно
-		if r.ContentLength == 0 && r.Method != "GET" && r.Method != "HEAD" {
-			r.ContentLength = -1
+		if strings.EqualFold(strings.TrimRight(candidate, "/"), origin) {
+			return origin, true
 		}
-
-		next.ServeHTTP(w, r)
-	})
+	}
+	if wildcard {
+		return "*", false
+	}
+	return "", false
 }
 
+// OriginAllowed сообщает, разрешен ли Origin списком allowedOrigins. Нужен
+// для WebSocket, на который CORS не действует. Запросы без Origin (не из
+// браузера) разрешены
+func OriginAllowed(origin string) bool {
+	if origin == "" {
+		return true
+	}
+	allowOrigin, _ := corsOrigin(origin, config.GetAppConfig().AllowedOrigins)
+	return allowOrigin != ""
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"database-manager/config"
+	"database-manager/utils"
+	"net/http"
+	"strings"
+)
+
+// CSRFMiddleware при включенном csrfProtection требует у изменяющих запросов
+// с токеном сессии заголовок X-CSRF-Token, выданный GET /api/auth/csrf для
+// этой сессии. Запросы без токена сессии пропускаются: их отклонит
+// AuthMiddleware, если эндпоинт требует авторизации
+func CSRFMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !config.GetAppConfig().CSRFProtection || isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := SessionToken(r)
+		if token != "" && !utils.ValidCSRFToken(token, r.Header.Get("X-CSRF-Token")) {
+			http.Error(w, "Неверный или отсутствующий CSRF-токен", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SessionToken возвращает токен сессии так же, как его читает AuthMiddleware;
+// пустая строка - токена нет или формат неверный
+func SessionToken(r *http.Request) string {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" && isStreamingRequest(r) {
+		return r.URL.Query().Get("token")
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+	return parts[1]
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ProxyMiddleware применяет заголовки X-Forwarded-* от обратного прокси.
+// Заголовки принимаются, только если запрос пришел от доверенного прокси:
+// из trusted, а при пустом списке - от любого непосредственного отправителя
+// (сервер доступен только через прокси). Клиентом считается самый правый
+// адрес X-Forwarded-For, не принадлежащий доверенным прокси: левые адреса
+// клиент может подставить сам
+func ProxyMiddleware(next http.Handler, trusted []*net.IPNet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			r.URL.Scheme = "https"
+		} else {
+			r.URL.Scheme = "http"
+		}
+
+		if isTrustedProxy(remoteIP(r.RemoteAddr), trusted, true) {
+			if proto := firstForwarded(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+				r.URL.Scheme = proto
+			}
+
+			if host := firstForwarded(r.Header.Get("X-Forwarded-Host")); validForwardedHost(host) {
+				r.Host = host
+				r.URL.Host = host
+			}
+
+			if client := forwardedClient(r.Header.Get("X-Forwarded-For"), trusted); client != "" {
+				r.RemoteAddr = client
+			}
+		}
+
+		// Устанавливаем правильный Content-Length если нужно
+		if r.ContentLength == 0 && r.Method != "GET" && r.Method != "HEAD" {
+			r.ContentLength = -1
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ParseTrustedProxies разбирает список доверенных прокси: IP или CIDR
+func ParseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return nil, fmt.Errorf("неверный адрес доверенного прокси %q", value)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("неверная сеть доверенных прокси %q: %w", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// forwardedClient проходит цепочку X-Forwarded-For справа налево, пропуская
+// доверенные прокси. Неверный адрес обрывает разбор: все левее него
+// недостоверно, и клиентом остается последний проверенный адрес
+func forwardedClient(header string, trusted []*net.IPNet) string {
+	if header == "" {
+		return ""
+	}
+
+	hops := strings.Split(header, ",")
+	client := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			break
+		}
+		client = ip.String()
+		if !isTrustedProxy(ip, trusted, false) {
+			break
+		}
+	}
+	return client
+}
+
+// isTrustedProxy проверяет адрес по списку; emptyTrusts - результат для
+// пустого списка
+func isTrustedProxy(ip net.IP, trusted []*net.IPNet, emptyTrusts bool) bool {
+	if ip == nil {
+		return false
+	}
+	if len(trusted) == 0 {
+		return emptyTrusts
+	}
+	for _, network := range trusted {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+// firstForwarded возвращает первое значение заголовка, который прокси
+// могли дополнить через запятую
+func firstForwarded(value string) string {
+	if i := strings.IndexByte(value, ','); i >= 0 {
+		value = value[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func validForwardedHost(host string) bool {
+	if host == "" || len(host) > 255 {
+		return false
+	}
+	return !strings.ContainsAny(host, "/\\@ \t?#")
+}
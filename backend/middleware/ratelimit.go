@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter ограничивает число запросов пользователя в фиксированном окне.
+// Пользователь определяется по заголовку UserID, который выставляет
+// AuthMiddleware, поэтому RateLimiter должен стоять после него.
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*rateWindow
+}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		window:  window,
+		windows: make(map[string]*rateWindow),
+	}
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("UserID")
+		if key == "" {
+			key = r.RemoteAddr
+		}
+
+		if retryAfter, ok := l.allow(key); !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+			http.Error(w, "Слишком много запросов, попробуйте позже", http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (l *RateLimiter) allow(key string) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	win, ok := l.windows[key]
+	if !ok || now.Sub(win.start) >= l.window {
+		// Заодно чистим устаревшие окна, чтобы карта не росла бесконечно
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.windows[key] = &rateWindow{start: now, count: 1}
+		return 0, true
+	}
+
+	if win.count >= l.limit {
+		return l.window - now.Sub(win.start), false
+	}
+
+	win.count++
+	return 0, true
+}
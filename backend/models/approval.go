@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type ApprovalStatus string
+
+const (
+	ApprovalPending  ApprovalStatus = "pending"  // ждет решения администратора
+	ApprovalApproved ApprovalStatus = "approved" // одобрен и выполняется
+	ApprovalExecuted ApprovalStatus = "executed"
+	ApprovalFailed   ApprovalStatus = "failed" // одобрен, но выполнился с ошибкой
+	ApprovalRejected ApprovalStatus = "rejected"
+)
+
+// PendingQuery - запрос на изменение к подключению с requireApproval,
+// отправленный не администратором и ожидающий подтверждения
+type PendingQuery struct {
+	ID           string         `json:"id"`
+	ConnectionID string         `json:"connectionId"`
+	Query        string         `json:"query"`
+	Params       QueryParams    `json:"params"`
+	Username     string         `json:"username"`
+	Status       ApprovalStatus `json:"status"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	ReviewedBy   string         `json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time     `json:"reviewedAt,omitempty"`
+	RowsAffected int64          `json:"rowsAffected,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
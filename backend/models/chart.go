@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// ChartTypes - поддерживаемые типы графиков; отрисовка на стороне клиента
+var ChartTypes = map[string]bool{
+	"line":    true,
+	"bar":     true,
+	"area":    true,
+	"pie":     true,
+	"scatter": true,
+}
+
+// Chart - сохраненная визуализация результата запроса. Сервер только
+// хранит описание графика, строит его клиент
+type Chart struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"userId"`
+	Name         string    `json:"name,omitempty"`
+	QueryID      string    `json:"queryId"`
+	ConnectionID string    `json:"connectionId,omitempty"`
+	Query        string    `json:"query,omitempty"`
+	ChartType    string    `json:"chartType"`
+	XColumn      string    `json:"xColumn"`
+	YColumns     []string  `json:"yColumns"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ChartRequest - тело создания и изменения графика. connectionId и query
+// необязательны: их можно сохранить, чтобы перестроить график без
+// сохраненного запроса
+type ChartRequest struct {
+	Name         string   `json:"name"`
+	QueryID      string   `json:"queryId"`
+	ConnectionID string   `json:"connectionId"`
+	Query        string   `json:"query"`
+	ChartType    string   `json:"chartType"`
+	XColumn      string   `json:"xColumn"`
+	YColumns     []string `json:"yColumns"`
+}
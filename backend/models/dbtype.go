@@ -0,0 +1,71 @@
+package models
+
+// DatabaseTypeDescriptor - свойства типа БД, не зависящие от драйвера:
+// стандартный порт для формы подключения, подставляет ли драйвер порт сам и
+// поддерживается ли флаг ssl. Возможности драйвера (язык запросов, таблицы,
+// пользователи) описывает DriverCapabilities
+type DatabaseTypeDescriptor struct {
+	DefaultPort  string // стандартный порт сервера; пусто - порт не используется
+	PortOptional bool   // драйвер подставляет порт сам, если он не указан
+	SupportsSSL  bool
+}
+
+// databaseTypeDescriptors - описания всех типов из SupportedDatabaseTypes.
+// У CockroachDB порт по умолчанию 26257, но драйвер PostgreSQL без порта
+// подключается к 5432
+var databaseTypeDescriptors = map[DatabaseType]DatabaseTypeDescriptor{
+	PostgreSQL:    {DefaultPort: "5432", PortOptional: true, SupportsSSL: true},
+	MongoDB:       {DefaultPort: "27017", PortOptional: true, SupportsSSL: true},
+	Elasticsearch: {DefaultPort: "9200", SupportsSSL: true},
+	Meilisearch:   {DefaultPort: "7700", SupportsSSL: true},
+	ClickHouse:    {DefaultPort: "8123", SupportsSSL: true},
+	Cassandra:     {DefaultPort: "9042", PortOptional: true},
+	Aerospike:     {DefaultPort: "3000", PortOptional: true},
+	Redis:         {DefaultPort: "6379", SupportsSSL: true},
+	InfluxDB:      {DefaultPort: "8086", SupportsSSL: true},
+	Neo4j:         {DefaultPort: neo4jBoltPort, SupportsSSL: true},
+	Couchbase:     {DefaultPort: "8091", SupportsSSL: true},
+	Supabase:      {DefaultPort: "5432", PortOptional: true, SupportsSSL: true},
+	Druid:         {DefaultPort: "8888", SupportsSSL: true},
+	CockroachDB:   {DefaultPort: "26257", PortOptional: true, SupportsSSL: true},
+	Kafka:         {DefaultPort: "9092", SupportsSSL: true},
+	RabbitMQ:      {DefaultPort: "15672", SupportsSSL: true},
+	Zookeeper:     {DefaultPort: "2181"},
+	Oracle:        {DefaultPort: "1521", PortOptional: true, SupportsSSL: true},
+	SQLite:        {},
+	Prometheus:    {DefaultPort: "9090", PortOptional: true, SupportsSSL: true},
+}
+
+// Descriptor возвращает описание типа; для неподдерживаемого типа - пустое
+func (t DatabaseType) Descriptor() DatabaseTypeDescriptor {
+	return databaseTypeDescriptors[t]
+}
+
+// RequiredFields - поля подключения, без которых Validate вернет ошибку
+// (кроме name и type). Для типов с DSN host и port можно заменить полем dsn
+func (t DatabaseType) RequiredFields() []string {
+	if t.IsFileBased() {
+		return []string{"database"}
+	}
+	fields := []string{"host"}
+	if !t.Descriptor().PortOptional {
+		fields = append(fields, "port")
+	}
+	if t == InfluxDB {
+		fields = append(fields, "database")
+	}
+	return fields
+}
+
+// DatabaseTypeInfo - описание типа БД для клиента (GET /api/database-types)
+type DatabaseTypeInfo struct {
+	Type           DatabaseType `json:"type"`
+	DefaultPort    string       `json:"defaultPort"`
+	RequiredFields []string     `json:"requiredFields"`
+	QueryLanguage  string       `json:"queryLanguage"` // как в DriverCapabilities; пусто - запросы не поддерживаются
+	FileBased      bool         `json:"fileBased"`
+	UsesDSN        bool         `json:"usesDsn"`
+	SupportsSSL    bool         `json:"supportsSsl"`
+	SupportsUsers  bool         `json:"supportsUsers"`
+	SupportsTables bool         `json:"supportsTables"` // создание и удаление таблиц (коллекций, индексов)
+}
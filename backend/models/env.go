@@ -0,0 +1,84 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// envRefPattern - ссылка на переменную окружения в значении поля: ${NAME}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// HasEnvRef сообщает, содержит ли значение ссылку ${NAME}
+func HasEnvRef(value string) bool {
+	return envRefPattern.MatchString(value)
+}
+
+// ResolveEnv возвращает копию подключения, в которой ссылки ${NAME} в полях
+// host, port, database, username, password, dsn, restUrl, caCert, clientCert
+// и clientKey заменены значениями переменных окружения. Исходное подключение
+// не меняется, поэтому в connections.json остаются ссылки. Подставляются
+// только переменные с префиксом prefix; пустой prefix запрещает подстановку:
+// иначе любой пользователь прочитал бы секреты сервера (JWT_SECRET и т.п.),
+// указав их в пароле подключения к своему хосту. Незаданная или запрещенная
+// переменная - ошибка: подключиться с пустым паролем хуже, чем не
+// подключиться
+func (c Connection) ResolveEnv(prefix string) (Connection, error) {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"host", &c.Host},
+		{"port", &c.Port},
+		{"database", &c.Database},
+		{"username", &c.Username},
+		{"password", &c.Password},
+		{"dsn", &c.DSN},
+		{"restUrl", &c.RestURL},
+		{"caCert", &c.CACert},
+		{"clientCert", &c.ClientCert},
+		{"clientKey", &c.ClientKey},
+	}
+
+	for _, field := range fields {
+		resolved, err := expandEnvRefs(*field.value, prefix)
+		if err != nil {
+			return c, fmt.Errorf("поле %s: %w", field.name, err)
+		}
+		*field.value = resolved
+	}
+	return c, nil
+}
+
+func expandEnvRefs(value, prefix string) (string, error) {
+	if !HasEnvRef(value) {
+		return value, nil
+	}
+
+	var resolveErr error
+	resolved := envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envRefPattern.FindStringSubmatch(ref)[1]
+		if resolveErr != nil {
+			return ref
+		}
+		if prefix == "" {
+			resolveErr = fmt.Errorf("подстановка переменной окружения %s запрещена: не задан connectionEnvPrefix", name)
+			return ref
+		}
+		if !strings.HasPrefix(name, prefix) {
+			resolveErr = fmt.Errorf("переменная окружения %s не начинается с разрешенного префикса %q", name, prefix)
+			return ref
+		}
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			resolveErr = fmt.Errorf("переменная окружения %s не задана", name)
+			return ref
+		}
+		return envValue
+	})
+	if resolveErr != nil {
+		return "", resolveErr
+	}
+	return resolved, nil
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveEnvPrefix(t *testing.T) {
+	os.Setenv("JWT_SECRET", "server-secret")
+	os.Setenv("DBM_PASSWORD", "db-secret")
+	defer os.Unsetenv("JWT_SECRET")
+	defer os.Unsetenv("DBM_PASSWORD")
+
+	tests := []struct {
+		password string
+		prefix   string
+		want     string
+		wantErr  bool
+	}{
+		{"${JWT_SECRET}", "", "", true},
+		{"${DBM_PASSWORD}", "", "", true},
+		{"${JWT_SECRET}", "DBM_", "", true},
+		{"${DBM_PASSWORD}", "DBM_", "db-secret", false},
+		{"plain", "", "plain", false},
+	}
+
+	for _, tt := range tests {
+		conn, err := Connection{Password: tt.password}.ResolveEnv(tt.prefix)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolveEnv(%q, prefix %q) error = %v, wantErr %v", tt.password, tt.prefix, err, tt.wantErr)
+			continue
+		}
+		if err == nil && conn.Password != tt.want {
+			t.Errorf("ResolveEnv(%q, prefix %q) = %q, want %q", tt.password, tt.prefix, conn.Password, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Profile - окружение (dev/staging/prod), сопоставляющее логические имена
+// подключений с конкретными подключениями
+type Profile struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Connections map[string]string `json:"connections"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+}
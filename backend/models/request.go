@@ -1,5 +1,12 @@
 package models
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -16,17 +23,217 @@ type LoginResponse struct {
 	User  User   `json:"user"`
 }
 
+// CSRFTokenResponse - токен для заголовка X-CSRF-Token. Enabled - требует
+// ли сервер этот заголовок (csrfProtection в app.json)
+type CSRFTokenResponse struct {
+	Token   string `json:"token"`
+	Enabled bool   `json:"enabled"`
+}
+
 type QueryRequest struct {
-	ConnectionID string `json:"connectionId"`
-	Query        string `json:"query"`
+	ConnectionID string            `json:"connectionId"`
+	Query        string            `json:"query"`
+	Params       QueryParams       `json:"params"`
+	PageSize     int               `json:"pageSize,omitempty"`    // Cassandra: размер страницы результата
+	PageState    string            `json:"pageState,omitempty"`   // Cassandra: nextPageState из предыдущего ответа
+	ResultMode   string            `json:"resultMode,omitempty"`  // Neo4j: "graph" - вернуть узлы и связи в graph вместе со строками
+	SessionVars  map[string]string `json:"sessionVars,omitempty"` // PostgreSQL: переменные сессии только для этого запроса (SET LOCAL)
+}
+
+// BatchQueryRequest - набор независимых запросов к одному подключению
+// для POST /api/query/batch
+type BatchQueryRequest struct {
+	ConnectionID string       `json:"connectionId"`
+	Queries      []BatchQuery `json:"queries"`
+	Concurrency  int          `json:"concurrency,omitempty"` // сколько запросов выполнять одновременно; по умолчанию 1 - по очереди
+}
+
+// BatchQuery - один запрос пакета
+type BatchQuery struct {
+	Query  string      `json:"query"`
+	Params QueryParams `json:"params"`
+}
+
+// QueryDiffRequest - запрос на сравнение результатов двух запросов по
+// ключевому столбцу. Пустые connectionId и query справа берутся слева:
+// так один запрос сравнивается на двух подключениях
+type QueryDiffRequest struct {
+	Left  QueryDiffSide `json:"left"`
+	Right QueryDiffSide `json:"right"`
+	Key   string        `json:"key"`
+}
+
+// QueryDiffSide - одна сторона сравнения
+type QueryDiffSide struct {
+	ConnectionID string      `json:"connectionId"`
+	Query        string      `json:"query"`
+	Params       QueryParams `json:"params"`
+}
+
+// QueryDiffResponse - построчные различия: Added - строки только справа,
+// Removed - только слева, Changed - строки с одинаковым ключом и разными
+// значениями общих столбцов
+type QueryDiffResponse struct {
+	Key              string                   `json:"key"`
+	Columns          []string                 `json:"columns"`                    // общие столбцы, по которым сравниваются строки
+	LeftOnlyColumns  []string                 `json:"leftOnlyColumns,omitempty"`  // столбцы только в левом результате
+	RightOnlyColumns []string                 `json:"rightOnlyColumns,omitempty"` // столбцы только в правом результате
+	Added            []map[string]interface{} `json:"added"`
+	Removed          []map[string]interface{} `json:"removed"`
+	Changed          []RowChange              `json:"changed"`
+	UnchangedCount   int                      `json:"unchangedCount"`
+	LeftRowCount     int                      `json:"leftRowCount"`
+	RightRowCount    int                      `json:"rightRowCount"`
+	Truncated        bool                     `json:"truncated,omitempty"` // один из результатов обрезан по maxRows, различия неполные
+}
+
+// RowChange - строка, измененная между результатами; Columns - столбцы
+// с разными значениями
+type RowChange struct {
+	Key     interface{}            `json:"key"`
+	Columns []string               `json:"columns"`
+	Left    map[string]interface{} `json:"left"`
+	Right   map[string]interface{} `json:"right"`
+}
+
+// SchemaDiffResponse - различия схем двух подключений: таблицы только
+// в одной из баз и различия столбцов общих таблиц
+type SchemaDiffResponse struct {
+	SourceID           string            `json:"sourceId"`
+	TargetID           string            `json:"targetId"`
+	TablesOnlyInSource []string          `json:"tablesOnlyInSource"`
+	TablesOnlyInTarget []string          `json:"tablesOnlyInTarget"`
+	Tables             []TableSchemaDiff `json:"tables"`          // общие таблицы с различиями
+	IdenticalTables    int               `json:"identicalTables"` // общие таблицы без различий
+}
+
+// TableSchemaDiff - различия столбцов одной таблицы
+type TableSchemaDiff struct {
+	Name                string           `json:"name"`
+	ColumnsOnlyInSource []TableColumn    `json:"columnsOnlyInSource"`
+	ColumnsOnlyInTarget []TableColumn    `json:"columnsOnlyInTarget"`
+	Mismatches          []ColumnMismatch `json:"mismatches"`
+}
+
+// ColumnMismatch - столбец, который есть в обеих базах, но отличается типом
+// или допустимостью NULL
+type ColumnMismatch struct {
+	Column         string `json:"column"`
+	SourceType     string `json:"sourceType"`
+	TargetType     string `json:"targetType"`
+	SourceNullable bool   `json:"sourceNullable"`
+	TargetNullable bool   `json:"targetNullable"`
+}
+
+// ResultModeGraph - режим ответа с графом: кроме строк возвращаются узлы и
+// связи результата
+const ResultModeGraph = "graph"
+
+// QueryParams - параметры запроса: объект {"name": value} для плейсхолдеров
+// :name или массив [v1, v2] для позиционных $1, $2. Числа сохраняются как
+// json.Number, чтобы драйвер сам выбрал целый или дробный тип
+type QueryParams struct {
+	Named      map[string]interface{}
+	Positional []interface{}
+}
+
+// MarshalJSON записывает параметры в том же виде, в каком они приходят в
+// запросе, чтобы сохраненные запросы (approvals.json) читались обратно
+func (p QueryParams) MarshalJSON() ([]byte, error) {
+	if p.Positional != nil {
+		return json.Marshal(p.Positional)
+	}
+	if p.Named != nil {
+		return json.Marshal(p.Named)
+	}
+	return []byte("null"), nil
+}
+
+func (p *QueryParams) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	switch v := raw.(type) {
+	case nil:
+	case map[string]interface{}:
+		p.Named = v
+	case []interface{}:
+		p.Positional = v
+	default:
+		return fmt.Errorf("params должен быть объектом или массивом")
+	}
+	return nil
+}
+
+type TransactionRequest struct {
+	ConnectionID string   `json:"connectionId"`
+	Commands     []string `json:"commands"`
+}
+
+// FilterCondition - условие упрощенного фильтра. Op: eq, ne, gt, gte, lt,
+// lte, in (value - массив), exists (value - bool, по умолчанию true)
+type FilterCondition struct {
+	Field string      `json:"field"`
+	Op    string      `json:"op"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// QueryFilter - условия и вложенные группы, объединенные через and (по умолчанию) или or
+type QueryFilter struct {
+	Logic      string            `json:"logic,omitempty"`
+	Conditions []FilterCondition `json:"conditions,omitempty"`
+	Groups     []QueryFilter     `json:"groups,omitempty"`
+}
+
+// CompileFilterRequest - запрос на сборку нативного запроса из фильтра
+type CompileFilterRequest struct {
+	ConnectionID string      `json:"connectionId"`
+	Filter       QueryFilter `json:"filter"`
+}
+
+// TerminalRequest - сообщение клиента в WebSocket-сессии /api/terminal
+type TerminalRequest struct {
+	Query string `json:"query"`
 }
 
 type QueryResponse struct {
 	Columns      []string                 `json:"columns"`
 	Rows         []map[string]interface{} `json:"rows"`
 	RowCount     int                      `json:"rowCount"`
+	RowsAffected int64                    `json:"rowsAffected"`
 	ExecutionTime int64                   `json:"executionTime"`
 	Error        string                   `json:"error,omitempty"`
+	Cached       bool                     `json:"cached,omitempty"` // результат взят из кэша, executionTime - время исходного выполнения
+	NextPageState string                  `json:"nextPageState,omitempty"` // токен следующей страницы; пусто - страниц больше нет
+	Truncated     bool                    `json:"truncated,omitempty"`     // результат обрезан по пределу maxRows
+	Graph         *GraphResult            `json:"graph,omitempty"`         // узлы и связи при resultMode "graph"; nil - в результате нет графовых значений
+}
+
+// GraphResult - узлы и связи результата без повторов, для отрисовки графа
+type GraphResult struct {
+	Nodes         []GraphNode         `json:"nodes"`
+	Relationships []GraphRelationship `json:"relationships"`
+}
+
+// GraphNode - узел графа; ID - elementId (в Neo4j до 5.x - числовой id строкой)
+type GraphNode struct {
+	ID         string                 `json:"id"`
+	Labels     []string               `json:"labels"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GraphRelationship - связь графа; StartNode и EndNode - ID узлов концов
+type GraphRelationship struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	StartNode  string                 `json:"startNode"`
+	EndNode    string                 `json:"endNode"`
+	Properties map[string]interface{} `json:"properties"`
 }
 
 type CreateDatabaseRequest struct {
@@ -51,6 +258,17 @@ type CreateTableRequest struct {
 	ConnectionID string                 `json:"connectionId"`
 	Name         string                 `json:"name"`
 	Columns      []TableColumn          `json:"columns"`
+	TableConstraints
+}
+
+// TableConstraints - ограничения уровня таблицы
+type TableConstraints struct {
+	PrimaryKey  []string `json:"primaryKey,omitempty"`  // составной первичный ключ (в Cassandra первый столбец - ключ партиции)
+	Constraints []string `json:"constraints,omitempty"` // выражения CHECK, например "price > 0"
+}
+
+func (c TableConstraints) IsEmpty() bool {
+	return len(c.PrimaryKey) == 0 && len(c.Constraints) == 0
 }
 
 type UpdateTableRequest struct {
@@ -60,14 +278,45 @@ type UpdateTableRequest struct {
 	Columns      []TableColumn `json:"columns"`
 }
 
+type TruncateTableRequest struct {
+	ConnectionID string `json:"connectionId"`
+	Name         string `json:"name"`
+}
+
+type BulkDeleteTablesRequest struct {
+	ConnectionID string   `json:"connectionId"`
+	Names        []string `json:"names"`
+	Cascade      bool     `json:"cascade"`
+	Confirm      bool     `json:"confirm"`
+}
+
+type TableOperationResult struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
 type TableColumn struct {
 	Name       string `json:"name"`
 	Type       string `json:"type"`
 	Nullable   bool   `json:"nullable"`
 	PrimaryKey bool   `json:"primaryKey"`
 	Unique     bool   `json:"unique"`
+	Kind       string `json:"kind,omitempty"` // Cassandra: partition_key, clustering, static, regular
+	Operation  string `json:"operation,omitempty"` // UpdateTable: add (по умолчанию), drop, alter, rename
+	NewName    string `json:"newName,omitempty"`   // UpdateTable: новое имя для operation = rename
+	Default    string `json:"default,omitempty"`    // SQL-выражение значения по умолчанию, например now() или 'new'
+	References string `json:"references,omitempty"` // Внешний ключ: table(column) или schema.table(column)
 }
 
+// Операции над столбцами в UpdateTable
+const (
+	ColumnAdd    = "add"
+	ColumnDrop   = "drop"
+	ColumnAlter  = "alter"
+	ColumnRename = "rename"
+)
+
 type TableInfo struct {
 	Name     string        `json:"name"`
 	Database string        `json:"database,omitempty"`
@@ -106,15 +355,274 @@ type UserInfo struct {
 	IsSuperuser bool    `json:"isSuperuser,omitempty"`
 }
 
+// UserFilter - поиск по имени и пагинация списка пользователей БД;
+// Limit 0 - без ограничения
+type UserFilter struct {
+	Search string
+	Limit  int
+	Offset int
+}
+
+// UserList - страница пользователей и их общее число с учетом поиска
+type UserList struct {
+	Users []UserInfo `json:"users"`
+	Total int        `json:"total"`
+}
+
+const (
+	GrantKindRole      = "role"
+	GrantKindPrivilege = "privilege"
+)
+
+// GrantableRole - значение, которое можно передать в permissions при
+// создании и изменении пользователя БД: роль или привилегия
+type GrantableRole struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`              // role или privilege
+	BuiltIn bool   `json:"builtIn,omitempty"` // встроенная роль сервера
+}
+
 type DatabaseInfo struct {
 	Name      string `json:"name"`
 	Owner     string `json:"owner,omitempty"`
 	Size      string `json:"size,omitempty"`
 	Encoding  string `json:"encoding,omitempty"`
 	Collation string `json:"collation,omitempty"`
+	// ObjectCount - число таблиц (коллекций, ключей); nil, если драйвер
+	// не может посчитать его дешево
+	ObjectCount *int64 `json:"objectCount,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+
+type RotatePasswordRequest struct {
+	ConnectionID string `json:"connectionId"`
+	Username     string `json:"username"`
+	Password     string `json:"password,omitempty"`
+}
+
+type ServerSetting struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Default     string `json:"default,omitempty"`
+	Unit        string `json:"unit,omitempty"`
+	Description string `json:"description,omitempty"`
+	Changeable  bool   `json:"changeable"`
+}
+
+type UpdateSettingRequest struct {
+	ConnectionID string `json:"connectionId"`
+	Name         string `json:"name"`
+	Value        string `json:"value"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+type UpdateIndexSettingsRequest struct {
+	ConnectionID string                 `json:"connectionId"`
+	Index        string                 `json:"index"`
+	Settings     map[string]interface{} `json:"settings"`
+}
+
+// IndexInfo - индекс таблицы. Для ClickHouse это индекс пропуска данных
+// (skip index): Type - его тип, Granularity - гранулярность
+type IndexInfo struct {
+	Name        string   `json:"name"`
+	Table       string   `json:"table"`
+	Columns     []string `json:"columns"`
+	Unique      bool     `json:"unique"`
+	Type        string   `json:"type,omitempty"`
+	Granularity int64    `json:"granularity,omitempty"`
+	Definition  string   `json:"definition,omitempty"`
+}
+
+// CreateIndexRequest описывает индекс: для PostgreSQL Type - метод доступа
+// (btree, hash, gin...), для ClickHouse - тип skip-индекса (minmax, set(100),
+// bloom_filter...)
+type CreateIndexRequest struct {
+	ConnectionID string   `json:"connectionId"`
+	Table        string   `json:"table"`
+	Name         string   `json:"name"`
+	Columns      []string `json:"columns"`
+	Unique       bool     `json:"unique"`
+	Type         string   `json:"type,omitempty"`
+	Granularity  int64    `json:"granularity,omitempty"`
+}
+
+// AsyncTask - задача, поставленная в очередь движком (Meilisearch);
+// по TaskUID клиент может отслеживать её выполнение
+type AsyncTask struct {
+	TaskUID  int64  `json:"taskUid"`
+	IndexUID string `json:"indexUid,omitempty"`
+	Status   string `json:"status"`
+	Type     string `json:"type"`
+}
+
+type WriteRowsRequest struct {
+	ConnectionID string                   `json:"connectionId"`
+	Table        string                   `json:"table"`
+	Rows         []map[string]interface{} `json:"rows"`
+	Lines        string                   `json:"lines,omitempty"`     // InfluxDB: точки в line protocol вместо rows
+	Precision    string                   `json:"precision,omitempty"` // InfluxDB: единица времени точек - ns (по умолчанию), us, ms, s
+	Database     string                   `json:"database,omitempty"`  // InfluxDB: база (1.x, по умолчанию база подключения) или bucket (2.x)
+}
+
+// DeleteRowsRequest - удаление строк по фильтру. Filter: условие WHERE
+// с параметрами $1, $2... из args (SQL), фильтр MongoDB в JSON или шаблон
+// ключей Redis. Без filter удаление всех строк требует all: true
+type DeleteRowsRequest struct {
+	ConnectionID string        `json:"connectionId"`
+	Table        string        `json:"table"`
+	Filter       string        `json:"filter"`
+	Args         []interface{} `json:"args,omitempty"`
+	All          bool          `json:"all,omitempty"`
+}
+
+// WriteResult - результат записи строк. Для движков с асинхронной
+// индексацией запись ещё не применена, и вместо числа строк возвращается Task
+type WriteResult struct {
+	RowsAffected int64      `json:"rowsAffected"`
+	Task         *AsyncTask `json:"task,omitempty"`
+}
+
+// Статусы асинхронной задачи (совпадают со статусами задач Meilisearch)
+const (
+	TaskEnqueued   = "enqueued"
+	TaskProcessing = "processing"
+	TaskSucceeded  = "succeeded"
+	TaskFailed     = "failed"
+	TaskCanceled   = "canceled"
+)
+
+type TaskStatus struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Completed   bool   `json:"completed"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Total       int64  `json:"total,omitempty"`
+	Processed   int64  `json:"processed,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type UseDatabaseRequest struct {
+	Database string `json:"database"`
+	Persist  bool   `json:"persist,omitempty"`
+}
+
+type ProfileRequest struct {
+	Name        string            `json:"name"`
+	Connections map[string]string `json:"connections"`
+}
+
+type SetActiveProfileRequest struct {
+	ProfileID string `json:"profileId"`
+}
+
+type ResolvedConnection struct {
+	Name         string `json:"name"`
+	ProfileID    string `json:"profileId"`
+	ProfileName  string `json:"profileName"`
+	ConnectionID string `json:"connectionId"`
+	Connected    bool   `json:"connected"`
+}
+
+type Notification struct {
+	Channel    string    `json:"channel"`
+	Payload    string    `json:"payload"`
+	PID        uint32    `json:"pid"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+type EventType string
+
+const (
+	EventQuery      EventType = "query"
+	EventConnect    EventType = "connect"
+	EventDisconnect EventType = "disconnect"
+	EventDDL        EventType = "ddl"
+)
+
+type Event struct {
+	Type         EventType `json:"type"`
+	Timestamp    time.Time `json:"timestamp"`
+	Username     string    `json:"username"`
+	ConnectionID string    `json:"connectionId"`
+	Details      string    `json:"details,omitempty"`
+}
+
+// ErrorRecord - ошибка драйвера из журнала последних ошибок
+type ErrorRecord struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Username     string    `json:"username,omitempty"`
+	ConnectionID string    `json:"connectionId,omitempty"`
+	Operation    string    `json:"operation"`
+	Message      string    `json:"message"`
+}
+
+// SlowQuery - запрос из журнала медленных запросов
+type SlowQuery struct {
+	Timestamp    time.Time `json:"timestamp"`
+	Username     string    `json:"username,omitempty"`
+	ConnectionID string    `json:"connectionId"`
+	Query        string    `json:"query"`
+	Duration     int64     `json:"duration"` // мс
+}
+
+type QueryActivity struct {
+	PID        int        `json:"pid"`
+	Username   string     `json:"username"`
+	Database   string     `json:"database"`
+	State      string     `json:"state"`
+	Query      string     `json:"query"`
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	DurationMs int64      `json:"durationMs"`
+}
+
+type KillQueryRequest struct {
+	ConnectionID string `json:"connectionId"`
+	PID          int    `json:"pid"`
+}
+
+type ClusterHealth struct {
+	ClusterName         string          `json:"clusterName"`
+	Status              string          `json:"status"`
+	NumberOfNodes       int             `json:"numberOfNodes"`
+	NumberOfDataNodes   int             `json:"numberOfDataNodes"`
+	ActivePrimaryShards int             `json:"activePrimaryShards"`
+	ActiveShards        int             `json:"activeShards"`
+	RelocatingShards    int             `json:"relocatingShards"`
+	InitializingShards  int             `json:"initializingShards"`
+	UnassignedShards    int             `json:"unassignedShards"`
+	Nodes               []NodeDiskUsage `json:"nodes"`
+}
+
+type CockroachClusterInfo struct {
+	ClusterID   string          `json:"clusterId"`
+	ClusterName string          `json:"clusterName"`
+	Version     string          `json:"version"`
+	Nodes       []CockroachNode `json:"nodes"`
+	Ranges      *QueryResponse  `json:"ranges,omitempty"`
+}
+
+type CockroachNode struct {
+	NodeID    int64     `json:"nodeId"`
+	Address   string    `json:"address"`
+	Locality  string    `json:"locality,omitempty"`
+	BuildTag  string    `json:"buildTag"`
+	StartedAt time.Time `json:"startedAt"`
+	IsLive    bool      `json:"isLive"`
+	Ranges    int64     `json:"ranges"`
+	Leases    int64     `json:"leases"`
+}
+
+type NodeDiskUsage struct {
+	ID              string  `json:"id"`
+	Name            string  `json:"name"`
+	Host            string  `json:"host,omitempty"`
+	DiskTotalBytes  int64   `json:"diskTotalBytes"`
+	DiskFreeBytes   int64   `json:"diskFreeBytes"`
+	DiskUsedPercent float64 `json:"diskUsedPercent"`
+}
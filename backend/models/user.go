@@ -3,10 +3,34 @@ package models
 import "time"
 
 type User struct {
-	ID           string    `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"-"` // Не возвращаем в JSON
-	Email        string    `json:"email,omitempty"`
-	CreatedAt    time.Time `json:"createdAt"`
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	PasswordHash  string    `json:"-"` // Не возвращаем в JSON
+	Email         string    `json:"email,omitempty"`
+	Role          string    `json:"role,omitempty"`
+	ActiveProfile string    `json:"activeProfile,omitempty"`
+	AuthSource    string    `json:"authSource,omitempty"` // откуда пользователь: пусто - локальный, ldap/oidc - создан при входе через LDAP/OIDC
+	OIDCIssuer    string    `json:"oidcIssuer,omitempty"` // issuer и sub учетной записи провайдера, с которой связан OIDC-пользователь
+	OIDCSubject   string    `json:"oidcSubject,omitempty"`
+	CreatedAt     time.Time `json:"createdAt"`
 }
 
+// RoleAdmin - роль с доступом к административным эндпоинтам (/api/admin/*)
+const RoleAdmin = "admin"
+
+// RootUserID и RootUsername - пользователь root, которого сервер создает
+// при первом запуске; от его имени работают запросы при authDisabled
+const (
+	RootUserID   = "00000000-0000-0000-0000-000000000001"
+	RootUsername = "root"
+)
+
+// AuthSourceLDAP - пользователь создан при первом входе через LDAP
+const AuthSourceLDAP = "ldap"
+
+// AuthSourceOIDC - пользователь создан при первом входе через OIDC
+const AuthSourceOIDC = "oidc"
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
@@ -0,0 +1,180 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FieldError - ошибка в одном поле подключения; Field - имя поля в JSON
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse - тело ответа 400 при неверных параметрах
+// подключения: Error - сводка для показа целиком, Fields - по полям для
+// подсветки в форме
+type ValidationErrorResponse struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields"`
+}
+
+// FieldErrorsSummary собирает ошибки полей в одну строку
+func FieldErrorsSummary(fields []FieldError) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = fmt.Sprintf("%s: %s", f.Field, f.Message)
+	}
+	return "Неверные параметры подключения: " + strings.Join(parts, "; ")
+}
+
+// UsesDSN - драйвер принимает строку подключения вместо host/port
+func (t DatabaseType) UsesDSN() bool {
+	switch t {
+	case PostgreSQL, CockroachDB, Supabase, MongoDB, Oracle, SQLite, Prometheus:
+		return true
+	}
+	return false
+}
+
+// Протоколы Neo4j: Bolt - бинарный протокол официального драйвера,
+// HTTP - транзакционный HTTP API
+const (
+	Neo4jProtocolBolt = "bolt"
+	Neo4jProtocolHTTP = "http"
+)
+
+// neo4jBoltPort - стандартный порт Bolt
+const neo4jBoltPort = "7687"
+
+// Neo4jProtocol возвращает протокол подключения к Neo4j: явно заданный или,
+// если поле пусто, Bolt для порта 7687 и HTTP для остальных
+func (c *Connection) Neo4jProtocol() string {
+	if c.Protocol != "" {
+		return c.Protocol
+	}
+	if c.Port == neo4jBoltPort {
+		return Neo4jProtocolBolt
+	}
+	return Neo4jProtocolHTTP
+}
+
+// redisMaxDB - номера баз Redis по умолчанию (databases 16 в redis.conf)
+const redisMaxDB = 15
+
+// Validate проверяет параметры подключения с учетом типа БД и возвращает
+// ошибки по полям; nil - параметры верны. Доступность сервера не проверяется,
+// это делает Connect. Пароль здесь не проверяется: при обновлении пустой
+// пароль означает "не менять". Поля со ссылками ${NAME} раскрываются только
+// при подключении, поэтому их формат здесь не проверяется
+func (c *Connection) Validate() []FieldError {
+	var errs []FieldError
+	add := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if strings.TrimSpace(c.Name) == "" {
+		add("name", "название подключения обязательно")
+	}
+	if !c.Type.IsSupported() {
+		add("type", "неподдерживаемый тип БД %q, допустимые типы: %s", c.Type, SupportedDatabaseTypesString())
+		return errs
+	}
+
+	viaDSN := c.DSN != "" && c.Type.UsesDSN()
+	viaREST := c.Type == Supabase && c.RestURL != ""
+
+	switch {
+	case c.Type.IsFileBased():
+		if c.Database == "" && c.DSN == "" {
+			add("database", "укажите путь к файлу базы данных")
+		}
+	case viaDSN || viaREST:
+		// Адрес сервера берется из DSN или restUrl
+	default:
+		if strings.TrimSpace(c.Host) == "" {
+			add("host", "хост обязателен для %s", c.Type)
+		} else if strings.Contains(c.Host, "://") {
+			add("host", "укажите только имя хоста без схемы: схему задает флаг ssl")
+		}
+		if c.Port == "" {
+			if !c.Type.Descriptor().PortOptional {
+				add("port", "порт обязателен для %s", c.Type)
+			}
+		}
+	}
+
+	if c.Port != "" && !c.Type.IsFileBased() && !HasEnvRef(c.Port) {
+		if port, err := strconv.Atoi(c.Port); err != nil {
+			add("port", "порт должен быть числом, получено %q", c.Port)
+		} else if port < 1 || port > 65535 {
+			add("port", "порт должен быть в диапазоне 1-65535, получено %d", port)
+		}
+	}
+
+	if c.DSN != "" && !c.Type.UsesDSN() {
+		add("dsn", "%s не поддерживает строку подключения, укажите host и port", c.Type)
+	}
+
+	if c.RestURL != "" {
+		if c.Type != Supabase {
+			add("restUrl", "restUrl используется только для Supabase")
+		} else if !HasEnvRef(c.RestURL) {
+			if u, err := url.Parse(c.RestURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				add("restUrl", "ожидается URL проекта вида https://<project>.supabase.co")
+			}
+		}
+	}
+
+	if c.Protocol != "" {
+		if c.Type != Neo4j {
+			add("protocol", "protocol используется только для Neo4j")
+		} else if c.Protocol != Neo4jProtocolBolt && c.Protocol != Neo4jProtocolHTTP {
+			add("protocol", "ожидается %q или %q, получено %q", Neo4jProtocolBolt, Neo4jProtocolHTTP, c.Protocol)
+		}
+	}
+
+	switch c.Type {
+	case Redis:
+		if c.Database != "" && !HasEnvRef(c.Database) {
+			if db, err := strconv.Atoi(c.Database); err != nil || db < 0 || db > redisMaxDB {
+				add("database", "номер базы Redis должен быть числом от 0 до %d, получено %q", redisMaxDB, c.Database)
+			}
+		}
+	case InfluxDB:
+		// Версия сервера определяется при подключении: в 1.x поле - база
+		// для InfluxQL, в 2.x - организация, в которой ищутся bucket'ы
+		if c.Database == "" {
+			add("database", "укажите базу (InfluxDB 1.x) или организацию (InfluxDB 2.x)")
+		}
+	}
+
+	if _, err := c.CacheDuration(); err != nil {
+		add("cacheTtl", "%v", err)
+	}
+	if _, err := c.HTTPTimeoutDuration(); err != nil {
+		add("httpTimeout", "%v", err)
+	}
+	if _, err := c.CACertPool(); err != nil && !HasEnvRef(c.CACert) {
+		add("caCert", "%v", err)
+	}
+	if _, err := c.ClientCertificate(); err != nil && !HasEnvRef(c.ClientCert) && !HasEnvRef(c.ClientKey) {
+		add("clientCert", "%v", err)
+	}
+	if c.MaxConcurrentQueries < 0 {
+		add("maxConcurrentQueries", "не может быть отрицательным")
+	}
+	if c.MaxRows < 0 {
+		add("maxRows", "не может быть отрицательным")
+	}
+	if c.QueryTimeoutSeconds < 0 {
+		add("queryTimeoutSeconds", "должен быть положительным")
+	}
+	if c.PageSize < 0 {
+		add("pageSize", "не может быть отрицательным")
+	}
+
+	return errs
+}
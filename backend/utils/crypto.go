@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// KDFScrypt - функция получения ключа из парольной фразы в экспорте
+// подключений
+const KDFScrypt = "scrypt"
+
+// Параметры scrypt по умолчанию: около 100 мс и 32 МБ памяти на ключ, что
+// делает перебор парольной фразы по файлу экспорта дорогим
+const (
+	ScryptN = 1 << 15
+	ScryptR = 8
+	ScryptP = 1
+)
+
+// Верхние границы параметров scrypt при импорте: N - на шаг выше значения
+// экспорта, r и p - как в экспорте. Так файл не может потребовать заметно
+// больше памяти и времени, чем файл, записанный самим сервером
+const (
+	maxScryptN = ScryptN << 1
+	maxScryptR = ScryptR
+	maxScryptP = ScryptP
+)
+
+const (
+	keySize  = 32
+	saltSize = 16
+)
+
+// NewSalt возвращает случайную соль для DeriveKey
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// DeriveKey получает ключ AES-256 из парольной фразы и соли через scrypt.
+// Параметры берутся из файла импорта, поэтому ограничены сверху
+// maxScryptN, maxScryptR и maxScryptP: иначе файл мог бы занять всю память
+// сервера
+func DeriveKey(passphrase string, salt []byte, n, r, p int) ([]byte, error) {
+	if len(salt) < saltSize {
+		return nil, errors.New("слишком короткая соль ключа")
+	}
+	if n < 2 || n > maxScryptN || r < 1 || r > maxScryptR || p < 1 || p > maxScryptP {
+		return nil, fmt.Errorf("недопустимые параметры scrypt: N=%d, r=%d, p=%d", n, r, p)
+	}
+	return scrypt.Key([]byte(passphrase), salt, n, r, p, keySize)
+}
+
+// LegacyKey - ключ экспорта версии 1: SHA-256 от парольной фразы без соли.
+// Используется только для импорта старых файлов
+func LegacyKey(passphrase string) []byte {
+	key := sha256.Sum256([]byte(passphrase))
+	return key[:]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptString шифрует строку AES-GCM ключом из DeriveKey.
+// Результат - base64(nonce || ciphertext).
+func EncryptString(plaintext string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func DecryptString(encoded string, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("поврежденные зашифрованные данные")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("неверная парольная фраза или поврежденные данные")
+	}
+
+	return string(plaintext), nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeriveKeyRoundTrip(t *testing.T) {
+	salt, err := NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := DeriveKey("secret phrase", salt, ScryptN, ScryptR, ScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := EncryptString("p@ssw0rd", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := DecryptString(encrypted, key); err != nil || got != "p@ssw0rd" {
+		t.Errorf("DecryptString = %q, %v; want p@ssw0rd", got, err)
+	}
+
+	wrong, err := DeriveKey("other phrase", salt, ScryptN, ScryptR, ScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptString(encrypted, wrong); err == nil {
+		t.Error("DecryptString with wrong passphrase: expected error")
+	}
+
+	otherSalt, _ := NewSalt()
+	salted, err := DeriveKey("secret phrase", otherSalt, ScryptN, ScryptR, ScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(key, salted) {
+		t.Error("same passphrase with different salt produced the same key")
+	}
+}
+
+func TestDeriveKeyRejectsParams(t *testing.T) {
+	salt, _ := NewSalt()
+	tests := []struct{ n, r, p int }{
+		{1 << 30, 8, 1},
+		{1 << 15, 1024, 1},
+		{1 << 15, 8, 0},
+		{1 << 17, 8, 1},
+		{1 << 20, 8, 1},
+		{1 << 15, 16, 1},
+		{1 << 15, 8, 2},
+	}
+	for _, tt := range tests {
+		if _, err := DeriveKey("x", salt, tt.n, tt.r, tt.p); err == nil {
+			t.Errorf("DeriveKey(N=%d, r=%d, p=%d): expected error", tt.n, tt.r, tt.p)
+		}
+	}
+	if _, err := DeriveKey("x", []byte("short"), ScryptN, ScryptR, ScryptP); err == nil {
+		t.Error("DeriveKey with short salt: expected error")
+	}
+}
@@ -1,21 +1,69 @@
 package utils
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"database-manager/models"
+	"encoding/base64"
 	"errors"
-	"os"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var jwtSecret = []byte("your-secret-key-change-in-production")
+// defaultJWTSecret - встроенный ключ для разработки; в production-режиме
+// сервер с ним не запускается
+const defaultJWTSecret = "your-secret-key-change-in-production"
 
-func init() {
-	if secret := os.Getenv("JWT_SECRET"); secret != "" {
-		jwtSecret = []byte(secret)
+// tokenLifetime - срок действия токена; по умолчанию столько же длится
+// период, когда принимаются токены, подписанные предыдущими ключами
+const tokenLifetime = 24 * time.Hour
+
+var (
+	jwtMu sync.RWMutex
+	// jwtKeys[0] подписывает новые токены, остальные - предыдущие ключи,
+	// токены с которыми принимаются в течение jwtGrace после выдачи
+	jwtKeys  = [][]byte{[]byte(defaultJWTSecret)}
+	jwtGrace = tokenLifetime
+)
+
+// SetJWTSecrets задает ключ подписи и предыдущие ключи для ротации: токены,
+// подписанные предыдущим ключом, принимаются, пока с их выдачи прошло
+// меньше grace (0 - срок действия токена), поэтому смена ключа не
+// выкидывает пользователей из системы
+func SetJWTSecrets(current string, previous []string, grace time.Duration) error {
+	if current == "" {
+		return errors.New("ключ подписи JWT не может быть пустым")
+	}
+	if grace <= 0 {
+		grace = tokenLifetime
+	}
+
+	keys := [][]byte{[]byte(current)}
+	for _, secret := range previous {
+		if secret != "" && secret != current {
+			keys = append(keys, []byte(secret))
+		}
 	}
+
+	jwtMu.Lock()
+	jwtKeys = keys
+	jwtGrace = grace
+	jwtMu.Unlock()
+	return nil
+}
+
+// IsDefaultJWTSecret сообщает, что токены подписываются встроенным ключом
+func IsDefaultJWTSecret() bool {
+	return string(signingKey()) == defaultJWTSecret
+}
+
+func signingKey() []byte {
+	jwtMu.RLock()
+	defer jwtMu.RUnlock()
+	return jwtKeys[0]
 }
 
 type Claims struct {
@@ -25,7 +73,7 @@ type Claims struct {
 }
 
 func GenerateToken(user models.User) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(tokenLifetime)
 	claims := &Claims{
 		UserID:   user.ID,
 		Username: user.Username,
@@ -36,26 +84,58 @@ func GenerateToken(user models.User) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	return token.SignedString(signingKey())
 }
 
+// ValidateToken проверяет подпись текущим ключом, затем предыдущими
 func ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("неверный метод подписи")
-		}
-		return jwtSecret, nil
-	})
+	jwtMu.RLock()
+	keys, grace := jwtKeys, jwtGrace
+	jwtMu.RUnlock()
 
-	if err != nil {
-		return nil, err
-	}
+	var lastErr error
+	for i, key := range keys {
+		claims := &Claims{}
+		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, errors.New("неверный метод подписи")
+			}
+			return key, nil
+		})
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			lastErr = err
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !token.Valid {
+			return nil, errors.New("невалидный токен")
+		}
 
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		if i > 0 && (claims.IssuedAt == nil || time.Since(claims.IssuedAt.Time) > grace) {
+			return nil, errors.New("токен подписан предыдущим ключом и больше не принимается")
+		}
 		return claims, nil
 	}
 
-	return nil, errors.New("невалидный токен")
+	return nil, lastErr
+}
+
+// CSRFToken выводит CSRF-токен из токена сессии: HMAC не хранится на
+// сервере, но привязан к сессии, и подобрать его без секрета нельзя
+func CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, signingKey())
+	mac.Write([]byte("csrf:" + sessionToken))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRFToken сравнивает CSRF-токен с ожидаемым за постоянное время
+func ValidCSRFToken(sessionToken, csrfToken string) bool {
+	if csrfToken == "" {
+		return false
+	}
+	return hmac.Equal([]byte(csrfToken), []byte(CSRFToken(sessionToken)))
 }
 
 func HashPassword(password string) (string, error) {
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_"
+
+// GeneratePassword возвращает криптографически стойкий случайный пароль.
+// Алфавит без кавычек и спецсимволов SQL, чтобы пароль можно было
+// подставлять в ALTER USER ... PASSWORD '...' без экранирования.
+func GeneratePassword(length int) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(result), nil
+}
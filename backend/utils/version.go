@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Данные сборки задаются при компиляции:
+//
+//	go build -ldflags "-X database-manager/utils.Version=1.2.0 \
+//	  -X database-manager/utils.Commit=$(git rev-parse --short HEAD) \
+//	  -X database-manager/utils.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+// BuildInfo - ответ GET /api/version
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"buildTime,omitempty"`
+	GoVersion string `json:"goVersion"`
+}
+
+// GetBuildInfo возвращает данные сборки. Если commit и время не переданы
+// через -ldflags, берутся из VCS-информации, которую go build встраивает
+// при сборке пакета из git-репозитория
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+	if info.Commit != "" && info.BuildTime != "" {
+		return info
+	}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -4,6 +4,7 @@ let connections = [];
 let selectedConnection = null;
 let currentTab = 'query';
 let authToken = localStorage.getItem('authToken') || '';
+let csrfToken = '';
 
 const API_BASE = window.location.origin;
 
@@ -17,16 +18,23 @@ const DB_PORTS = {
     'Cassandra': '9042',
     'Redis': '6379',
     'InfluxDB': '8086',
-    'Neo4j': '7474',
+    'Neo4j': '7687',
     'Couchbase': '8091',
     'Supabase': '5432',
     'Druid': '8888',
     'CockroachDB': '26257',
     'Kafka': '9092',
     'RabbitMQ': '15672',
-    'Zookeeper': '2181'
+    'Zookeeper': '2181',
+    'Oracle': '1521',
+    'SQLite': '',
+    'Prometheus': '9090'
 };
 
+// Описания типов БД с сервера (/api/database-types); DB_PORTS - запасной
+// вариант, если запрос не удался
+let databaseTypes = {};
+
 const DB_COLORS = {
     'PostgreSQL': 'bg-blue-500',
     'Elasticsearch': 'bg-yellow-500',
@@ -44,7 +52,10 @@ const DB_COLORS = {
     'CockroachDB': 'bg-lime-500',
     'Kafka': 'bg-slate-600',
     'RabbitMQ': 'bg-orange-600',
-    'Zookeeper': 'bg-yellow-600'
+    'Zookeeper': 'bg-yellow-600',
+    'Oracle': 'bg-red-700',
+    'SQLite': 'bg-sky-600',
+    'Prometheus': 'bg-orange-700'
 };
 
 const DATA_TYPES = {
@@ -61,7 +72,9 @@ const DATA_TYPES = {
     'Couchbase': ['string', 'number', 'boolean', 'array', 'object'],
     'Supabase': ['INTEGER', 'BIGINT', 'VARCHAR', 'TEXT', 'BOOLEAN', 'DATE', 'TIMESTAMP', 'JSON', 'UUID'],
     'Druid': ['STRING', 'LONG', 'DOUBLE', 'FLOAT', 'TIMESTAMP', 'COMPLEX'],
-    'CockroachDB': ['INTEGER', 'BIGINT', 'VARCHAR', 'TEXT', 'BOOLEAN', 'DATE', 'TIMESTAMP', 'JSON', 'UUID']
+    'CockroachDB': ['INTEGER', 'BIGINT', 'VARCHAR', 'TEXT', 'BOOLEAN', 'DATE', 'TIMESTAMP', 'JSON', 'UUID'],
+    'Oracle': ['NUMBER', 'INTEGER', 'VARCHAR2(255)', 'NVARCHAR2(255)', 'CLOB', 'BLOB', 'DATE', 'TIMESTAMP', 'RAW(16)'],
+    'SQLite': ['INTEGER', 'REAL', 'TEXT', 'BLOB', 'NUMERIC']
 };
 
 const PERMISSIONS = {
@@ -78,9 +91,24 @@ const PERMISSIONS = {
     'Couchbase': ['read', 'write', 'admin'],
     'Supabase': ['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'DROP'],
     'Druid': ['read', 'write', 'admin'],
-    'CockroachDB': ['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'DROP', 'SUPERUSER']
+    'CockroachDB': ['SELECT', 'INSERT', 'UPDATE', 'DELETE', 'CREATE', 'DROP', 'SUPERUSER'],
+    'Oracle': ['CONNECT', 'RESOURCE', 'CREATE TABLE', 'CREATE VIEW', 'SELECT ANY TABLE', 'DBA']
 };
 
+// CSRF-токен сессии запрашивается один раз; сервер проверяет его,
+// только если в app.json включен csrfProtection
+async function getCSRFToken() {
+    if (!csrfToken) {
+        const response = await fetch(`${API_BASE}/api/auth/csrf`, {
+            headers: { 'Authorization': `Bearer ${authToken}` }
+        });
+        if (response.ok) {
+            csrfToken = (await response.json()).token || '';
+        }
+    }
+    return csrfToken;
+}
+
 async function apiRequest(url, options = {}) {
     const headers = {
         'Content-Type': 'application/json',
@@ -89,6 +117,10 @@ async function apiRequest(url, options = {}) {
     
     if (authToken) {
         headers['Authorization'] = `Bearer ${authToken}`;
+        const method = (options.method || 'GET').toUpperCase();
+        if (method !== 'GET' && method !== 'HEAD') {
+            headers['X-CSRF-Token'] = await getCSRFToken();
+        }
     }
     
     const response = await fetch(`${API_BASE}${url}`, {
@@ -140,11 +172,27 @@ function showToast(message, type = 'success') {
     }).showToast();
 }
 
+// После входа через OIDC сервер возвращает токен или ошибку во фрагменте URL
+function consumeSSORedirect() {
+    const params = new URLSearchParams(window.location.hash.slice(1));
+    if (!params.has('token') && !params.has('ssoError')) return;
+
+    history.replaceState(null, '', window.location.pathname + window.location.search);
+    if (params.get('token')) {
+        authToken = params.get('token');
+        localStorage.setItem('authToken', authToken);
+    }
+    if (params.get('ssoError')) {
+        showToast(`Ошибка входа через SSO: ${params.get('ssoError')}`, 'error');
+    }
+}
+
 document.addEventListener('DOMContentLoaded', async () => {
+    consumeSSORedirect();
     if (!authToken) {
         showLoginModal();
     } else {
-        await loadConnections();
+        await Promise.all([loadConnections(), loadDatabaseTypes()]);
         setupFormListeners();
     }
     
@@ -152,8 +200,20 @@ document.addEventListener('DOMContentLoaded', async () => {
         // Обновляем порт только если он пустой или если это не режим редактирования
         const portField = document.getElementById('port');
         if (!editingConnectionId && (!portField.value || portField.value === '')) {
-            portField.value = DB_PORTS[e.target.value];
+            portField.value = databaseTypes[e.target.value]?.defaultPort ?? DB_PORTS[e.target.value];
+        }
+        const info = databaseTypes[e.target.value];
+        if (info) {
+            ['host', 'port', 'username'].forEach(id => {
+                document.getElementById(id).required = info.requiredFields.includes(id);
+            });
+            return;
         }
+        // Файловым БД (SQLite) хост, порт и пользователь не нужны
+        const fileBased = e.target.value === 'SQLite';
+        ['host', 'port', 'username'].forEach(id => {
+            document.getElementById(id).required = !fileBased;
+        });
     });
 });
 
@@ -179,10 +239,23 @@ function showLoginModal() {
                     <button type="button" onclick="showRegisterModal()" class="w-full border px-4 py-2 rounded-md hover:bg-gray-50">
                         Регистрация
                     </button>
+                    <a id="sso-login" href="${API_BASE}/api/auth/oidc/login"
+                        class="hidden block w-full text-center border px-4 py-2 rounded-md hover:bg-gray-50">
+                        Войти через SSO
+                    </a>
                 </form>
             </div>
         </div>
     `;
+
+    fetch(`${API_BASE}/api/auth/providers`)
+        .then(response => response.ok ? response.json() : {})
+        .then(providers => {
+            if (providers.oidc) {
+                document.getElementById('sso-login')?.classList.remove('hidden');
+            }
+        })
+        .catch(() => {});
     
     document.getElementById('login-form').addEventListener('submit', async (e) => {
         e.preventDefault();
@@ -275,6 +348,17 @@ async function loadConnections() {
     }
 }
 
+async function loadDatabaseTypes() {
+    try {
+        const types = await apiRequest('/api/database-types');
+        if (Array.isArray(types)) {
+            databaseTypes = Object.fromEntries(types.map(info => [info.type, info]));
+        }
+    } catch (error) {
+        console.warn('Не удалось загрузить типы БД:', error);
+    }
+}
+
 let editingConnectionId = null;
 
 function setupFormListeners() {
@@ -292,7 +376,12 @@ function setupFormListeners() {
             database: formData.get('database') || '',
             username: formData.get('username') || '',
             password: formData.get('password') || '',
-            ssl: formData.get('ssl') === 'on'
+            ssl: formData.get('ssl') === 'on',
+            skipTlsVerify: formData.get('skipTlsVerify') === 'on',
+            caCert: formData.get('caCert') || '',
+            clientCert: formData.get('clientCert') || '',
+            clientKey: formData.get('clientKey') || '',
+            redactColumns: (formData.get('redactColumns') || '').split(',').map(s => s.trim()).filter(Boolean)
         };
         
         try {
@@ -328,15 +417,33 @@ function setupFormListeners() {
             await loadConnections();
             resetConnectionForm();
         } catch (error) {
+            markConnectionFieldErrors(error.response?.fields);
             showToast('Ошибка: ' + error.message, 'error');
         }
     });
 }
 
+// Подсвечивает поля формы подключения, отклоненные сервером; поля без
+// элемента в форме (например, maxRows) остаются только в тексте ошибки
+function markConnectionFieldErrors(fields) {
+    document.querySelectorAll('#connection-form .border-red-500').forEach(el => {
+        el.classList.remove('border-red-500');
+        el.removeAttribute('title');
+    });
+    (fields || []).forEach(({ field, message }) => {
+        const input = document.getElementById(field);
+        if (input) {
+            input.classList.add('border-red-500');
+            input.title = message;
+        }
+    });
+}
+
 function resetConnectionForm() {
     const form = document.getElementById('connection-form');
     if (form) {
         form.reset();
+        markConnectionFieldErrors();
         editingConnectionId = null;
         const submitBtn = form.querySelector('button[type="submit"]');
         if (submitBtn) {
@@ -350,6 +457,7 @@ function resetConnectionForm() {
         }
         const passwordHint = document.getElementById('password-hint');
         if (passwordHint) passwordHint.classList.add('hidden');
+        document.getElementById('client-key-hint')?.classList.add('hidden');
         const formTitle = document.querySelector('#connection-form').closest('.bg-white').querySelector('h3');
         if (formTitle) {
             formTitle.innerHTML = `
@@ -384,6 +492,12 @@ async function editConnection(id) {
         const passwordHint = document.getElementById('password-hint');
         if (passwordHint) passwordHint.classList.add('hidden');
         document.getElementById('ssl').checked = conn.ssl || false;
+        document.getElementById('skipTlsVerify').checked = conn.skipTlsVerify || false;
+        document.getElementById('caCert').value = conn.caCert || '';
+        document.getElementById('clientCert').value = conn.clientCert || '';
+        document.getElementById('clientKey').value = conn.clientKey || '';
+        document.getElementById('redactColumns').value = (conn.redactColumns || []).join(', ');
+        document.getElementById('client-key-hint').classList.toggle('hidden', !conn.clientCert || !!conn.clientKey);
         
         // Сохраняем текущий порт, не перезаписываем автоматически при редактировании
         
@@ -459,6 +573,10 @@ function renderConnections() {
                             class="px-3 py-1.5 text-sm border rounded hover:bg-gray-50 transition-colors">
                             ✏️
                         </button>
+                        <button onclick="event.stopPropagation(); cloneConnection('${conn.id}')"
+                            class="px-3 py-1.5 text-sm border rounded hover:bg-gray-50 transition-colors" title="Копировать">
+                            📋
+                        </button>
                         <button onclick="event.stopPropagation(); deleteConnection('${conn.id}')"
                             class="px-3 py-1.5 text-sm border rounded hover:bg-gray-50 transition-colors">
                             🗑️
@@ -503,6 +621,16 @@ async function toggleConnection(id) {
     }
 }
 
+async function cloneConnection(id) {
+    try {
+        const clone = await apiRequest(`/api/connections/${id}/clone`, { method: 'POST' });
+        await loadConnections();
+        showToast(`Создана копия "${clone.name}"`);
+    } catch (error) {
+        showToast('Ошибка копирования: ' + error.message, 'error');
+    }
+}
+
 async function deleteConnection(id) {
     const conn = connections.find(c => c.id === id);
     const connName = conn ? conn.name : 'это подключение';
@@ -652,7 +780,10 @@ function getExampleQuery() {
         'CockroachDB': 'SELECT * FROM users LIMIT 10;',
         'Kafka': 'Kafka не поддерживает SQL. Используйте Kafka API',
         'RabbitMQ': 'RabbitMQ не поддерживает SQL. Используйте RabbitMQ Management API',
-        'Zookeeper': 'Zookeeper не поддерживает SQL. Используйте Zookeeper API'
+        'Zookeeper': 'Zookeeper не поддерживает SQL. Используйте Zookeeper API',
+        'Oracle': 'SELECT * FROM users FETCH FIRST 10 ROWS ONLY',
+        'SQLite': 'SELECT * FROM sqlite_master;',
+        'Prometheus': 'sum by (job) (rate(http_requests_total[5m]))'
     };
     return examples[selectedConnection.type] || 'SELECT * FROM table;';
 }
@@ -692,6 +823,10 @@ function getTableQuery(tableName) {
             return `SELECT * FROM ${tableName} LIMIT 100`;
         case 'CockroachDB':
             return `SELECT * FROM ${tableName} LIMIT 100;`;
+        case 'Oracle':
+            return `SELECT * FROM ${tableName} FETCH FIRST 100 ROWS ONLY`;
+        case 'Prometheus':
+            return `{"query": "${tableName}", "range": "1h"}`;
         default:
             return `SELECT * FROM ${tableName} LIMIT 100;`;
     }
@@ -738,6 +873,8 @@ async function executeQuery() {
         if (result.error) {
             showToast('Ошибка выполнения запроса: ' + result.error, 'error');
             displayQueryResults({ columns: [], rows: [], rowCount: 0, executionTime: 0, error: result.error });
+        } else if (result.status === 'pending') {
+            showToast('Запрос на изменение отправлен на подтверждение администратору');
         } else {
             displayQueryResults(result);
             showToast(`Запрос выполнен успешно. Найдено строк: ${result.rowCount}`);
@@ -763,6 +900,7 @@ function displayQueryResults(result) {
                         <p class="text-sm text-gray-500 mt-1">
                             Строк: ${result.rowCount} • Время: ${result.executionTime}ms
                         </p>
+                        ${result.truncated ? `<p class="text-sm text-amber-600 mt-1">Показаны первые ${result.rowCount} строк: результат обрезан по пределу maxRows. Уточните запрос или добавьте LIMIT</p>` : ''}
                     </div>
                 </div>
             </div>
@@ -889,6 +1027,7 @@ let editingUsername = null;
 
 function setupUsersTab() {
     loadUsers();
+    loadGrantableRoles();
     
     const form = document.getElementById('user-form');
     if (!form) return;
@@ -934,6 +1073,28 @@ function setupUsersTab() {
     });
 }
 
+// Заменяет статический список прав ролями и привилегиями, которые вернул
+// сервер; если тип БД список не поддерживает, остается PERMISSIONS
+async function loadGrantableRoles() {
+    const container = document.getElementById('user-permissions');
+    if (!container) return;
+    
+    let roles;
+    try {
+        roles = await apiRequest(`/api/users/roles?connectionId=${selectedConnection.id}`);
+    } catch (error) {
+        return;
+    }
+    if (Array.isArray(roles) && roles.length > 0) {
+        container.innerHTML = roles.map(role => `
+            <div class="flex items-center gap-2">
+                <input type="checkbox" id="perm-${role.name}" value="${role.name}" class="h-4 w-4">
+                <label for="perm-${role.name}" class="text-sm">${role.name}${role.kind === 'role' ? ' <span class="text-xs text-gray-500">роль</span>' : ''}</label>
+            </div>
+        `).join('');
+    }
+}
+
 async function loadUsers() {
     const container = document.getElementById('users-list');
     if (!container) return;
@@ -1382,12 +1543,13 @@ async function editTable(name) {
 }
 
 async function deleteTable(name) {
-    if (!confirm(`Вы уверены, что хотите удалить таблицу "${name}"?\n\nЭто действие нельзя отменить!`)) {
+    const typed = prompt(`Удаление таблицы "${name}" нельзя отменить.\n\nВведите имя таблицы для подтверждения:`);
+    if (typed === null) {
         return;
     }
     
     try {
-        await apiRequest(`/api/tables/delete?connectionId=${selectedConnection.id}&name=${name}`, {
+        await apiRequest(`/api/tables/delete?connectionId=${selectedConnection.id}&name=${encodeURIComponent(name)}&confirm=${encodeURIComponent(typed)}`, {
             method: 'DELETE'
         });
         
@@ -1816,12 +1978,13 @@ async function deleteDatabase(name) {
                         selectedConnection.type === 'Zookeeper' ? 'Узел' :
                         'База данных';
     
-    if (!confirm(`Вы уверены, что хотите удалить ${dbTypeLabel.toLowerCase()} "${name}"?\n\nЭто действие нельзя отменить!`)) {
+    const typed = prompt(`Удаление: ${dbTypeLabel.toLowerCase()} "${name}". Это действие нельзя отменить.\n\nВведите имя для подтверждения:`);
+    if (typed === null) {
         return;
     }
     
     try {
-        await apiRequest(`/api/databases/delete?connectionId=${selectedConnection.id}&name=${name}`, {
+        await apiRequest(`/api/databases/delete?connectionId=${selectedConnection.id}&name=${encodeURIComponent(name)}&confirm=${encodeURIComponent(typed)}`, {
             method: 'DELETE'
         });
         
@@ -18,7 +18,9 @@ go mod download
 
 # Собираем приложение
 echo "⚙️  Компиляция..."
-go build -o database-manager main.go
+COMMIT=$(git rev-parse --short HEAD 2>/dev/null || true)
+BUILD_TIME=$(date -u +%Y-%m-%dT%H:%M:%SZ)
+go build -o database-manager -ldflags "-X database-manager/utils.Commit=$COMMIT -X database-manager/utils.BuildTime=$BUILD_TIME" main.go
 
 echo "✅ Backend собран успешно!"
 